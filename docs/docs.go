@@ -15,16 +15,21 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/categories": {
+        "/admin/categories": {
             "get": {
-                "description": "get all categories",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get categories proposed by users which are not reviewed yet, admins and moderators only",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "categories"
+                    "admin"
                 ],
-                "summary": "Get categories",
+                "summary": "Get pending categories",
                 "parameters": [
                     {
                         "type": "integer",
@@ -37,6 +42,12 @@ const docTemplate = `{
                         "description": "limit records by page",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -55,6 +66,18 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -62,39 +85,53 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/categories/{id}/approve": {
             "post": {
-                "description": "create new category, return its id",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "approve the pending category, so it can be used by portfolios, admins and moderators only",
                 "tags": [
-                    "categories"
+                    "admin"
                 ],
-                "summary": "Post category",
+                "summary": "Approve category",
                 "parameters": [
                     {
-                        "description": "category, name required",
-                        "name": "category",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Category"
-                        }
+                        "type": "integer",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "string"
                         }
@@ -108,13 +145,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/categories/{id}": {
-            "delete": {
-                "description": "delete category by its id",
+        "/admin/categories/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete the pending category, admins and moderators only",
                 "tags": [
-                    "categories"
+                    "admin"
                 ],
-                "summary": "Delete category",
+                "summary": "Reject category",
                 "parameters": [
                     {
                         "type": "integer",
@@ -134,6 +176,24 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -143,16 +203,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/profiles/{profileID}/portfolios": {
+        "/admin/tags": {
             "get": {
-                "description": "get portfolios (all, by profile id or by category id)",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get tags proposed by users which are not reviewed yet, admins and moderators only",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "portfolios"
+                    "admin"
                 ],
-                "summary": "Get portfolios",
+                "summary": "Get pending tags",
                 "parameters": [
                     {
                         "type": "integer",
@@ -167,19 +232,9 @@ const docTemplate = `{
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "profile or category id",
-                        "name": "id",
-                        "in": "query"
-                    },
-                    {
-                        "enum": [
-                            "ByProfileID",
-                            "ByCategoryID"
-                        ],
                         "type": "string",
-                        "description": "filtered by",
-                        "name": "filter",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
                         "in": "query"
                     }
                 ],
@@ -187,7 +242,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PortfoliosPage"
+                            "$ref": "#/definitions/models.TagsPage"
                         }
                     },
                     "204": {
@@ -199,6 +254,18 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -206,39 +273,53 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/tags/{id}/approve": {
             "post": {
-                "description": "create new portfolio, return its id",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "approve the pending tag, so it can be added to crafts, admins and moderators only",
                 "tags": [
-                    "portfolios"
+                    "admin"
                 ],
-                "summary": "Post portfolio",
+                "summary": "Approve tag",
                 "parameters": [
                     {
-                        "description": "portfolio without crafts, profile id is required",
-                        "name": "portfolio",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Portfolio"
-                        }
+                        "type": "integer",
+                        "description": "tag id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "string"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "string"
                         }
@@ -252,20 +333,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/profiles/{profileID}/portfolios/{id}": {
-            "get": {
-                "description": "get portfolio by its id",
-                "produces": [
-                    "application/json"
+        "/admin/tags/{id}/reject": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "delete the pending tag, admins and moderators only",
                 "tags": [
-                    "portfolios"
+                    "admin"
                 ],
-                "summary": "Get portfolio",
+                "summary": "Reject tag",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "portfolio id",
+                        "description": "tag id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -273,10 +356,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Portfolio"
-                        }
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -284,6 +364,24 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -291,13 +389,18 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete portfolio by its id",
+            }
+        },
+        "/categories": {
+            "get": {
+                "description": "get all categories",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "portfolios"
+                    "categories"
                 ],
-                "summary": "Delete portfolio",
+                "summary": "Get categories",
                 "parameters": [
                     {
                         "type": "integer",
@@ -307,22 +410,26 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "portfolio id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "limit records by page",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "profile id",
-                        "name": "profileID",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CategoriesPage"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -338,36 +445,46 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
-                "description": "update portfolio by its id",
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create new category, return its id. Categories of users without moderator or admin role are proposals, they are pending until they are approved",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "portfolios"
+                    "categories"
                 ],
-                "summary": "Patch portfolio",
+                "summary": "Post category",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "portfolio id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "updated portfolio, info without changes is also required",
-                        "name": "portfolio",
+                        "description": "category, name required",
+                        "name": "category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Portfolio"
+                            "$ref": "#/definitions/models.Category"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -375,6 +492,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -384,16 +507,106 @@ const docTemplate = `{
                 }
             }
         },
-        "/profiles/{profileID}/portfolios/{id}/crafts": {
-            "get": {
-                "description": "get all crafts by portfolio id",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "crafts"
+        "/categories/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
-                "summary": "Get crafts by portfolio id",
+                "description": "delete category by its id, admins only",
+                "tags": [
+                    "categories"
+                ],
+                "summary": "Delete category",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/debug/vars": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "metrics of the service in the expvar format, only the spool of events is published, it requires the admin role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "metrics"
+                ],
+                "summary": "Get metrics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios": {
+            "get": {
+                "description": "get portfolios (all, by profile id or by category id)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolios",
                 "parameters": [
                     {
                         "type": "integer",
@@ -407,6 +620,121 @@ const docTemplate = `{
                         "name": "limit",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "profile or category id",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ByProfileID",
+                            "ByCategoryID"
+                        ],
+                        "type": "string",
+                        "description": "filtered by",
+                        "name": "filter",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PortfoliosPage"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create new portfolio, return its id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Post portfolio",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "portfolio without crafts, profile id is taken from the path if it is omitted",
+                        "name": "portfolio",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Portfolio"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}": {
+            "get": {
+                "description": "get portfolio by its id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Get portfolio",
+                "parameters": [
                     {
                         "type": "integer",
                         "description": "portfolio id",
@@ -419,14 +747,1500 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CraftsPage"
+                            "$ref": "#/definitions/models.Portfolio"
                         }
                     },
-                    "204": {
-                        "description": "No Content"
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "move portfolio with its crafts and contents to the trash, it can be restored until it is purged",
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Delete portfolio",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update portfolio by its id, previous values are saved as a revision",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolios"
+                ],
+                "summary": "Patch portfolio",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "updated portfolio, info without changes is also required",
+                        "name": "portfolio",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Portfolio"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts": {
+            "get": {
+                "description": "get all crafts by portfolio id, the preview content of every craft has data_url and thumbnails instead of data",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Get crafts by portfolio id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit records by page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CraftsPage"
+                        }
+                    },
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create new craft, return its id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Post craft",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "craft without contents",
+                        "name": "craft",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Craft"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/order": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "set order of crafts of the portfolio, ids of all not deleted crafts of the portfolio must be sent exactly once",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Reorder crafts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ids of crafts in the new order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}": {
+            "get": {
+                "description": "get craft by its id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Get craft",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "embed (default) returns data of contents, url returns data_url for download instead",
+                        "name": "data",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Craft"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "move craft with its contents to the trash, it can be restored until it is purged",
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Delete craft",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update craft by its id, previous values are saved as a revision",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Patch craft",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "updated craft, info without changes is also required",
+                        "name": "craft",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Craft"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create new content, return its id. Content is sent as JSON with base64 data or as multipart/form-data with content_description field followed by data file, the file is streamed to the storage",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contents"
+                ],
+                "summary": "Post content",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "content, for JSON requests",
+                        "name": "content",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.Content"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "content description, for multipart requests, must be sent before data",
+                        "name": "content_description",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "file",
+                        "description": "content data, for multipart requests",
+                        "name": "data",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PortfoliosPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/response_errors.ContentError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/response_errors.ContentError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/order": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "set order of contents of the craft, ids of all not deleted contents of the craft must be sent exactly once",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contents"
+                ],
+                "summary": "Reorder contents",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ids of contents in the new order",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Order"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "move content to the trash, it can be restored until it is purged",
+                "tags": [
+                    "contents"
+                ],
+                "summary": "Delete content",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "content id",
+                        "name": "contentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "update content by its id, previous values are saved as a revision",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contents"
+                ],
+                "summary": "Patch content",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "content id",
+                        "name": "contentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "updated content, info without changes is also required",
+                        "name": "content",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Content"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/response_errors.ContentError"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/response_errors.ContentError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/data": {
+            "get": {
+                "description": "download raw data of the content, supports Range and If-None-Match requests, ETag is SHA-256 of the data",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "contents"
+                ],
+                "summary": "Get content data",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "content id",
+                        "name": "contentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "416": {
+                        "description": "Requested Range Not Satisfiable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get previous values of the content, the oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Get content revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "content id",
+                        "name": "contentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit records by page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RevisionsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/revisions/{rev}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get values of the content saved in the revision",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Get content revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "content id",
+                        "name": "contentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Revision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/revisions/{rev}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "restore values of the content saved in the revision, current values are saved as a new revision",
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Revert content",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "content id",
+                        "name": "contentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/thumbnails/{size}": {
+            "get": {
+                "description": "download the thumbnail of the image content, sizes of thumbnails are listed in thumbnails of the content, ETag is SHA-256 of the thumbnail",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "contents"
+                ],
+                "summary": "Get content thumbnail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "content id",
+                        "name": "contentID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "thumbnail size",
+                        "name": "size",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get previous values of the craft, the oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Get craft revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit records by page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RevisionsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/revisions/{rev}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get values of the craft saved in the revision",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Get craft revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Revision"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/revisions/{rev}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "restore values of the craft saved in the revision, current values are saved as a new revision",
+                "tags": [
+                    "revisions"
+                ],
+                "summary": "Revert craft",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/tags/{tagID}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "add tag to the craft",
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Post tag patch craft",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tag id",
+                        "name": "tagID",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete tag from the craft",
+                "tags": [
+                    "crafts"
+                ],
+                "summary": "Delete tag patch craft",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "craft id",
+                        "name": "craftID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tag id",
+                        "name": "tagID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "string"
                         }
@@ -438,19 +2252,23 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "create new craft, return its id",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/revisions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "get previous values of the portfolio, the oldest first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "crafts"
+                    "revisions"
                 ],
-                "summary": "Post craft",
+                "summary": "Get portfolio revisions",
                 "parameters": [
                     {
                         "type": "integer",
@@ -467,20 +2285,29 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "craft without contents",
-                        "name": "craft",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Craft"
-                        }
+                        "type": "integer",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit records by page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/models.RevisionsPage"
                         }
                     },
                     "400": {
@@ -489,6 +2316,24 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -498,21 +2343,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}": {
+        "/profiles/{profileID}/portfolios/{id}/revisions/{rev}": {
             "get": {
-                "description": "get craft by its id",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "get values of the portfolio saved in the revision",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "crafts"
+                    "revisions"
                 ],
-                "summary": "Get craft",
+                "summary": "Get portfolio revision",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "craft id",
-                        "name": "craftID",
+                        "description": "profile id",
+                        "name": "profileID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "portfolio id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "revision number",
+                        "name": "rev",
                         "in": "path",
                         "required": true
                     }
@@ -521,7 +2385,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Craft"
+                            "$ref": "#/definitions/models.Revision"
                         }
                     },
                     "400": {
@@ -530,42 +2394,20 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "string"
                         }
-                    }
-                }
-            },
-            "delete": {
-                "description": "delete craft by its id",
-                "tags": [
-                    "crafts"
-                ],
-                "summary": "Delete craft",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "profile id",
-                        "name": "profileID",
-                        "in": "path",
-                        "required": true
                     },
-                    {
-                        "type": "integer",
-                        "description": "craft id",
-                        "name": "craftID",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK"
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "string"
                         }
@@ -577,16 +2419,20 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "update craft by its id",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/profiles/{profileID}/portfolios/{id}/revisions/{rev}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "restore values of the portfolio saved in the revision, current values are saved as a new revision",
                 "tags": [
-                    "crafts"
+                    "revisions"
                 ],
-                "summary": "Patch craft",
+                "summary": "Revert portfolio",
                 "parameters": [
                     {
                         "type": "integer",
@@ -597,19 +2443,17 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "craft id",
-                        "name": "craftID",
+                        "description": "portfolio id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "updated craft, info without changes is also required",
-                        "name": "craft",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Craft"
-                        }
+                        "type": "integer",
+                        "description": "revision number",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -622,6 +2466,12 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -631,19 +2481,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents": {
-            "post": {
-                "description": "create new content, return its id",
-                "consumes": [
-                    "application/json"
+        "/profiles/{profileID}/trash": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "get deleted portfolios, crafts and contents of the profile which are not purged yet, the most recently deleted first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "contents"
+                    "trash"
                 ],
-                "summary": "Post content",
+                "summary": "Get trash",
                 "parameters": [
                     {
                         "type": "integer",
@@ -651,29 +2503,13 @@ const docTemplate = `{
                         "name": "profileID",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "craft id",
-                        "name": "craftID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "content",
-                        "name": "content",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Content"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.PortfoliosPage"
+                            "$ref": "#/definitions/models.Trash"
                         }
                     },
                     "400": {
@@ -682,6 +2518,18 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -691,13 +2539,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}": {
-            "delete": {
-                "description": "delete content by its id",
+        "/profiles/{profileID}/trash/contents/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "restore deleted content, content of deleted craft or portfolio can't be restored separately",
                 "tags": [
-                    "contents"
+                    "trash"
                 ],
-                "summary": "Delete content",
+                "summary": "Restore content",
                 "parameters": [
                     {
                         "type": "integer",
@@ -709,7 +2562,7 @@ const docTemplate = `{
                     {
                         "type": "integer",
                         "description": "content id",
-                        "name": "contentID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -731,16 +2584,20 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "update content by its id",
-                "consumes": [
-                    "application/json"
+            }
+        },
+        "/profiles/{profileID}/trash/crafts/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "restore deleted craft with contents deleted together with it, craft of deleted portfolio can't be restored separately",
                 "tags": [
-                    "contents"
+                    "trash"
                 ],
-                "summary": "Patch content",
+                "summary": "Restore craft",
                 "parameters": [
                     {
                         "type": "integer",
@@ -751,19 +2608,10 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "content id",
-                        "name": "contentID",
+                        "description": "craft id",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "updated content, info without changes is also required",
-                        "name": "content",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.Content"
-                        }
                     }
                 ],
                 "responses": {
@@ -785,13 +2633,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/profiles/{profileID}/portfolios/{id}/crafts/{craftID}/tags/{tagID}": {
+        "/profiles/{profileID}/trash/portfolios/{id}/restore": {
             "post": {
-                "description": "add tag to the craft",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "restore deleted portfolio with crafts and contents deleted together with it",
                 "tags": [
-                    "crafts"
+                    "trash"
                 ],
-                "summary": "Post tag patch craft",
+                "summary": "Restore portfolio",
                 "parameters": [
                     {
                         "type": "integer",
@@ -802,16 +2655,9 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "craft id",
-                        "name": "craftID",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "tag id",
-                        "name": "tagID",
-                        "in": "query",
+                        "description": "portfolio id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -832,39 +2678,63 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete tag from the craft",
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "full-text search of portfolios and crafts by names, descriptions, tags and contents descriptions, results are sorted by rank",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "crafts"
+                    "search"
                 ],
-                "summary": "Delete tag patch craft",
+                "summary": "Search",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "profile id",
-                        "name": "profileID",
-                        "in": "path",
+                        "type": "string",
+                        "description": "search query, supports quoted phrases, OR and -word",
+                        "name": "q",
+                        "in": "query",
                         "required": true
                     },
                     {
                         "type": "integer",
-                        "description": "craft id",
-                        "name": "craftID",
-                        "in": "path",
-                        "required": true
+                        "description": "category id of portfolios",
+                        "name": "category",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "tag id of crafts, portfolios are found if any of their crafts has the tag",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "tag id",
-                        "name": "tagID",
-                        "in": "path",
-                        "required": true
+                        "description": "limit records by page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SearchPage"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -877,6 +2747,12 @@ const docTemplate = `{
                         "schema": {
                             "type": "string"
                         }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
+                        "schema": {
+                            "type": "string"
+                        }
                     }
                 }
             }
@@ -903,6 +2779,12 @@ const docTemplate = `{
                         "description": "limit records by page",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -930,7 +2812,12 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "create new tag, return its id",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "create new tag, return its id. Tags of users without moderator or admin role are proposals, they are pending until they are approved",
                 "consumes": [
                     "application/json"
                 ],
@@ -959,12 +2846,24 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -976,7 +2875,12 @@ const docTemplate = `{
         },
         "/tags/{id}": {
             "delete": {
-                "description": "delete tag by its id",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "delete tag by its id, admins only",
                 "tags": [
                     "tags"
                 ],
@@ -1000,6 +2904,18 @@ const docTemplate = `{
                             "type": "string"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1011,7 +2927,7 @@ const docTemplate = `{
         },
         "/tags/{id}/crafts": {
             "get": {
-                "description": "get all crafts by tag id",
+                "description": "get all crafts by tag id, the preview content of every craft has data_url and thumbnails instead of data",
                 "produces": [
                     "application/json"
                 ],
@@ -1020,6 +2936,24 @@ const docTemplate = `{
                 ],
                 "summary": "Get crafts by tag",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit records by page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page, empty for the first page, can't be used with page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
                         "description": "tag id",
@@ -1067,6 +3001,9 @@ const docTemplate = `{
                 "limit": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "page_number": {
                     "type": "integer"
                 },
@@ -1083,6 +3020,13 @@ const docTemplate = `{
                 },
                 "category_name": {
                     "type": "string"
+                },
+                "proposed_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status and ProposedBy are set for proposals only",
+                    "type": "string"
                 }
             }
         },
@@ -1100,6 +3044,39 @@ const docTemplate = `{
                     "items": {
                         "type": "integer"
                     }
+                },
+                "data_url": {
+                    "description": "DataURL replaces Data in responses when data is requested by url",
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "mime_type": {
+                    "description": "metadata is detected by the data, values sent by clients are ignored",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Position orders contents of the craft, it is changed by reordering only",
+                    "type": "integer"
+                },
+                "sha256": {
+                    "description": "Checksum is hex encoded SHA-256 of the data",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "thumbnails": {
+                    "description": "Thumbnails are made for images after the content is saved, so they may be missing for a while",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Thumbnail"
+                    }
+                },
+                "width": {
+                    "description": "Width and Height are set for images only",
+                    "type": "integer"
                 }
             }
         },
@@ -1121,6 +3098,17 @@ const docTemplate = `{
                 "craft_name": {
                     "type": "string"
                 },
+                "portfolio_id": {
+                    "description": "PortfolioID and ProfileID are filled on reading only, they are kept by the portfolio",
+                    "type": "integer"
+                },
+                "position": {
+                    "description": "Position orders crafts of the portfolio, it is changed by reordering only",
+                    "type": "integer"
+                },
+                "profile_id": {
+                    "type": "integer"
+                },
                 "tags": {
                     "type": "array",
                     "items": {
@@ -1141,6 +3129,9 @@ const docTemplate = `{
                 "limit": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "page_number": {
                     "type": "integer"
                 },
@@ -1149,6 +3140,17 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Order": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
         "models.Portfolio": {
             "type": "object",
             "properties": {
@@ -1181,6 +3183,9 @@ const docTemplate = `{
                 "limit": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "page_number": {
                     "type": "integer"
                 },
@@ -1195,9 +3200,117 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Revision": {
+            "type": "object",
+            "properties": {
+                "author_id": {
+                    "type": "integer"
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "object_id": {
+                    "type": "integer"
+                },
+                "revision": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RevisionsPage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page_number": {
+                    "type": "integer"
+                },
+                "pages_amount": {
+                    "type": "integer"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Revision"
+                    }
+                }
+            }
+        },
+        "models.SearchPage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page_number": {
+                    "type": "integer"
+                },
+                "pages_amount": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SearchResult"
+                    }
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "profile_id": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Tag": {
             "type": "object",
             "properties": {
+                "proposed_by": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status and ProposedBy are set for proposals only",
+                    "type": "string"
+                },
                 "tag_id": {
                     "type": "integer"
                 },
@@ -1212,6 +3325,9 @@ const docTemplate = `{
                 "limit": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "page_number": {
                     "type": "integer"
                 },
@@ -1225,6 +3341,95 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "models.Thumbnail": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "type": "integer"
+                },
+                "mime_type": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Trash": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TrashItem"
+                    }
+                }
+            }
+        },
+        "models.TrashItem": {
+            "type": "object",
+            "properties": {
+                "craft_id": {
+                    "type": "integer"
+                },
+                "deleted_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "portfolio_id": {
+                    "type": "integer"
+                },
+                "profile_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response_errors.ContentError": {
+            "type": "object",
+            "properties": {
+                "allowed": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code": {
+                    "type": "string"
+                },
+                "limit": {
+                    "description": "Limit is the exceeded limit in bytes",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "mime_type": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Bearer JWT, the subject is the profile id of the caller",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/auth"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+var errNoToken = errors.New("bearer token is required")
+
+// authenticator checks bearer tokens of requests, requests without tokens are allowed only to public routes,
+// writes to a profile and private reads of it are allowed only to the owner of the profile, who is the subject of the token
+type authenticator struct {
+	verifier     *auth.Verifier
+	publicReads  bool
+	publicRoutes map[string]bool
+}
+
+func newAuthenticator(cfg config.Auth) (*authenticator, error) {
+	verifier, err := auth.NewVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	publicRoutes := make(map[string]bool, len(cfg.PublicRoutes))
+	for _, route := range cfg.PublicRoutes {
+		publicRoutes[strings.Join(strings.Fields(route), " ")] = true
+	}
+
+	return &authenticator{verifier: verifier, publicReads: cfg.PublicReads, publicRoutes: publicRoutes}, nil
+}
+
+func (a *authenticator) middleware(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+	return func(w http.ResponseWriter, req bunrouter.Request) error {
+		token, ok := bearerToken(req.Request)
+		if !ok {
+			if a.isPublic(req) {
+				return next(w, req)
+			}
+			unauthorized(w, errNoToken)
+			return nil
+		}
+
+		identity, err := a.verifier.Verify(token)
+		if err != nil {
+			unauthorized(w, err)
+			return nil
+		}
+
+		if isWrite(req.Method) || isPrivateRead(req) {
+			if profileID, ok := req.Params().Get("profileID"); ok && profileID != strconv.Itoa(identity.ProfileID) {
+				http.Error(w, "token subject does not match the profile", http.StatusForbidden)
+				return nil
+			}
+		}
+
+		return next(w, req.WithContext(auth.WithIdentity(req.Context(), identity)))
+	}
+}
+
+// isPublic reports whether the route can be called without a token, private reads are never public
+func (a *authenticator) isPublic(req bunrouter.Request) bool {
+	if isPrivateRead(req) {
+		return false
+	}
+	if a.publicReads && (req.Method == http.MethodGet || req.Method == http.MethodHead) {
+		return true
+	}
+
+	return a.publicRoutes[req.Method+" "+req.Route()]
+}
+
+// isPrivateRead reports whether the route reads the trash or revisions of the profile, they keep deleted objects,
+// previous values and authors, so only the owner can read them
+func isPrivateRead(req bunrouter.Request) bool {
+	route := req.Route()
+	if !strings.HasPrefix(route, "/profiles/:profileID/") {
+		return false
+	}
+
+	return strings.HasPrefix(route, "/profiles/:profileID/trash") || strings.Contains(route, "/revisions")
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func isWrite(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNoToken) {
+		w.Header().Set("WWW-Authenticate", `Bearer`)
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
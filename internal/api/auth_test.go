@@ -0,0 +1,112 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/uptrace/bunrouter"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/auth"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+const testSecret = "secret"
+
+func testToken(t *testing.T, sub string) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": sub, "exp": time.Now().Add(time.Hour).Unix()}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+// newAuthRouter routes requests through the authenticator to the handler which writes 200 and checks the identity of the caller
+func newAuthRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	authenticator, err := newAuthenticator(config.Auth{Enabled: true, HS256Secret: testSecret, RolesClaim: "roles", PublicReads: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.FromContext(r.Context()); !ok && r.Header.Get("Authorization") != "" {
+			t.Errorf("identity of the caller is not in the context of %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+
+	router := bunrouter.New(bunrouter.Use(authenticator.middleware)).Compat()
+	router.GET("/profiles/:profileID/portfolios", handler)
+	router.POST("/profiles/:profileID/portfolios", handler)
+	router.PATCH("/profiles/:profileID/portfolios/:id", handler)
+	router.GET("/profiles/:profileID/portfolios/:id/revisions", handler)
+	router.GET("/profiles/:profileID/trash", handler)
+	router.POST("/tags", handler)
+
+	return router
+}
+
+func TestAuthenticatorMiddleware(t *testing.T) {
+	router := newAuthRouter(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		sub    string
+		want   int
+	}{
+		{name: "write to another profile", method: http.MethodPost, path: "/profiles/2/portfolios", sub: "1", want: http.StatusForbidden},
+		{name: "patch of another profile", method: http.MethodPatch, path: "/profiles/2/portfolios/5", sub: "1", want: http.StatusForbidden},
+		{name: "write to own profile", method: http.MethodPost, path: "/profiles/2/portfolios", sub: "2", want: http.StatusOK},
+		{name: "write without token", method: http.MethodPost, path: "/profiles/2/portfolios", want: http.StatusUnauthorized},
+		{name: "write without profile in path", method: http.MethodPost, path: "/tags", sub: "1", want: http.StatusOK},
+		{name: "public read without token", method: http.MethodGet, path: "/profiles/2/portfolios", want: http.StatusOK},
+		{name: "public read of another profile", method: http.MethodGet, path: "/profiles/2/portfolios", sub: "1", want: http.StatusOK},
+		{name: "trash without token", method: http.MethodGet, path: "/profiles/2/trash", want: http.StatusUnauthorized},
+		{name: "trash of another profile", method: http.MethodGet, path: "/profiles/2/trash", sub: "1", want: http.StatusForbidden},
+		{name: "own trash", method: http.MethodGet, path: "/profiles/2/trash", sub: "2", want: http.StatusOK},
+		{name: "revisions without token", method: http.MethodGet, path: "/profiles/2/portfolios/5/revisions", want: http.StatusUnauthorized},
+		{name: "revisions of another profile", method: http.MethodGet, path: "/profiles/2/portfolios/5/revisions", sub: "1", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.sub != "" {
+				req.Header.Set("Authorization", "Bearer "+testToken(t, tt.sub))
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("got status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticatorMiddlewareInvalidToken(t *testing.T) {
+	router := newAuthRouter(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/profiles/2/portfolios", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken(t, "2")+"x")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	if got := w.Header().Get("WWW-Authenticate"); got != `Bearer error="invalid_token"` {
+		t.Errorf("got WWW-Authenticate %q", got)
+	}
+}
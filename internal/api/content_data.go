@@ -0,0 +1,149 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/thumbnail"
+)
+
+// maxDescriptionSize limits content_description part of the multipart upload, the data part is not limited here
+const maxDescriptionSize = 64 << 10
+
+var errMissingData = errors.New("content data is required")
+
+// isMultipart reports whether the content is uploaded as multipart/form-data instead of JSON
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// readContentParts reads parts of the multipart upload until the data part and returns the content with the reader of the data,
+// so the data is streamed to the storage. content_description must be sent before data, parts after data are ignored
+func readContentParts(r *http.Request) (models.Content, io.Reader, error) {
+	var content models.Content
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return content, nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return content, nil, errMissingData
+		}
+		if err != nil {
+			return content, nil, err
+		}
+
+		switch part.FormName() {
+		case "content_description":
+			description, err := io.ReadAll(io.LimitReader(part, maxDescriptionSize))
+			if err != nil {
+				return content, nil, fmt.Errorf("failed to read content_description: %w", err)
+			}
+			content.Description = string(description)
+		case "data":
+			data := bufio.NewReader(part)
+			if _, err = data.Peek(1); err != nil {
+				if errors.Is(err, io.EOF) {
+					return content, nil, errMissingData
+				}
+				return content, nil, fmt.Errorf("failed to read data: %w", err)
+			}
+			return content, data, nil
+		}
+	}
+}
+
+// dataByURL reads data param: embed (default) keeps data of contents in the response, url replaces it by the download url
+func dataByURL(r *http.Request) (bool, error) {
+	switch r.FormValue("data") {
+	case "", "embed":
+		return false, nil
+	case "url":
+		return true, nil
+	default:
+		return false, errors.New("incorrect data: must be embed, url or empty")
+	}
+}
+
+func contentURL(craft models.Craft, contentID int) string {
+	return fmt.Sprintf("/profiles/%d/portfolios/%d/crafts/%d/contents/%d", craft.ProfileID, craft.PortfolioID, craft.ID, contentID)
+}
+
+// contentDataURL is the path of getContentDataHandler
+func contentDataURL(craft models.Craft, contentID int) string {
+	return contentURL(craft, contentID) + "/data"
+}
+
+// setContentURLs sets urls of thumbnails of the craft contents, dataByURL replaces data of the contents by their download urls too,
+// empty preview of the craft without contents is kept as is
+func setContentURLs(craft *models.Craft, dataByURL bool) {
+	for i := range craft.Contents {
+		content := &craft.Contents[i]
+		if content.ID == 0 {
+			continue
+		}
+
+		for j := range content.Thumbnails {
+			content.Thumbnails[j].URL = fmt.Sprintf("%s/thumbnails/%d", contentURL(*craft, content.ID), content.Thumbnails[j].Size)
+		}
+
+		if dataByURL {
+			content.Data, content.DataURL = nil, contentDataURL(*craft, content.ID)
+		}
+	}
+}
+
+// extendDeadlines gives uploads and downloads of contents data the transfer timeout instead of read and write timeouts
+// of the server, which are short for other requests. The read deadline is extended only for uploads
+func (s *Server) extendDeadlines(w http.ResponseWriter, r *http.Request, upload bool) {
+	if s.transferTimeout <= 0 {
+		return
+	}
+
+	deadline := time.Now().Add(s.transferTimeout)
+	controller := http.NewResponseController(w)
+	if upload {
+		if err := controller.SetReadDeadline(deadline); err != nil {
+			s.logger.WarnContext(r.Context(), "failed to extend read deadline", slog.Any("error", err))
+		}
+	}
+	if err := controller.SetWriteDeadline(deadline); err != nil {
+		s.logger.WarnContext(r.Context(), "failed to extend write deadline", slog.Any("error", err))
+	}
+}
+
+// serveContentData writes data of the content or its thumbnail,
+// ServeContent detects Content-Type by the data if it is unknown and handles Range, If-Range and If-None-Match using the ETag
+func serveContentData(w http.ResponseWriter, r *http.Request, data *models.ContentData) {
+	defer data.Data.Close()
+
+	if data.MIMEType != "" {
+		w.Header().Set("Content-Type", data.MIMEType)
+	}
+	w.Header().Set("ETag", fmt.Sprintf("%q", data.Checksum))
+	http.ServeContent(w, r, "", time.Time{}, data.Data)
+}
+
+// makeThumbnails queues the image content for thumbnails, so the upload doesn't wait for them,
+// the context of the request gives only its request id
+func (s *Server) makeThumbnails(ctx context.Context, contentID int, mimeType string) {
+	if !thumbnail.Supported(mimeType) {
+		return
+	}
+
+	if !s.thumbnails.Enqueue(context.WithoutCancel(ctx), contentID) {
+		s.logger.WarnContext(ctx, "thumbnails queue is full, content is left without thumbnails", slog.Int("content_id", contentID))
+	}
+}
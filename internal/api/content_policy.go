@@ -0,0 +1,176 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+
+	_ "golang.org/x/image/webp"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// maxJSONOverhead is the room for the description and other fields of the content sent as JSON
+const maxJSONOverhead = 64 << 10
+
+// inspectLen is the prefix of data read to detect its type and image dimensions,
+// images with larger headers (like JPEG with big EXIF) get no dimensions
+const inspectLen = 64 << 10
+
+// contentPolicy detects metadata of uploaded contents and enforces the limits of config.Content, zero size means no limit
+type contentPolicy struct {
+	allowed      map[string]bool
+	allowedTypes []string
+	maxSize      int64
+	maxCraftSize int64
+}
+
+func newContentPolicy(cfg config.Content) *contentPolicy {
+	allowed := make(map[string]bool, len(cfg.AllowedTypes))
+	for _, mimeType := range cfg.AllowedTypes {
+		allowed[mimeType] = true
+	}
+
+	return &contentPolicy{allowed: allowed, allowedTypes: cfg.AllowedTypes, maxSize: cfg.MaxSize, maxCraftSize: cfg.MaxCraftSize}
+}
+
+// detect sets MIME type and image dimensions of the content by the beginning of data
+// and returns the reader of the whole data, data of not allowed type is rejected
+func (p *contentPolicy) detect(content *models.Content, data io.Reader) (io.Reader, error) {
+	reader := bufio.NewReaderSize(data, inspectLen)
+
+	head, err := reader.Peek(inspectLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("failed to read data: %w", err)
+	}
+	if len(head) == 0 {
+		return nil, errMissingData
+	}
+
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect content type: %w", err)
+	}
+
+	if !p.allowed[mimeType] {
+		return nil, response_errors.NewUnsupportedMediaTypeError(mimeType, p.allowedTypes)
+	}
+
+	content.MIMEType, content.Width, content.Height, content.Thumbnails = mimeType, 0, 0, nil
+	if imageConfig, _, err := image.DecodeConfig(bytes.NewReader(head)); err == nil {
+		content.Width, content.Height = imageConfig.Width, imageConfig.Height
+	}
+
+	return reader, nil
+}
+
+// decodeContent decodes the content sent as JSON, the body is limited by the base64 length of the max content size,
+// so larger data is rejected before it is read into memory
+func (s *Server) decodeContent(w http.ResponseWriter, r *http.Request, content *models.Content) error {
+	if s.contentPolicy.maxSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, int64(base64.StdEncoding.EncodedLen(int(s.contentPolicy.maxSize)))+maxJSONOverhead)
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(content); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return response_errors.NewContentTooLargeError(s.contentPolicy.maxSize)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkContent checks the content sent as JSON and sets its metadata
+func (s *Server) checkContent(content *models.Content, limit *sizeLimit) error {
+	if _, err := s.contentPolicy.detect(content, bytes.NewReader(content.Data)); err != nil {
+		return err
+	}
+
+	return limit.check(int64(len(content.Data)))
+}
+
+// writeContentError writes rejection of the content as JSON and other errors as bad request
+func writeContentError(w http.ResponseWriter, err error) {
+	var contentErr *response_errors.ContentError
+	if errors.As(err, &contentErr) {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	http.Error(w, fmt.Sprintf("incorrect content data: %s", err.Error()), http.StatusBadRequest)
+}
+
+// sizeLimit returns the limit of the content size, usedCraftSize is the size of other contents of the craft
+func (p *contentPolicy) sizeLimit(usedCraftSize int64) *sizeLimit {
+	limit := &sizeLimit{left: math.MaxInt64}
+
+	if p.maxSize > 0 {
+		limit.left, limit.err = p.maxSize, response_errors.NewContentTooLargeError(p.maxSize)
+	}
+
+	if p.maxCraftSize > 0 && p.maxCraftSize-usedCraftSize < limit.left {
+		limit.left, limit.err = max(p.maxCraftSize-usedCraftSize, 0), response_errors.NewCraftTooLargeError(p.maxCraftSize)
+	}
+
+	return limit
+}
+
+// sizeLimit counts size of data read through it and keeps the error of the limit it checks
+type sizeLimit struct {
+	reader io.Reader
+	left   int64
+	err    *response_errors.ContentError
+}
+
+func (sl *sizeLimit) check(size int64) error {
+	if size > sl.left {
+		return sl.err
+	}
+
+	return nil
+}
+
+// limit returns the reader which fails when data is larger than the limit
+func (sl *sizeLimit) limit(reader io.Reader) io.Reader {
+	sl.reader = reader
+	return sl
+}
+
+func (sl *sizeLimit) Read(p []byte) (int, error) {
+	if int64(len(p)) > sl.left {
+		// one more byte is enough to find out that the limit is exceeded
+		p = p[:sl.left+1]
+	}
+
+	n, err := sl.reader.Read(p)
+	sl.left -= int64(n)
+	if sl.left < 0 {
+		return n, sl.err
+	}
+
+	return n, err
+}
+
+// exceeded returns the error of the limit if the data read is larger than the limit
+func (sl *sizeLimit) exceeded() error {
+	if sl.left < 0 {
+		return sl.err
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// pathEvent makes the event of the object, its parents are taken from the request path,
+// ids of parents which are not in the path are zero
+func pathEvent(r *http.Request, profileID int, object sender.Object, id int, change sender.Change) sender.Event {
+	params := bunrouter.ParamsFromContext(r.Context())
+	portfolioID, _ := strconv.Atoi(params.ByName("id"))
+	craftID, _ := strconv.Atoi(params.ByName("craftID"))
+
+	switch object {
+	case sender.Portfolio:
+		return sender.PortfolioEvent(profileID, id, change)
+	case sender.Craft:
+		return sender.CraftEvent(profileID, portfolioID, id, change)
+	case sender.CraftTag:
+		return sender.CraftTagEvent(profileID, portfolioID, craftID, id, change)
+	default:
+		return sender.ContentEvent(profileID, portfolioID, craftID, id, change)
+	}
+}
+
+// trashEvent makes the event of the object in the trash, its parents are taken from the trash item
+func trashEvent(item models.TrashItem, change sender.Change) sender.Event {
+	switch sender.Object(item.Object) {
+	case sender.Portfolio:
+		return sender.PortfolioEvent(item.ProfileID, item.ID, change)
+	case sender.Craft:
+		return sender.CraftEvent(item.ProfileID, item.PortfolioID, item.ID, change)
+	default:
+		return sender.ContentEvent(item.ProfileID, item.PortfolioID, item.CraftID, item.ID, change)
+	}
+}
@@ -1,18 +1,21 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/uptrace/bunrouter"
 
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/validation"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/auth"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
-	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/storage/postgresql"
 )
 
 // @Summary Get portfolios
@@ -21,6 +24,7 @@ import (
 // @Produce json
 // @Param page query int false "page number"
 // @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
 // @Param id query int false "profile or category id"
 // @Param filter query string false "filtered by" Enums(ByProfileID, ByCategoryID)
 // @Success 200 {object} models.PortfoliosPage
@@ -44,7 +48,7 @@ func (s *Server) getPortfoliosHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	portfolios, pagesAmount, err := s.databaseConnector.GetAllPortfolios(r.Context(), page.limit, page.offset, id, postgresql.PortfoliosFilterType(filterType))
+	portfolios, pagesAmount, err := s.databaseConnector.GetAllPortfolios(r.Context(), page.model(), id, models.PortfoliosFilterType(filterType))
 	if err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, true)
 		return
@@ -55,6 +59,7 @@ func (s *Server) getPortfoliosHandler(w http.ResponseWriter, r *http.Request) {
 		PageNo:      page.number,
 		Limit:       page.limit,
 		PagesAmount: pagesAmount,
+		NextCursor:  nextCursor(page, portfolios),
 	}
 
 	_ = json.NewEncoder(w).Encode(response)
@@ -67,6 +72,7 @@ func (s *Server) getPortfoliosHandler(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "portfolio id"
 // @Success 200 {object} models.Portfolio
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
 // @Router /profiles/{profileID}/portfolios/{id} [get]
 func (s *Server) getPortfolioByIDHandler(w http.ResponseWriter, r *http.Request) {
@@ -91,10 +97,12 @@ func (s *Server) getPortfolioByIDHandler(w http.ResponseWriter, r *http.Request)
 // @Description create new portfolio, return its id
 // @Accept json
 // @Produce json
-// @Param portfolio body models.Portfolio true "portfolio without crafts, profile id is required"
+// @Param profileID path int true "profile id"
+// @Param portfolio body models.Portfolio true "portfolio without crafts, profile id is taken from the path if it is omitted"
 // @Success 200 {string} string
 // @Failure 400 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios [post]
 func (s *Server) postPortfolioHandler(w http.ResponseWriter, r *http.Request) {
 	var portfolio models.Portfolio
@@ -104,40 +112,63 @@ func (s *Server) postPortfolioHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if portfolio.ProfileID <= 0 {
-		http.Error(w, response_errors.ErrIncorrectID.Error(), http.StatusBadRequest)
+	profileIdStr, _ := bunrouter.ParamsFromContext(r.Context()).Get("profileID")
+	profileID, err := validation.ID(profileIdStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the portfolio is created in the profile of the path
+	if portfolio.ProfileID == 0 {
+		portfolio.ProfileID = profileID
+	}
+	if portfolio.ProfileID != profileID {
+		http.Error(w, "incorrect portfolio data: profile id doesn't match the path", http.StatusBadRequest)
 		return
 	}
 
 	portfolioID, err := s.databaseConnector.CreatePortfolio(r.Context(), portfolio)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response_errors.StatusCodeByErrorWriter(err, w, false)
 		return
 	}
 
-	go s.sender.SendEvent(portfolio.ProfileID, sender.Portfolio, portfolioID, sender.CreateObj)
+	go s.sender.SendEvent(r.Context(), sender.PortfolioEvent(portfolio.ProfileID, portfolioID, sender.CreateObj))
 
 	_ = json.NewEncoder(w).Encode(portfolioID)
 }
 
 // @Summary Patch portfolio
 // @Tags portfolios
-// @Description update portfolio by its id
+// @Description update portfolio by its id, previous values are saved as a revision
 // @Accept json
 // @Param id path int true "portfolio id"
+// @Param profileID path int true "profile id"
 // @Param portfolio body models.Portfolio true "updated portfolio, info without changes is also required"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id} [patch]
 func (s *Server) patchPortfolioHandler(w http.ResponseWriter, r *http.Request) {
-	idStr, _ := bunrouter.ParamsFromContext(r.Context()).Get("id")
+	params := bunrouter.ParamsFromContext(r.Context())
+
+	idStr, _ := params.Get("id")
 	id, err := validation.ID(idStr)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	profileIdStr, _ := params.Get("profileID")
+	profileID, err := validation.ID(profileIdStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var portfolio models.Portfolio
 	defer r.Body.Close()
 	if err = json.NewDecoder(r.Body).Decode(&portfolio); err != nil {
@@ -145,13 +176,22 @@ func (s *Server) patchPortfolioHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the portfolio stays in the profile of the path
+	if portfolio.ProfileID == 0 {
+		portfolio.ProfileID = profileID
+	}
+	if portfolio.ProfileID != profileID {
+		http.Error(w, "incorrect portfolio data: profile id doesn't match the path", http.StatusBadRequest)
+		return
+	}
+
 	portfolio.ID = id
-	if err = s.databaseConnector.PatchPortfolio(r.Context(), portfolio); err != nil {
+	if err = s.databaseConnector.PatchPortfolio(r.Context(), profileID, portfolio); err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, false)
 		return
 	}
 
-	go s.sender.SendEvent(portfolio.ProfileID, sender.Portfolio, portfolio.ID, sender.UpdateObj)
+	go s.sender.SendEvent(r.Context(), sender.PortfolioEvent(profileID, portfolio.ID, sender.UpdateObj))
 
 	w.WriteHeader(http.StatusOK)
 	return
@@ -159,13 +199,15 @@ func (s *Server) patchPortfolioHandler(w http.ResponseWriter, r *http.Request) {
 
 // @Summary Delete portfolio
 // @Tags portfolios
-// @Description delete portfolio by its id
+// @Description move portfolio with its crafts and contents to the trash, it can be restored until it is purged
 // @Param page query int false "page number"
 // @Param id path int true "portfolio id"
 // @Param profileID path int true "profile id"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id} [delete]
 func (s *Server) deletePortfolioHandler(w http.ResponseWriter, r *http.Request) {
 	params := bunrouter.ParamsFromContext(r.Context())
@@ -189,20 +231,23 @@ func (s *Server) deletePortfolioHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Portfolio, id, sender.DeleteObj)
+	go s.sender.SendEvent(r.Context(), sender.PortfolioEvent(profileID, id, sender.TrashObj))
 
 	w.WriteHeader(http.StatusOK)
 }
 
 // @Summary Post category
 // @Tags categories
-// @Description create new category, return its id
+// @Description create new category, return its id. Categories of users without moderator or admin role are proposals, they are pending until they are approved
 // @Accept json
 // @Produce json
 // @Param category body models.Category true "category, name required"
 // @Success 200 {string} string
+// @Success 202 {string} string
 // @Failure 400 {string} string
+// @Failure 401 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /categories [post]
 func (s *Server) postCategoryHandler(w http.ResponseWriter, r *http.Request) {
 	var category models.Category
@@ -217,24 +262,50 @@ func (s *Server) postCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.policy.allows(r.Context(), manageTaxonomy) {
+		if !s.policy.require(w, r, proposeTaxonomy) {
+			return
+		}
+
+		identity, _ := auth.FromContext(r.Context())
+		id, err := s.databaseConnector.ProposeCategory(r.Context(), category.Name, identity.ProfileID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(id)
+		return
+	}
+
 	id, err := s.databaseConnector.CreateCategory(r.Context(), category.Name)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	go s.sender.SendEvent(r.Context(), sender.CategoryEvent(id, sender.CreateObj))
+
 	_ = json.NewEncoder(w).Encode(id)
 }
 
 // @Summary Delete category
 // @Tags categories
-// @Description delete category by its id
+// @Description delete category by its id, admins only
 // @Param id path int true "category id"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /categories/{id} [delete]
 func (s *Server) deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.policy.require(w, r, deleteTaxonomy) {
+		return
+	}
+
 	idStr, _ := bunrouter.ParamsFromContext(r.Context()).Get("id")
 	id, err := validation.ID(idStr)
 	if err != nil {
@@ -247,6 +318,8 @@ func (s *Server) deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	go s.sender.SendEvent(r.Context(), sender.CategoryEvent(id, sender.DeleteObj))
+
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -256,39 +329,46 @@ func (s *Server) deleteCategoryHandler(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param page query int false "page number"
 // @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
 // @Success 200 {object} models.CategoriesPage
 // @Success 204
 // @Failure 400 {string} string
 // @Failure 500	{string} string
 // @Router /categories [get]
 func (s *Server) getCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	s.getCategories(w, r, false)
+}
+
+func (s *Server) getCategories(w http.ResponseWriter, r *http.Request, pending bool) {
 	page, err := s.getPageInfo(r)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("incorrect page info: %s", err.Error()), http.StatusBadRequest)
 		return
 	}
 
-	categories, pagesAmount, err := s.databaseConnector.GetAllCategories(r.Context(), page.limit, page.offset)
+	categories, pagesAmount, err := s.databaseConnector.GetAllCategories(r.Context(), page.model(), pending)
 	if err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, true)
 		return
 	}
 
-	response := models.CategoriesPage{Categories: categories, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount}
+	response := models.CategoriesPage{Categories: categories, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount, NextCursor: nextCursor(page, categories)}
 
 	_ = json.NewEncoder(w).Encode(response)
 }
 
 // @Summary Get crafts by portfolio id
 // @Tags crafts
-// @Description get all crafts by portfolio id
+// @Description get all crafts by portfolio id, the preview content of every craft has data_url and thumbnails instead of data
 // @Produce json
 // @Param page query int false "page number"
 // @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
 // @Param id path int true "portfolio id"
 // @Success 200 {object} models.CraftsPage
 // @Success 204
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
 // @Router /profiles/{profileID}/portfolios/{id}/crafts [get]
 func (s *Server) getCraftsByPortfolioIDHandler(w http.ResponseWriter, r *http.Request) {
@@ -305,13 +385,17 @@ func (s *Server) getCraftsByPortfolioIDHandler(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	crafts, pagesAmount, err := s.databaseConnector.GetAllCraftsByPortfolioID(r.Context(), id, page.limit, page.offset)
+	crafts, pagesAmount, err := s.databaseConnector.GetAllCraftsByPortfolioID(r.Context(), id, page.model())
 	if err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, true)
 		return
 	}
 
-	response := models.CraftsPage{Crafts: crafts, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount}
+	for i := range crafts {
+		setContentURLs(&crafts[i], true)
+	}
+
+	response := models.CraftsPage{Crafts: crafts, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount, NextCursor: nextCursor(page, crafts)}
 
 	_ = json.NewEncoder(w).Encode(response)
 }
@@ -321,8 +405,10 @@ func (s *Server) getCraftsByPortfolioIDHandler(w http.ResponseWriter, r *http.Re
 // @Description get craft by its id
 // @Produce json
 // @Param craftID path int true "craft id"
+// @Param data query string false "embed (default) returns data of contents, url returns data_url for download instead"
 // @Success 200 {object} models.Craft
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID} [get]
 func (s *Server) getCraftHandler(w http.ResponseWriter, r *http.Request) {
@@ -333,12 +419,20 @@ func (s *Server) getCraftHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	byURL, err := dataByURL(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	craft, err := s.databaseConnector.GetCraftByID(r.Context(), id)
 	if err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, false)
 		return
 	}
 
+	setContentURLs(craft, byURL)
+
 	_ = json.NewEncoder(w).Encode(craft)
 }
 
@@ -352,7 +446,9 @@ func (s *Server) getCraftHandler(w http.ResponseWriter, r *http.Request) {
 // @Param craft body models.Craft true "craft without contents"
 // @Success 200 {string} string
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts [post]
 func (s *Server) postCraftHandler(w http.ResponseWriter, r *http.Request) {
 	params := bunrouter.ParamsFromContext(r.Context())
@@ -380,11 +476,11 @@ func (s *Server) postCraftHandler(w http.ResponseWriter, r *http.Request) {
 
 	craftID, err := s.databaseConnector.CreateCraft(r.Context(), id, craft)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response_errors.StatusCodeByErrorWriter(err, w, false)
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Craft, craftID, sender.CreateObj)
+	go s.sender.SendEvent(r.Context(), sender.CraftEvent(profileID, id, craftID, sender.CreateObj))
 
 	_ = json.NewEncoder(w).Encode(craftID)
 }
@@ -397,7 +493,9 @@ func (s *Server) postCraftHandler(w http.ResponseWriter, r *http.Request) {
 // @Param tagID query int true "tag id"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/tags/{tagID} [post]
 func (s *Server) postTagPatchCraftHandler(w http.ResponseWriter, r *http.Request) {
 	params := bunrouter.ParamsFromContext(r.Context())
@@ -428,7 +526,11 @@ func (s *Server) postTagPatchCraftHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Craft, craftID, sender.UpdateObj)
+	tagEvent, craftEvent := pathEvent(r, profileID, sender.CraftTag, tagID, sender.AttachObj), pathEvent(r, profileID, sender.Craft, craftID, sender.UpdateObj)
+	go func() {
+		s.sender.SendEvent(r.Context(), tagEvent)
+		s.sender.SendEvent(r.Context(), craftEvent)
+	}()
 
 	w.WriteHeader(http.StatusOK)
 }
@@ -441,7 +543,9 @@ func (s *Server) postTagPatchCraftHandler(w http.ResponseWriter, r *http.Request
 // @Param tagID path int true "tag id"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/tags/{tagID} [delete]
 func (s *Server) deleteTagPatchCraftHandler(w http.ResponseWriter, r *http.Request) {
 	params := bunrouter.ParamsFromContext(r.Context())
@@ -472,21 +576,27 @@ func (s *Server) deleteTagPatchCraftHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Craft, craftID, sender.UpdateObj)
+	tagEvent, craftEvent := pathEvent(r, profileID, sender.CraftTag, tagID, sender.DetachObj), pathEvent(r, profileID, sender.Craft, craftID, sender.UpdateObj)
+	go func() {
+		s.sender.SendEvent(r.Context(), tagEvent)
+		s.sender.SendEvent(r.Context(), craftEvent)
+	}()
 
 	w.WriteHeader(http.StatusOK)
 }
 
 // @Summary Patch craft
 // @Tags crafts
-// @Description update craft by its id
+// @Description update craft by its id, previous values are saved as a revision
 // @Accept json
 // @Param profileID path int true "profile id"
 // @Param craftID path int true "craft id"
 // @Param craft body models.Craft true "updated craft, info without changes is also required"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID} [patch]
 func (s *Server) patchCraftHandler(w http.ResponseWriter, r *http.Request) {
 	params := bunrouter.ParamsFromContext(r.Context())
@@ -514,24 +624,26 @@ func (s *Server) patchCraftHandler(w http.ResponseWriter, r *http.Request) {
 
 	craft.ID = id
 
-	if err = s.databaseConnector.PatchCraft(r.Context(), craft); err != nil {
+	if err = s.databaseConnector.PatchCraft(r.Context(), profileID, craft); err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, false)
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Craft, craft.ID, sender.UpdateObj)
+	go s.sender.SendEvent(r.Context(), pathEvent(r, profileID, sender.Craft, craft.ID, sender.UpdateObj))
 
 	w.WriteHeader(http.StatusOK)
 }
 
 // @Summary Delete craft
 // @Tags crafts
-// @Description delete craft by its id
+// @Description move craft with its contents to the trash, it can be restored until it is purged
 // @Param profileID path int true "profile id"
 // @Param craftID path int true "craft id"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID} [delete]
 func (s *Server) deleteCraftHandler(w http.ResponseWriter, r *http.Request) {
 	params := bunrouter.ParamsFromContext(r.Context())
@@ -555,15 +667,18 @@ func (s *Server) deleteCraftHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Craft, id, sender.DeleteObj)
+	go s.sender.SendEvent(r.Context(), pathEvent(r, profileID, sender.Craft, id, sender.TrashObj))
 
 	w.WriteHeader(http.StatusOK)
 }
 
 // @Summary Get crafts by tag
 // @Tags crafts
-// @Description get all crafts by tag id
+// @Description get all crafts by tag id, the preview content of every craft has data_url and thumbnails instead of data
 // @Produce json
+// @Param page query int false "page number"
+// @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
 // @Param id path int true "tag id"
 // @Success 200 {object} models.CraftsPage
 // @Success 204
@@ -584,13 +699,17 @@ func (s *Server) getCraftsByTagIDHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	crafts, pagesAmount, err := s.databaseConnector.GetAllCraftsByTagID(r.Context(), id, page.limit, page.offset)
+	crafts, pagesAmount, err := s.databaseConnector.GetAllCraftsByTagID(r.Context(), id, page.model())
 	if err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, true)
 		return
 	}
 
-	response := models.CraftsPage{Crafts: crafts, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount}
+	for i := range crafts {
+		setContentURLs(&crafts[i], true)
+	}
+
+	response := models.CraftsPage{Crafts: crafts, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount, NextCursor: nextCursor(page, crafts)}
 
 	_ = json.NewEncoder(w).Encode(response)
 }
@@ -601,38 +720,46 @@ func (s *Server) getCraftsByTagIDHandler(w http.ResponseWriter, r *http.Request)
 // @Produce json
 // @Param page query int false "page number"
 // @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
 // @Success 200 {object} models.TagsPage
 // @Success 204
 // @Failure 400 {string} string
 // @Failure 500	{string} string
 // @Router /tags [get]
 func (s *Server) getTagsHandler(w http.ResponseWriter, r *http.Request) {
+	s.getTags(w, r, false)
+}
+
+func (s *Server) getTags(w http.ResponseWriter, r *http.Request, pending bool) {
 	page, err := s.getPageInfo(r)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("incorrect page info: %s", err.Error()), http.StatusBadRequest)
 		return
 	}
 
-	tags, pagesAmount, err := s.databaseConnector.GetAllTags(r.Context(), page.limit, page.offset)
+	tags, pagesAmount, err := s.databaseConnector.GetAllTags(r.Context(), page.model(), pending)
 	if err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, true)
 		return
 	}
 
-	response := models.TagsPage{Tags: tags, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount}
+	response := models.TagsPage{Tags: tags, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount, NextCursor: nextCursor(page, tags)}
 
 	_ = json.NewEncoder(w).Encode(response)
 }
 
 // @Summary Post tag
 // @Tags tags
-// @Description create new tag, return its id
+// @Description create new tag, return its id. Tags of users without moderator or admin role are proposals, they are pending until they are approved
 // @Accept json
 // @Produce json
 // @Param tag body models.Tag true "tag name required"
 // @Success 200 {string} string
+// @Success 202 {string} string
 // @Failure 400 {string} string
+// @Failure 401 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /tags [post]
 func (s *Server) postTagHandler(w http.ResponseWriter, r *http.Request) {
 	var tag models.Tag
@@ -642,24 +769,50 @@ func (s *Server) postTagHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !s.policy.allows(r.Context(), manageTaxonomy) {
+		if !s.policy.require(w, r, proposeTaxonomy) {
+			return
+		}
+
+		identity, _ := auth.FromContext(r.Context())
+		id, err := s.databaseConnector.ProposeTag(r.Context(), tag.Name, identity.ProfileID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(id)
+		return
+	}
+
 	id, err := s.databaseConnector.CreateTag(r.Context(), tag.Name)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	go s.sender.SendEvent(r.Context(), sender.TagEvent(id, sender.CreateObj))
+
 	_ = json.NewEncoder(w).Encode(id)
 }
 
 // @Summary Delete tag
 // @Tags tags
-// @Description delete tag by its id
+// @Description delete tag by its id, admins only
 // @Param id path int true "tag id"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /tags/{id} [delete]
 func (s *Server) deleteTagHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.policy.require(w, r, deleteTaxonomy) {
+		return
+	}
+
 	idStr, _ := bunrouter.ParamsFromContext(r.Context()).Get("id")
 	id, err := validation.ID(idStr)
 	if err != nil {
@@ -672,22 +825,32 @@ func (s *Server) deleteTagHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	go s.sender.SendEvent(r.Context(), sender.TagEvent(id, sender.DeleteObj))
+
 	w.WriteHeader(http.StatusOK)
 }
 
 // @Summary Post content
 // @Tags contents
-// @Description create new content, return its id
-// @Accept json
+// @Description create new content, return its id. Content is sent as JSON with base64 data or as multipart/form-data with content_description field followed by data file, the file is streamed to the storage
+// @Accept json,mpfd
 // @Produce json
 // @Param profileID path int true "profile id"
 // @Param craftID path int true "craft id"
-// @Param content body models.Content true "content"
+// @Param content body models.Content false "content, for JSON requests"
+// @Param content_description formData string false "content description, for multipart requests, must be sent before data"
+// @Param data formData file false "content data, for multipart requests"
 // @Success 200 {object} models.PortfoliosPage
 // @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 413 {object} response_errors.ContentError
+// @Failure 415 {object} response_errors.ContentError
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents [post]
 func (s *Server) postContentHandler(w http.ResponseWriter, r *http.Request) {
+	s.extendDeadlines(w, r, true)
+
 	params := bunrouter.ParamsFromContext(r.Context())
 
 	craftIdStr, _ := params.Get("craftID")
@@ -704,37 +867,71 @@ func (s *Server) postContentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var content models.Content
 	defer r.Body.Close()
-	if err = json.NewDecoder(r.Body).Decode(&content); err != nil {
-		http.Error(w, fmt.Sprintf("incorrect content data: %s", err.Error()), http.StatusBadRequest)
-		return
-	}
 
-	if len(content.Data) == 0 {
-		http.Error(w, "content data is required", http.StatusBadRequest)
-		return
-	}
-
-	id, err := s.databaseConnector.CreateContent(r.Context(), craftID, content)
+	usedCraftSize, err := s.databaseConnector.GetCraftContentsSize(r.Context(), craftID, 0)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		response_errors.StatusCodeByErrorWriter(err, w, false)
 		return
 	}
+	limit := s.contentPolicy.sizeLimit(usedCraftSize)
 
-	go s.sender.SendEvent(profileID, sender.Content, id, sender.CreateObj)
+	var id int
+	var content models.Content
+	if isMultipart(r) {
+		var data io.Reader
+		if content, data, err = readContentParts(r); err != nil {
+			http.Error(w, fmt.Sprintf("incorrect content data: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+
+		if data, err = s.contentPolicy.detect(&content, data); err != nil {
+			writeContentError(w, err)
+			return
+		}
+
+		if id, err = s.databaseConnector.CreateContentFromReader(r.Context(), craftID, content, limit.limit(data)); err != nil {
+			if limitErr := limit.exceeded(); limitErr != nil {
+				response_errors.StatusCodeByErrorWriter(limitErr, w, false)
+				return
+			}
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		if err = s.decodeContent(w, r, &content); err != nil {
+			writeContentError(w, err)
+			return
+		}
+
+		if err = s.checkContent(&content, limit); err != nil {
+			writeContentError(w, err)
+			return
+		}
+
+		if id, err = s.databaseConnector.CreateContent(r.Context(), craftID, content); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	s.makeThumbnails(r.Context(), id, content.MIMEType)
+
+	go s.sender.SendEvent(r.Context(), pathEvent(r, profileID, sender.Content, id, sender.CreateObj))
 
 	_ = json.NewEncoder(w).Encode(id)
 }
 
 // @Summary Delete content
 // @Tags contents
-// @Description delete content by its id
+// @Description move content to the trash, it can be restored until it is purged
 // @Param profileID path int true "profile id"
 // @Param contentID path int true "content id"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID} [delete]
 func (s *Server) deleteContentHandler(w http.ResponseWriter, r *http.Request) {
 	params := bunrouter.ParamsFromContext(r.Context())
@@ -758,23 +955,29 @@ func (s *Server) deleteContentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Content, id, sender.DeleteObj)
+	go s.sender.SendEvent(r.Context(), pathEvent(r, profileID, sender.Content, id, sender.TrashObj))
 
 	w.WriteHeader(http.StatusOK)
 }
 
 // @Summary Patch content
 // @Tags contents
-// @Description update content by its id
+// @Description update content by its id, previous values are saved as a revision
 // @Accept json
 // @Param profileID path int true "profile id"
 // @Param contentID path int true "content id"
 // @Param content body models.Content true "updated content, info without changes is also required"
 // @Success 200
 // @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 413 {object} response_errors.ContentError
+// @Failure 415 {object} response_errors.ContentError
 // @Failure 500	{string} string
+// @Security BearerAuth
 // @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID} [patch]
 func (s *Server) patchContentHandler(w http.ResponseWriter, r *http.Request) {
+	s.extendDeadlines(w, r, true)
+
 	params := bunrouter.ParamsFromContext(r.Context())
 
 	idStr, _ := params.Get("contentID")
@@ -791,26 +994,759 @@ func (s *Server) patchContentHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	craftIdStr, _ := params.Get("craftID")
+	craftID, err := validation.ID(craftIdStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var content models.Content
 	defer r.Body.Close()
-	if err = json.NewDecoder(r.Body).Decode(&content); err != nil {
-		http.Error(w, fmt.Sprintf("incorrect content data: %s", err.Error()), http.StatusBadRequest)
+	if err = s.decodeContent(w, r, &content); err != nil {
+		writeContentError(w, err)
 		return
 	}
 
-	if len(content.Data) == 0 {
-		http.Error(w, "content data is required", http.StatusBadRequest)
+	usedCraftSize, err := s.databaseConnector.GetCraftContentsSize(r.Context(), craftID, id)
+	if err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	if err = s.checkContent(&content, s.contentPolicy.sizeLimit(usedCraftSize)); err != nil {
+		writeContentError(w, err)
 		return
 	}
 
 	content.ID = id
 
-	if err = s.databaseConnector.PatchContent(r.Context(), content); err != nil {
+	if err = s.databaseConnector.PatchContent(r.Context(), profileID, content); err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	s.makeThumbnails(r.Context(), content.ID, content.MIMEType)
+
+	go s.sender.SendEvent(r.Context(), pathEvent(r, profileID, sender.Content, content.ID, sender.UpdateObj))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Search
+// @Tags search
+// @Description full-text search of portfolios and crafts by names, descriptions, tags and contents descriptions, results are sorted by rank
+// @Produce json
+// @Param q query string true "search query, supports quoted phrases, OR and -word"
+// @Param category query int false "category id of portfolios"
+// @Param tag query int false "tag id of crafts, portfolios are found if any of their crafts has the tag"
+// @Param page query int false "page number"
+// @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
+// @Success 200 {object} models.SearchPage
+// @Failure 400 {string} string
+// @Failure 500	{string} string
+// @Failure 501	{string} string
+// @Router /search [get]
+func (s *Server) getSearchHandler(w http.ResponseWriter, r *http.Request) {
+	query := models.SearchQuery{Text: strings.TrimSpace(r.FormValue("q"))}
+	if query.Text == "" {
+		http.Error(w, "missing search query: q is required", http.StatusBadRequest)
+		return
+	}
+
+	if categoryStr := r.FormValue("category"); categoryStr != "" {
+		categoryID, err := validation.ID(categoryStr)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("incorrect category: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		query.CategoryID = categoryID
+	}
+
+	if tagStr := r.FormValue("tag"); tagStr != "" {
+		tagID, err := validation.ID(tagStr)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("incorrect tag: %s", err.Error()), http.StatusBadRequest)
+			return
+		}
+		query.TagID = tagID
+	}
+
+	page, err := s.getPageInfo(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("incorrect page info: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	results, pagesAmount, err := s.databaseConnector.Search(r.Context(), query, page.model())
+	if err != nil {
 		response_errors.StatusCodeByErrorWriter(err, w, false)
 		return
 	}
 
-	go s.sender.SendEvent(profileID, sender.Content, content.ID, sender.UpdateObj)
+	if results == nil {
+		results = []models.SearchResult{}
+	}
+
+	response := models.SearchPage{Results: results, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount, NextCursor: nextCursor(page, results)}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// @Summary Get trash
+// @Tags trash
+// @Description get deleted portfolios, crafts and contents of the profile which are not purged yet, the most recently deleted first
+// @Produce json
+// @Param profileID path int true "profile id"
+// @Success 200 {object} models.Trash
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/trash [get]
+func (s *Server) getTrashHandler(w http.ResponseWriter, r *http.Request) {
+	profileIdStr, _ := bunrouter.ParamsFromContext(r.Context()).Get("profileID")
+	profileID, err := validation.ID(profileIdStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	items, err := s.databaseConnector.GetTrash(r.Context(), profileID)
+	if err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	if items == nil {
+		items = []models.TrashItem{}
+	}
+
+	_ = json.NewEncoder(w).Encode(models.Trash{Items: items})
+}
+
+// @Summary Restore portfolio
+// @Tags trash
+// @Description restore deleted portfolio with crafts and contents deleted together with it
+// @Param profileID path int true "profile id"
+// @Param id path int true "portfolio id"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/trash/portfolios/{id}/restore [post]
+func (s *Server) restorePortfolioHandler(w http.ResponseWriter, r *http.Request) {
+	s.restore(w, r, sender.Portfolio, s.databaseConnector.RestorePortfolio)
+}
+
+// @Summary Restore craft
+// @Tags trash
+// @Description restore deleted craft with contents deleted together with it, craft of deleted portfolio can't be restored separately
+// @Param profileID path int true "profile id"
+// @Param id path int true "craft id"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/trash/crafts/{id}/restore [post]
+func (s *Server) restoreCraftHandler(w http.ResponseWriter, r *http.Request) {
+	s.restore(w, r, sender.Craft, s.databaseConnector.RestoreCraft)
+}
+
+// @Summary Restore content
+// @Tags trash
+// @Description restore deleted content, content of deleted craft or portfolio can't be restored separately
+// @Param profileID path int true "profile id"
+// @Param id path int true "content id"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/trash/contents/{id}/restore [post]
+func (s *Server) restoreContentHandler(w http.ResponseWriter, r *http.Request) {
+	s.restore(w, r, sender.Content, s.databaseConnector.RestoreContent)
+}
+
+func (s *Server) restore(w http.ResponseWriter, r *http.Request, obj sender.Object, restoreFunc func(ctx context.Context, profileID, id int) error) {
+	params := bunrouter.ParamsFromContext(r.Context())
+
+	idStr, _ := params.Get("id")
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profileIdStr, _ := params.Get("profileID")
+	profileID, err := validation.ID(profileIdStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the path of the trash has no parents of the object, they are taken from the trash item before it is restored
+	items, err := s.databaseConnector.GetTrash(r.Context(), profileID)
+	if err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+	item := models.TrashItem{Object: string(obj), ID: id, ProfileID: profileID}
+	for _, trashItem := range items {
+		if trashItem.Object == item.Object && trashItem.ID == id {
+			item = trashItem
+			break
+		}
+	}
+
+	if err = restoreFunc(r.Context(), profileID, id); err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	go s.sender.SendEvent(r.Context(), trashEvent(item, sender.RestoreObj))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Get portfolio revisions
+// @Tags revisions
+// @Description get previous values of the portfolio, the oldest first
+// @Produce json
+// @Param profileID path int true "profile id"
+// @Param id path int true "portfolio id"
+// @Param page query int false "page number"
+// @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
+// @Success 200 {object} models.RevisionsPage
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/revisions [get]
+func (s *Server) getPortfolioRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	s.getRevisions(w, r, models.ObjectPortfolio, "id")
+}
+
+// @Summary Get portfolio revision
+// @Tags revisions
+// @Description get values of the portfolio saved in the revision
+// @Produce json
+// @Param profileID path int true "profile id"
+// @Param id path int true "portfolio id"
+// @Param rev path int true "revision number"
+// @Success 200 {object} models.Revision
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/revisions/{rev} [get]
+func (s *Server) getPortfolioRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	s.getRevision(w, r, models.ObjectPortfolio, "id")
+}
+
+// @Summary Revert portfolio
+// @Tags revisions
+// @Description restore values of the portfolio saved in the revision, current values are saved as a new revision
+// @Param profileID path int true "profile id"
+// @Param id path int true "portfolio id"
+// @Param rev path int true "revision number"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/revisions/{rev}/revert [post]
+func (s *Server) revertPortfolioHandler(w http.ResponseWriter, r *http.Request) {
+	s.revert(w, r, models.ObjectPortfolio, "id")
+}
+
+// @Summary Get craft revisions
+// @Tags revisions
+// @Description get previous values of the craft, the oldest first
+// @Produce json
+// @Param profileID path int true "profile id"
+// @Param craftID path int true "craft id"
+// @Param page query int false "page number"
+// @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
+// @Success 200 {object} models.RevisionsPage
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/revisions [get]
+func (s *Server) getCraftRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	s.getRevisions(w, r, models.ObjectCraft, "craftID")
+}
+
+// @Summary Get craft revision
+// @Tags revisions
+// @Description get values of the craft saved in the revision
+// @Produce json
+// @Param profileID path int true "profile id"
+// @Param craftID path int true "craft id"
+// @Param rev path int true "revision number"
+// @Success 200 {object} models.Revision
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/revisions/{rev} [get]
+func (s *Server) getCraftRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	s.getRevision(w, r, models.ObjectCraft, "craftID")
+}
+
+// @Summary Revert craft
+// @Tags revisions
+// @Description restore values of the craft saved in the revision, current values are saved as a new revision
+// @Param profileID path int true "profile id"
+// @Param craftID path int true "craft id"
+// @Param rev path int true "revision number"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/revisions/{rev}/revert [post]
+func (s *Server) revertCraftHandler(w http.ResponseWriter, r *http.Request) {
+	s.revert(w, r, models.ObjectCraft, "craftID")
+}
+
+// @Summary Get content revisions
+// @Tags revisions
+// @Description get previous values of the content, the oldest first
+// @Produce json
+// @Param profileID path int true "profile id"
+// @Param contentID path int true "content id"
+// @Param page query int false "page number"
+// @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
+// @Success 200 {object} models.RevisionsPage
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/revisions [get]
+func (s *Server) getContentRevisionsHandler(w http.ResponseWriter, r *http.Request) {
+	s.getRevisions(w, r, models.ObjectContent, "contentID")
+}
+
+// @Summary Get content revision
+// @Tags revisions
+// @Description get values of the content saved in the revision
+// @Produce json
+// @Param profileID path int true "profile id"
+// @Param contentID path int true "content id"
+// @Param rev path int true "revision number"
+// @Success 200 {object} models.Revision
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/revisions/{rev} [get]
+func (s *Server) getContentRevisionHandler(w http.ResponseWriter, r *http.Request) {
+	s.getRevision(w, r, models.ObjectContent, "contentID")
+}
+
+// @Summary Revert content
+// @Tags revisions
+// @Description restore values of the content saved in the revision, current values are saved as a new revision
+// @Param profileID path int true "profile id"
+// @Param contentID path int true "content id"
+// @Param rev path int true "revision number"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/revisions/{rev}/revert [post]
+func (s *Server) revertContentHandler(w http.ResponseWriter, r *http.Request) {
+	s.revert(w, r, models.ObjectContent, "contentID")
+}
+
+// getRevisions writes revisions of the object which id is kept in idParam of the path
+func (s *Server) getRevisions(w http.ResponseWriter, r *http.Request, object string, idParam string) {
+	idStr, _ := bunrouter.ParamsFromContext(r.Context()).Get(idParam)
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.getPageInfo(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("incorrect page info: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	revisions, pagesAmount, err := s.databaseConnector.GetRevisions(r.Context(), object, id, page.model())
+	if err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	if revisions == nil {
+		revisions = []models.Revision{}
+	}
+
+	response := models.RevisionsPage{Revisions: revisions, PageNo: page.number, Limit: page.limit, PagesAmount: pagesAmount, NextCursor: nextCursor(page, revisions)}
+
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) getRevision(w http.ResponseWriter, r *http.Request, object string, idParam string) {
+	params := bunrouter.ParamsFromContext(r.Context())
+
+	idStr, _ := params.Get(idParam)
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revisionStr, _ := params.Get("rev")
+	revision, err := validation.ID(revisionStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("incorrect revision: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.databaseConnector.GetRevision(r.Context(), object, id, revision)
+	if err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) revert(w http.ResponseWriter, r *http.Request, object string, idParam string) {
+	params := bunrouter.ParamsFromContext(r.Context())
+
+	idStr, _ := params.Get(idParam)
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profileIdStr, _ := params.Get("profileID")
+	profileID, err := validation.ID(profileIdStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revisionStr, _ := params.Get("rev")
+	revision, err := validation.ID(revisionStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("incorrect revision: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if err = s.databaseConnector.Revert(r.Context(), profileID, object, id, revision); err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	go s.sender.SendEvent(r.Context(), pathEvent(r, profileID, sender.Object(object), id, sender.UpdateObj))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Get content data
+// @Tags contents
+// @Description download raw data of the content, supports Range and If-None-Match requests, ETag is SHA-256 of the data
+// @Produce octet-stream
+// @Param profileID path int true "profile id"
+// @Param contentID path int true "content id"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Success 304
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 416 {string} string
+// @Failure 500	{string} string
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/data [get]
+func (s *Server) getContentDataHandler(w http.ResponseWriter, r *http.Request) {
+	s.extendDeadlines(w, r, false)
+
+	idStr, _ := bunrouter.ParamsFromContext(r.Context()).Get("contentID")
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.databaseConnector.GetContentData(r.Context(), id)
+	if err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	serveContentData(w, r, data)
+}
+
+// @Summary Get content thumbnail
+// @Tags contents
+// @Description download the thumbnail of the image content, sizes of thumbnails are listed in thumbnails of the content, ETag is SHA-256 of the thumbnail
+// @Produce jpeg
+// @Produce png
+// @Param profileID path int true "profile id"
+// @Param contentID path int true "content id"
+// @Param size path int true "thumbnail size"
+// @Success 200 {file} file
+// @Success 304
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/{contentID}/thumbnails/{size} [get]
+func (s *Server) getContentThumbnailHandler(w http.ResponseWriter, r *http.Request) {
+	s.extendDeadlines(w, r, false)
+
+	params := bunrouter.ParamsFromContext(r.Context())
+
+	idStr, _ := params.Get("contentID")
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sizeStr, _ := params.Get("size")
+	size, err := validation.ID(sizeStr)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("incorrect size: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	data, err := s.databaseConnector.GetThumbnailData(r.Context(), id, size)
+	if err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	serveContentData(w, r, data)
+}
+
+// @Summary Reorder crafts
+// @Tags crafts
+// @Description set order of crafts of the portfolio, ids of all not deleted crafts of the portfolio must be sent exactly once
+// @Accept json
+// @Param profileID path int true "profile id"
+// @Param id path int true "portfolio id"
+// @Param order body models.Order true "ids of crafts in the new order"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/order [put]
+func (s *Server) reorderCraftsHandler(w http.ResponseWriter, r *http.Request) {
+	s.reorder(w, r, sender.Portfolio, "id", s.databaseConnector.ReorderCrafts)
+}
+
+// @Summary Reorder contents
+// @Tags contents
+// @Description set order of contents of the craft, ids of all not deleted contents of the craft must be sent exactly once
+// @Accept json
+// @Param profileID path int true "profile id"
+// @Param craftID path int true "craft id"
+// @Param order body models.Order true "ids of contents in the new order"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /profiles/{profileID}/portfolios/{id}/crafts/{craftID}/contents/order [put]
+func (s *Server) reorderContentsHandler(w http.ResponseWriter, r *http.Request) {
+	s.reorder(w, r, sender.Craft, "craftID", s.databaseConnector.ReorderContents)
+}
+
+// reorder sets order of children of the object, the object is reported as changed
+func (s *Server) reorder(w http.ResponseWriter, r *http.Request, object sender.Object, idParam string, reorder func(ctx context.Context, id int, order models.Order) error) {
+	params := bunrouter.ParamsFromContext(r.Context())
+
+	idStr, _ := params.Get(idParam)
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profileIdStr, _ := params.Get("profileID")
+	profileID, err := validation.ID(profileIdStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var order models.Order
+	defer r.Body.Close()
+	if err = json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, fmt.Sprintf("incorrect order: %s", err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	if err = reorder(r.Context(), id, order); err != nil {
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	go s.sender.SendEvent(r.Context(), pathEvent(r, profileID, object, id, sender.UpdateObj))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// @Summary Get pending categories
+// @Tags admin
+// @Description get categories proposed by users which are not reviewed yet, admins and moderators only
+// @Produce json
+// @Param page query int false "page number"
+// @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
+// @Success 200 {object} models.CategoriesPage
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /admin/categories [get]
+func (s *Server) getPendingCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.policy.require(w, r, manageTaxonomy) {
+		return
+	}
+
+	s.getCategories(w, r, true)
+}
+
+// @Summary Approve category
+// @Tags admin
+// @Description approve the pending category, so it can be used by portfolios, admins and moderators only
+// @Param id path int true "category id"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /admin/categories/{id}/approve [post]
+func (s *Server) approveCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	s.review(w, r, true, s.databaseConnector.ReviewCategory, sender.CategoryEvent)
+}
+
+// @Summary Reject category
+// @Tags admin
+// @Description delete the pending category, admins and moderators only
+// @Param id path int true "category id"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /admin/categories/{id}/reject [post]
+func (s *Server) rejectCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	s.review(w, r, false, s.databaseConnector.ReviewCategory, sender.CategoryEvent)
+}
+
+// @Summary Get pending tags
+// @Tags admin
+// @Description get tags proposed by users which are not reviewed yet, admins and moderators only
+// @Produce json
+// @Param page query int false "page number"
+// @Param limit query int false "limit records by page"
+// @Param cursor query string false "next_cursor of the previous page, empty for the first page, can't be used with page"
+// @Success 200 {object} models.TagsPage
+// @Success 204
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /admin/tags [get]
+func (s *Server) getPendingTagsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.policy.require(w, r, manageTaxonomy) {
+		return
+	}
+
+	s.getTags(w, r, true)
+}
+
+// @Summary Approve tag
+// @Tags admin
+// @Description approve the pending tag, so it can be added to crafts, admins and moderators only
+// @Param id path int true "tag id"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /admin/tags/{id}/approve [post]
+func (s *Server) approveTagHandler(w http.ResponseWriter, r *http.Request) {
+	s.review(w, r, true, s.databaseConnector.ReviewTag, sender.TagEvent)
+}
+
+// @Summary Reject tag
+// @Tags admin
+// @Description delete the pending tag, admins and moderators only
+// @Param id path int true "tag id"
+// @Success 200
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500	{string} string
+// @Security BearerAuth
+// @Router /admin/tags/{id}/reject [post]
+func (s *Server) rejectTagHandler(w http.ResponseWriter, r *http.Request) {
+	s.review(w, r, false, s.databaseConnector.ReviewTag, sender.TagEvent)
+}
+
+// review approves or rejects the proposal, approved categories and tags are created for consumers of events
+func (s *Server) review(w http.ResponseWriter, r *http.Request, approve bool, reviewFunc func(ctx context.Context, id int, approve bool) error, event func(id int, change sender.Change) sender.Event) {
+	if !s.policy.require(w, r, manageTaxonomy) {
+		return
+	}
+
+	idStr, _ := bunrouter.ParamsFromContext(r.Context()).Get("id")
+	id, err := validation.ID(idStr)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = reviewFunc(r.Context(), id, approve); err != nil {
+		if errors.Is(err, response_errors.ErrNotFound) {
+			http.Error(w, "no such pending proposal", http.StatusNotFound)
+			return
+		}
+		response_errors.StatusCodeByErrorWriter(err, w, false)
+		return
+	}
+
+	if approve {
+		go s.sender.SendEvent(r.Context(), event(id, sender.CreateObj))
+	}
 
 	w.WriteHeader(http.StatusOK)
 }
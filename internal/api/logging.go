@@ -0,0 +1,101 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/logging"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength limits request ids of clients, longer or unprintable ones are replaced by new ids
+const maxRequestIDLength = 128
+
+// maxLoggedErrorSize limits the body of 5xx responses which is logged as the error
+const maxLoggedErrorSize = 512
+
+// logRequests gives every request an id, the id of the client from X-Request-ID is kept, and logs the request
+// when it is handled. The id is returned in X-Request-ID and is put to the context, so it gets to logs and events
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Int64("bytes", recorder.size),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+		}
+
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", strings.TrimSpace(string(recorder.body))))
+		}
+
+		s.logger.LogAttrs(ctx, level, "request handled", attrs...)
+	})
+}
+
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] <= ' ' || requestID[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statusRecorder keeps the status and the size of the response, the beginning of 5xx responses is kept as the error
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int64
+	wroteHeader bool
+	body        []byte
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	if !sr.wroteHeader {
+		sr.status, sr.wroteHeader = status, true
+	}
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(p []byte) (int, error) {
+	sr.wroteHeader = true
+	if sr.status >= http.StatusInternalServerError && len(sr.body) < maxLoggedErrorSize {
+		sr.body = append(sr.body, p[:min(len(p), maxLoggedErrorSize-len(sr.body))]...)
+	}
+
+	n, err := sr.ResponseWriter.Write(p)
+	sr.size += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the original writer
+func (sr *statusRecorder) Unwrap() http.ResponseWriter {
+	return sr.ResponseWriter
+}
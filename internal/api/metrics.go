@@ -0,0 +1,40 @@
+package api
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+)
+
+// publishedMetrics are names of expvar variables served by getMetricsHandler,
+// other variables like memstats and cmdline are not exposed
+var publishedMetrics = []string{"spool"}
+
+// @Summary Get metrics
+// @Tags metrics
+// @Description metrics of the service in the expvar format, only the spool of events is published, it requires the admin role
+// @Produce json
+// @Success 200 {object} map[string]any
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Security BearerAuth
+// @Router /debug/vars [get]
+func (s *Server) getMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.policy.require(w, r, viewMetrics) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprint(w, "{")
+	for i, name := range publishedMetrics {
+		value := "null"
+		if v := expvar.Get(name); v != nil {
+			value = v.String()
+		}
+		if i > 0 {
+			fmt.Fprint(w, ",")
+		}
+		fmt.Fprintf(w, "\n%q: %s", name, value)
+	}
+	fmt.Fprint(w, "\n}\n")
+}
@@ -1,20 +1,27 @@
 package api
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
 )
 
 type pageInfo struct {
 	number int
 	limit  int
 	offset int
+	after  *models.Cursor
 }
 
 const defaultLimit int = 30
 
+// getPageInfo reads page number mode (page, limit) or cursor mode (cursor, limit), cursor mode is chosen by presence of cursor param,
+// empty cursor means the first page
 func (s *Server) getPageInfo(r *http.Request) (*pageInfo, error) {
 	limitStr := r.FormValue("limit")
 	var limit int
@@ -32,6 +39,22 @@ func (s *Server) getPageInfo(r *http.Request) (*pageInfo, error) {
 		return nil, errors.New("limit must be greater than 0")
 	}
 
+	if r.Form.Has("cursor") {
+		if r.Form.Has("page") {
+			return nil, errors.New("page and cursor can't be used together")
+		}
+
+		after, err := decodeCursor(r.Form.Get("cursor"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &pageInfo{
+			limit: limit,
+			after: after,
+		}, nil
+	}
+
 	pageNoStr := r.FormValue("page")
 	var page int
 	switch pageNoStr {
@@ -54,3 +77,39 @@ func (s *Server) getPageInfo(r *http.Request) (*pageInfo, error) {
 		offset: (page - 1) * limit,
 	}, nil
 }
+
+func (p *pageInfo) model() models.Page {
+	return models.Page{Limit: p.limit, Offset: p.offset, After: p.after}
+}
+
+// nextCursor returns cursor of the last item when the page is full, so there may be more items after it
+func nextCursor[T interface{ Cursor() models.Cursor }](page *pageInfo, items []T) string {
+	if len(items) == 0 || len(items) < page.limit {
+		return ""
+	}
+
+	return encodeCursor(items[len(items)-1].Cursor())
+}
+
+func encodeCursor(cursor models.Cursor) string {
+	cursorJSON, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+func decodeCursor(cursorStr string) (*models.Cursor, error) {
+	var cursor models.Cursor
+	if cursorStr == "" {
+		return &cursor, nil
+	}
+
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(cursorStr)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect cursor: %w", err)
+	}
+
+	if err = json.Unmarshal(cursorJSON, &cursor); err != nil {
+		return nil, fmt.Errorf("incorrect cursor: %w", err)
+	}
+
+	return &cursor, nil
+}
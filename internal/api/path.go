@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/validation"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// checkPath answers 404 if the portfolio, craft or content of the request doesn't belong to the profile, portfolio
+// or craft of its path, so handlers which read only the id of the object can't reach objects of other profiles
+func (s *Server) checkPath(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+	return func(w http.ResponseWriter, req bunrouter.Request) error {
+		params := req.Params()
+
+		var path models.Path
+		for _, param := range []struct {
+			name string
+			id   *int
+		}{
+			{"profileID", &path.ProfileID},
+			{"id", &path.PortfolioID},
+			{"craftID", &path.CraftID},
+			{"contentID", &path.ContentID},
+		} {
+			idStr, ok := params.Get(param.name)
+			if !ok {
+				continue
+			}
+
+			id, err := validation.ID(idStr)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return nil
+			}
+			*param.id = id
+		}
+
+		if err := s.databaseConnector.CheckPath(req.Context(), path); err != nil {
+			response_errors.StatusCodeByErrorWriter(err, w, false)
+			return nil
+		}
+
+		return next(w, req)
+	}
+}
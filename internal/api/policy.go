@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/auth"
+)
+
+type permission int
+
+const (
+	// proposeTaxonomy lets to propose categories and tags, proposals are pending until they are reviewed
+	proposeTaxonomy permission = iota
+	// manageTaxonomy lets to create categories and tags and to approve or reject proposals
+	manageTaxonomy
+	// deleteTaxonomy lets to delete categories and tags which may be used by portfolios of everyone
+	deleteTaxonomy
+	// viewMetrics lets to read metrics of the service
+	viewMetrics
+)
+
+var rolePermissions = map[string][]permission{
+	auth.RoleUser:      {proposeTaxonomy},
+	auth.RoleModerator: {proposeTaxonomy, manageTaxonomy},
+	auth.RoleAdmin:     {proposeTaxonomy, manageTaxonomy, deleteTaxonomy, viewMetrics},
+}
+
+// policy decides by roles of the caller what it is allowed to do,
+// without authentication there are no roles and everything is allowed to everyone
+type policy struct {
+	enabled bool
+}
+
+func (p policy) allows(ctx context.Context, perm permission) bool {
+	if !p.enabled {
+		return true
+	}
+
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	if slices.Contains(rolePermissions[auth.RoleUser], perm) {
+		return true
+	}
+	for _, role := range identity.Roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// require writes 401 for requests without tokens and 403 for callers without the permission
+func (p policy) require(w http.ResponseWriter, r *http.Request, perm permission) bool {
+	if p.allows(r.Context(), perm) {
+		return true
+	}
+
+	if _, ok := auth.FromContext(r.Context()); !ok {
+		unauthorized(w, errNoToken)
+		return false
+	}
+
+	http.Error(w, "the role of the caller doesn't allow this action", http.StatusForbidden)
+	return false
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/auth"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/ratelimit"
+)
+
+// budgets of requests, each of them has its own bucket for every client
+const (
+	readBudget   = "read"
+	writeBudget  = "write"
+	uploadBudget = "upload"
+	// ipBudget limits all requests of the IP before authentication, so requests with invalid tokens are limited too
+	ipBudget = "ip"
+)
+
+// uploadRoutes send data of contents, they are limited by the upload budget instead of the write one
+var uploadRoutes = map[string]bool{
+	http.MethodPost + " /profiles/:profileID/portfolios/:id/crafts/:craftID/contents":             true,
+	http.MethodPatch + " /profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID": true,
+}
+
+// rateLimiter limits requests of clients by token buckets, authenticated clients are limited by their profiles,
+// others by their IPs. All requests are limited by their IPs before authentication too
+type rateLimiter struct {
+	store          ratelimit.Store
+	limits         map[string]ratelimit.Limit
+	trustedProxies []*net.IPNet
+	logger         *slog.Logger
+}
+
+func newRateLimiter(cfg config.RateLimit, store ratelimit.Store, logger *slog.Logger) (*rateLimiter, error) {
+	limiter := &rateLimiter{store: store, limits: make(map[string]ratelimit.Limit), logger: logger}
+
+	budgets := []struct {
+		name   string
+		limit  int
+		window time.Duration
+	}{
+		{readBudget, cfg.ReadLimit, cfg.ReadWindow},
+		{writeBudget, cfg.WriteLimit, cfg.WriteWindow},
+		{uploadBudget, cfg.UploadLimit, cfg.UploadWindow},
+		{ipBudget, cfg.IPLimit, cfg.IPWindow},
+	}
+	for _, budget := range budgets {
+		if budget.limit <= 0 {
+			continue
+		}
+		if budget.window <= 0 {
+			return nil, fmt.Errorf("failed to create rate limiter: window of %s budget must be positive", budget.name)
+		}
+		limiter.limits[budget.name] = ratelimit.Limit{Burst: budget.limit, Window: budget.window}
+	}
+
+	for _, proxy := range cfg.TrustedProxies {
+		network, err := parseNetwork(strings.TrimSpace(proxy))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create rate limiter: incorrect trusted proxy %q: %w", proxy, err)
+		}
+		limiter.trustedProxies = append(limiter.trustedProxies, network)
+	}
+
+	return limiter, nil
+}
+
+// ipMiddleware must precede the authenticator, so requests rejected by it are limited as well
+func (l *rateLimiter) ipMiddleware(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+	return func(w http.ResponseWriter, req bunrouter.Request) error {
+		if !l.allow(w, req, ipBudget, l.ipKey(req)) {
+			return nil
+		}
+
+		return next(w, req)
+	}
+}
+
+// middleware must follow the authenticator, so identities of callers are known
+func (l *rateLimiter) middleware(next bunrouter.HandlerFunc) bunrouter.HandlerFunc {
+	return func(w http.ResponseWriter, req bunrouter.Request) error {
+		if !l.allow(w, req, requestBudget(req), l.client(req)) {
+			return nil
+		}
+
+		return next(w, req)
+	}
+}
+
+// allow takes a token of the budget from the bucket of the client and sets RateLimit headers,
+// the request is rejected with 429 if the bucket is empty
+func (l *rateLimiter) allow(w http.ResponseWriter, req bunrouter.Request, budget, client string) bool {
+	limit, ok := l.limits[budget]
+	if !ok {
+		return true
+	}
+
+	result, err := l.store.Take(req.Context(), budget+":"+client, limit)
+	if err != nil {
+		// the service stays available if the shared store is not
+		l.logger.ErrorContext(req.Context(), "failed to limit rate", slog.Any("error", err))
+		return true
+	}
+
+	header := w.Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", seconds(result.Reset))
+	header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Burst, seconds(limit.Window)))
+
+	if !result.Allowed {
+		header.Set("Retry-After", seconds(result.RetryAfter))
+		http.Error(w, fmt.Sprintf("too many %s requests", budget), http.StatusTooManyRequests)
+		return false
+	}
+
+	return true
+}
+
+func requestBudget(req bunrouter.Request) string {
+	switch {
+	case uploadRoutes[req.Method+" "+req.Route()]:
+		return uploadBudget
+	case isWrite(req.Method):
+		return writeBudget
+	default:
+		return readBudget
+	}
+}
+
+// client is the key of the caller: the profile of the token or the IP, IPv6 clients are limited by /64 networks,
+// because one client usually has the whole network
+func (l *rateLimiter) client(req bunrouter.Request) string {
+	if identity, ok := auth.FromContext(req.Context()); ok {
+		return "profile:" + strconv.Itoa(identity.ProfileID)
+	}
+
+	return l.ipKey(req)
+}
+
+func (l *rateLimiter) ipKey(req bunrouter.Request) string {
+	ip := l.clientIP(req.Request)
+	if ip == nil {
+		return "ip:" + req.RemoteAddr
+	}
+	if ip.To4() == nil {
+		return "ip:" + ip.Mask(net.CIDRMask(64, 128)).String() + "/64"
+	}
+	return "ip:" + ip.String()
+}
+
+// clientIP is the remote address, behind trusted proxies it is the last address of X-Forwarded-For
+// which is not a trusted proxy, because clients can send their own X-Forwarded-For
+func (l *rateLimiter) clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !l.isTrusted(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if forwardedIP == nil {
+			break
+		}
+		ip = forwardedIP
+		if !l.isTrusted(ip) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (l *rateLimiter) isTrusted(ip net.IP) bool {
+	for _, network := range l.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseNetwork accepts a CIDR or a single IP
+func parseNetwork(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		return network, err
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, fmt.Errorf("not an IP or CIDR")
+	}
+	bits := 8 * len(ip.To16())
+	if ip.To4() != nil {
+		ip, bits = ip.To4(), 32
+	}
+
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
+
+// seconds rounds the duration up, so clients don't retry too early
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
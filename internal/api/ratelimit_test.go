@@ -0,0 +1,94 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/uptrace/bunrouter"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/ratelimit/memory"
+)
+
+func TestRateLimiterIPKey(t *testing.T) {
+	cfg := config.RateLimit{TrustedProxies: []string{"10.0.0.0/8", " 2001:db8::1 "}}
+	limiter, err := newRateLimiter(cfg, memory.NewStore(0), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "untrusted remote ignores forwarded", remoteAddr: "1.2.3.4:5000", forwarded: []string{"5.6.7.8"}, want: "ip:1.2.3.4"},
+		{name: "remote without port", remoteAddr: "1.2.3.4", want: "ip:1.2.3.4"},
+		{name: "unparsable remote", remoteAddr: "pipe", want: "ip:pipe"},
+		{name: "trusted remote without forwarded", remoteAddr: "10.0.0.1:5000", want: "ip:10.0.0.1"},
+		{name: "last forwarded address", remoteAddr: "10.0.0.1:5000", forwarded: []string{"9.9.9.9, 5.6.7.8"}, want: "ip:5.6.7.8"},
+		{name: "trusted hops are skipped", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8, 10.0.0.2, 10.0.0.3"}, want: "ip:5.6.7.8"},
+		{name: "untrusted hop stops the chain", remoteAddr: "10.0.0.1:5000", forwarded: []string{"1.1.1.1, 5.6.7.8, 10.0.0.2"}, want: "ip:5.6.7.8"},
+		{name: "several headers", remoteAddr: "10.0.0.1:5000", forwarded: []string{"1.1.1.1", "5.6.7.8, 10.0.0.2"}, want: "ip:5.6.7.8"},
+		{name: "unparsable entry stops the chain", remoteAddr: "10.0.0.1:5000", forwarded: []string{"5.6.7.8, unknown, 10.0.0.2"}, want: "ip:10.0.0.2"},
+		{name: "only trusted hops", remoteAddr: "10.0.0.1:5000", forwarded: []string{"10.0.0.2"}, want: "ip:10.0.0.2"},
+		{name: "ipv6 remote is keyed by /64", remoteAddr: "[2001:db8:1:2:3:4:5:6]:5000", want: "ip:2001:db8:1:2::/64"},
+		{name: "ipv6 forwarded by trusted ipv6 proxy", remoteAddr: "[2001:db8::1]:5000", forwarded: []string{"2001:db8:aaaa:bbbb::7"}, want: "ip:2001:db8:aaaa:bbbb::/64"},
+		{name: "ipv6 proxy outside of trusted address", remoteAddr: "[2001:db8::2]:5000", forwarded: []string{"5.6.7.8"}, want: "ip:2001:db8::/64"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tags", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			if got := limiter.ipKey(bunrouter.NewRequest(req)); got != tt.want {
+				t.Errorf("got key %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNetwork(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "10.0.0.0/8", want: "10.0.0.0/8"},
+		{value: "10.1.2.3/8", want: "10.0.0.0/8"},
+		{value: "1.2.3.4", want: "1.2.3.4/32"},
+		{value: "::ffff:1.2.3.4", want: "1.2.3.4/32"},
+		{value: "2001:db8::1", want: "2001:db8::1/128"},
+		{value: "2001:db8::/32", want: "2001:db8::/32"},
+		{value: "10.0.0.0/33", wantErr: true},
+		{value: "proxy", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			network, err := parseNetwork(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got network %v, want error", network)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if network.String() != tt.want {
+				t.Errorf("got network %s, want %s", network, tt.want)
+			}
+		})
+	}
+}
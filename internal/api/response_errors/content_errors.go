@@ -0,0 +1,65 @@
+package response_errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Codes of ContentError
+const (
+	CodeContentTooLarge      = "content_too_large"
+	CodeCraftTooLarge        = "craft_too_large"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+)
+
+// ContentError tells why the content is rejected, it is written as JSON, so clients can show the reason to users
+type ContentError struct {
+	Status  int    `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Limit is the exceeded limit in bytes
+	Limit    int64    `json:"limit,omitempty"`
+	MIMEType string   `json:"mime_type,omitempty"`
+	Allowed  []string `json:"allowed,omitempty"`
+}
+
+func (ce *ContentError) Error() string {
+	return ce.Message
+}
+
+func NewContentTooLargeError(limit int64) *ContentError {
+	return &ContentError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    CodeContentTooLarge,
+		Message: fmt.Sprintf("content is larger than %d bytes", limit),
+		Limit:   limit,
+	}
+}
+
+// NewCraftTooLargeError is returned when the content doesn't fit into the size left for contents of the craft
+func NewCraftTooLargeError(limit int64) *ContentError {
+	return &ContentError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    CodeCraftTooLarge,
+		Message: fmt.Sprintf("contents of the craft are larger than %d bytes", limit),
+		Limit:   limit,
+	}
+}
+
+func NewUnsupportedMediaTypeError(mimeType string, allowed []string) *ContentError {
+	return &ContentError{
+		Status:   http.StatusUnsupportedMediaType,
+		Code:     CodeUnsupportedMediaType,
+		Message:  fmt.Sprintf("content type %s is not allowed, allowed types: %s", mimeType, strings.Join(allowed, ", ")),
+		MIMEType: mimeType,
+		Allowed:  allowed,
+	}
+}
+
+func (ce *ContentError) write(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(ce.Status)
+	_ = json.NewEncoder(w).Encode(ce)
+}
@@ -10,9 +10,23 @@ import (
 var ErrMissingID = errors.New("missing id: id is required")
 var ErrIncorrectID = errors.New("incorrect id: must be greater than 0")
 var ErrIncorrectPortfoliosFilterType = errors.New("incorrect filter: must be ByProfileID, ByCategoryID or empty")
+var ErrNotFound = errors.New("no rows in result set")
+var ErrPathNotFound = errors.New("not found: the object doesn't belong to the profile, portfolio or craft of the path")
+var ErrNotApproved = errors.New("category or tag is not approved yet")
+var ErrNotImplemented = errors.New("not implemented for the selected storage")
+var ErrIncorrectOrder = errors.New("incorrect order: must contain ids of all crafts of the portfolio or all contents of the craft exactly once")
 
 func StatusCodeByErrorWriter(err error, w http.ResponseWriter, isNotFoundOk bool) {
-	if errors.Is(err, pgx.ErrNoRows) {
+	var contentErr *ContentError
+	if errors.As(err, &contentErr) {
+		contentErr.write(w)
+		return
+	}
+	if errors.Is(err, ErrPathNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) || errors.Is(err, ErrNotFound) {
 		if !isNotFoundOk {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
@@ -20,6 +34,14 @@ func StatusCodeByErrorWriter(err error, w http.ResponseWriter, isNotFoundOk bool
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
+	if errors.Is(err, ErrIncorrectOrder) || errors.Is(err, ErrNotApproved) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, ErrNotImplemented) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
 	http.Error(w, err.Error(), http.StatusInternalServerError)
 
 }
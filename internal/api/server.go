@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -11,106 +13,204 @@ import (
 
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/ratelimit"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
-	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/storage/postgresql"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/thumbnail"
 )
 
 type Connector interface {
-	GetAllPortfolios(ctx context.Context, limit int, offset int, id int, filterType postgresql.PortfoliosFilterType) ([]models.Portfolio, int, error)
+	GetAllPortfolios(ctx context.Context, page models.Page, id int, filterType models.PortfoliosFilterType) ([]models.Portfolio, int, error)
 	GetPortfolioByID(ctx context.Context, portfolioID int) (*models.Portfolio, error)
 	CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (int, error)
-	PatchPortfolio(ctx context.Context, portfolio models.Portfolio) error
+	PatchPortfolio(ctx context.Context, authorID int, portfolio models.Portfolio) error
 	DeletePortfolio(ctx context.Context, portfolioID int) error
 	CreateCategory(ctx context.Context, name string) (int, error)
+	ProposeCategory(ctx context.Context, name string, profileID int) (int, error)
+	ReviewCategory(ctx context.Context, id int, approve bool) error
 	DeleteCategory(ctx context.Context, id int) error
-	GetAllCategories(ctx context.Context, limit int, offset int) ([]models.Category, int, error)
-	GetAllCraftsByPortfolioID(ctx context.Context, portfolioID int, limit int, offset int) ([]models.Craft, int, error)
+	GetAllCategories(ctx context.Context, page models.Page, pending bool) ([]models.Category, int, error)
+	GetAllCraftsByPortfolioID(ctx context.Context, portfolioID int, page models.Page) ([]models.Craft, int, error)
 	GetCraftByID(ctx context.Context, craftID int) (*models.Craft, error)
 	CreateCraft(ctx context.Context, portfolioID int, craft models.Craft) (int, error)
 	AddTagToCraft(ctx context.Context, craftID int, tagID int) error
 	DeleteTagFromCraft(ctx context.Context, craftID int, tagID int) error
-	PatchCraft(ctx context.Context, craft models.Craft) error
+	PatchCraft(ctx context.Context, authorID int, craft models.Craft) error
 	DeleteCraft(ctx context.Context, id int) error
-	GetAllCraftsByTagID(ctx context.Context, tagID int, limit int, offset int) ([]models.Craft, int, error)
-	GetAllTags(ctx context.Context, limit int, offset int) ([]models.Tag, int, error)
+	GetAllCraftsByTagID(ctx context.Context, tagID int, page models.Page) ([]models.Craft, int, error)
+	GetAllTags(ctx context.Context, page models.Page, pending bool) ([]models.Tag, int, error)
 	CreateTag(ctx context.Context, name string) (int, error)
+	ProposeTag(ctx context.Context, name string, profileID int) (int, error)
+	ReviewTag(ctx context.Context, id int, approve bool) error
 	DeleteTag(ctx context.Context, id int) error
 	CreateContent(ctx context.Context, craftID int, content models.Content) (int, error)
 	DeleteContent(ctx context.Context, id int) error
-	PatchContent(ctx context.Context, content models.Content) error
+	PatchContent(ctx context.Context, authorID int, content models.Content) error
+	CreateContentFromReader(ctx context.Context, craftID int, content models.Content, data io.Reader) (int, error)
+	GetContentData(ctx context.Context, contentID int) (*models.ContentData, error)
+	GetCraftContentsSize(ctx context.Context, craftID, exceptContentID int) (int64, error)
+	ReorderCrafts(ctx context.Context, portfolioID int, order models.Order) error
+	ReorderContents(ctx context.Context, craftID int, order models.Order) error
+	SaveThumbnails(ctx context.Context, contentID int, checksum string, thumbnails []models.Thumbnail) error
+	GetThumbnailData(ctx context.Context, contentID, size int) (*models.ContentData, error)
+	Search(ctx context.Context, query models.SearchQuery, page models.Page) ([]models.SearchResult, int, error)
+	GetTrash(ctx context.Context, profileID int) ([]models.TrashItem, error)
+	RestorePortfolio(ctx context.Context, profileID, portfolioID int) error
+	RestoreCraft(ctx context.Context, profileID, craftID int) error
+	RestoreContent(ctx context.Context, profileID, contentID int) error
+	GetRevisions(ctx context.Context, object string, id int, page models.Page) ([]models.Revision, int, error)
+	GetRevision(ctx context.Context, object string, id, revision int) (*models.Revision, error)
+	Revert(ctx context.Context, authorID int, object string, id, revision int) error
+	CheckPath(ctx context.Context, path models.Path) error
 }
 
 type Server struct {
 	databaseConnector Connector
 	sender            Sender
+	contentPolicy     *contentPolicy
+	policy            policy
+	thumbnails        *thumbnail.Maker
+	transferTimeout   time.Duration
+	logger            *slog.Logger
 	httpServer        *http.Server
 }
 
+// Sender sends events in the background, the context gives the request id of the event
 type Sender interface {
-	SendEvent(userID int, obj sender.Object, objID int, change sender.Change)
+	SendEvent(ctx context.Context, event sender.Event)
 }
 
-func NewServer(cfg config.Server, connector Connector, notifier Sender) *Server {
+// NewServer creates the server, limits is the store of rate limits, it is used only if rate limiting is enabled
+func NewServer(cfg config.Server, connector Connector, notifier Sender, limits ratelimit.Store, logger *slog.Logger) (*Server, error) {
 	s := &Server{
 		databaseConnector: connector,
 		sender:            notifier,
+		contentPolicy:     newContentPolicy(cfg.Content),
+		policy:            policy{enabled: cfg.Auth.Enabled},
+		thumbnails:        thumbnail.NewMaker(cfg.Thumbnail, connector, logger),
+		transferTimeout:   cfg.TransferTimeout,
+		logger:            logger,
 	}
 
-	router := bunrouter.New().Compat()
+	var limiter *rateLimiter
+	if cfg.RateLimit.Enabled {
+		var err error
+		if limiter, err = newRateLimiter(cfg.RateLimit, limits, logger); err != nil {
+			return nil, fmt.Errorf("failed to create server: %w", err)
+		}
+	}
+
+	// requests are limited by IPs before authentication and by profiles after it
+	var options []bunrouter.Option
+	if limiter != nil {
+		options = append(options, bunrouter.Use(limiter.ipMiddleware))
+	}
+	if cfg.Auth.Enabled {
+		authenticator, err := newAuthenticator(cfg.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create server: %w", err)
+		}
+		options = append(options, bunrouter.Use(authenticator.middleware))
+	}
+	if limiter != nil {
+		options = append(options, bunrouter.Use(limiter.middleware))
+	}
+
+	router := bunrouter.New(options...).Compat()
+	// routes of objects of portfolios, the path of the object is checked before handlers
+	owned := router.WithMiddleware(s.checkPath)
+
 	router.GET("/profiles/:profileID/portfolios", s.getPortfoliosHandler)
-	router.GET("/profiles/:profileID/portfolios/:id", s.getPortfolioByIDHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id", s.getPortfolioByIDHandler)
 	router.POST("/profiles/:profileID/portfolios", s.postPortfolioHandler)
-	router.PATCH("/profiles/:profileID/portfolios/:id", s.patchPortfolioHandler)
-	router.DELETE("/profiles/:profileID/portfolios/:id", s.deletePortfolioHandler)
+	owned.PATCH("/profiles/:profileID/portfolios/:id", s.patchPortfolioHandler)
+	owned.DELETE("/profiles/:profileID/portfolios/:id", s.deletePortfolioHandler)
 
 	router.POST("/categories", s.postCategoryHandler)
 	router.DELETE("/categories/:id", s.deleteCategoryHandler)
 	router.GET("/categories", s.getCategoriesHandler)
 
-	router.GET("/profiles/:profileID/portfolios/:id/crafts", s.getCraftsByPortfolioIDHandler)
-	router.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID", s.getCraftHandler)
-	router.POST("/profiles/:profileID/portfolios/:id/crafts", s.postCraftHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts", s.getCraftsByPortfolioIDHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID", s.getCraftHandler)
+	owned.POST("/profiles/:profileID/portfolios/:id/crafts", s.postCraftHandler)
+	owned.PUT("/profiles/:profileID/portfolios/:id/crafts/order", s.reorderCraftsHandler)
 
-	router.POST("/profiles/:profileID/portfolios/:id/crafts/:craftID/tags/:tagID", s.postTagPatchCraftHandler)
-	router.DELETE("/profiles/:profileID/portfolios/:id/crafts/:craftID/tags/:tagID", s.deleteTagPatchCraftHandler)
+	owned.POST("/profiles/:profileID/portfolios/:id/crafts/:craftID/tags/:tagID", s.postTagPatchCraftHandler)
+	owned.DELETE("/profiles/:profileID/portfolios/:id/crafts/:craftID/tags/:tagID", s.deleteTagPatchCraftHandler)
 
-	router.PATCH("/profiles/:profileID/portfolios/:id/crafts/:craftID", s.patchCraftHandler)
-	router.DELETE("/profiles/:profileID/portfolios/:id/crafts/:craftID", s.deleteCraftHandler)
+	owned.PATCH("/profiles/:profileID/portfolios/:id/crafts/:craftID", s.patchCraftHandler)
+	owned.DELETE("/profiles/:profileID/portfolios/:id/crafts/:craftID", s.deleteCraftHandler)
 
 	router.GET("/tags/:id/crafts", s.getCraftsByTagIDHandler)
 	router.GET("/tags", s.getTagsHandler)
 	router.POST("/tags", s.postTagHandler)
 	router.DELETE("/tags/:id", s.deleteTagHandler)
 
-	router.POST("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents", s.postContentHandler)
-	router.DELETE("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID", s.deleteContentHandler)
-	router.PATCH("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID", s.patchContentHandler)
+	owned.POST("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents", s.postContentHandler)
+	owned.DELETE("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID", s.deleteContentHandler)
+	owned.PATCH("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID", s.patchContentHandler)
+	owned.PUT("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/order", s.reorderContentsHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID/data", s.getContentDataHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID/thumbnails/:size", s.getContentThumbnailHandler)
+
+	owned.GET("/profiles/:profileID/portfolios/:id/revisions", s.getPortfolioRevisionsHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/revisions/:rev", s.getPortfolioRevisionHandler)
+	owned.POST("/profiles/:profileID/portfolios/:id/revisions/:rev/revert", s.revertPortfolioHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID/revisions", s.getCraftRevisionsHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID/revisions/:rev", s.getCraftRevisionHandler)
+	owned.POST("/profiles/:profileID/portfolios/:id/crafts/:craftID/revisions/:rev/revert", s.revertCraftHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID/revisions", s.getContentRevisionsHandler)
+	owned.GET("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID/revisions/:rev", s.getContentRevisionHandler)
+	owned.POST("/profiles/:profileID/portfolios/:id/crafts/:craftID/contents/:contentID/revisions/:rev/revert", s.revertContentHandler)
+
+	router.GET("/profiles/:profileID/trash", s.getTrashHandler)
+	router.POST("/profiles/:profileID/trash/portfolios/:id/restore", s.restorePortfolioHandler)
+	router.POST("/profiles/:profileID/trash/crafts/:id/restore", s.restoreCraftHandler)
+	router.POST("/profiles/:profileID/trash/contents/:id/restore", s.restoreContentHandler)
+
+	router.GET("/admin/categories", s.getPendingCategoriesHandler)
+	router.POST("/admin/categories/:id/approve", s.approveCategoryHandler)
+	router.POST("/admin/categories/:id/reject", s.rejectCategoryHandler)
+	router.GET("/admin/tags", s.getPendingTagsHandler)
+	router.POST("/admin/tags/:id/approve", s.approveTagHandler)
+	router.POST("/admin/tags/:id/reject", s.rejectTagHandler)
+
+	router.GET("/search", s.getSearchHandler)
+
+	router.GET("/debug/vars", s.getMetricsHandler)
 
 	swagHandler := httpSwagger.Handler(httpSwagger.URL("/swagger/doc.json"))
 	router.GET("/swagger/*path", swagHandler)
 
 	s.httpServer = &http.Server{
 		Addr:         cfg.Listen,
-		Handler:      router,
+		Handler:      s.logRequests(router),
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
 		IdleTimeout:  cfg.IdleTimeout,
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
 	}
 
-	return s
+	return s, nil
 }
 
 func (s *Server) Run() {
-	log.Println("server started") // TODO: логгер
+	s.thumbnails.Run()
+
+	s.logger.Info("server started", slog.String("listen", s.httpServer.Addr))
 
 	go func() {
 		err := s.httpServer.ListenAndServe()
-		log.Printf("http server stopped: %s", err.Error()) // TODO: логгер
+		s.logger.Info("http server stopped", slog.Any("reason", err))
 	}()
 }
 
 func (s *Server) Shutdown() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+
+	// thumbnails of contents queued by handlers are made before the database is closed
+	s.thumbnails.Shutdown()
+
+	return err
 }
@@ -2,33 +2,72 @@ package app
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob/filesystem"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob/s3"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/connector"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/logging"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/outbox"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/profiles"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/ratelimit"
+	ratelimitmemory "github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/ratelimit/memory"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender/amqp"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender/file"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender/kafka"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender/nats"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender/webhook"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/spool"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/storage/memory"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/storage/mongodb"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/storage/postgresql"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/trash"
 )
 
+// storageConnector is used by the server, by the trash purger and by the profile events consumer
+type storageConnector interface {
+	api.Connector
+	trash.Connector
+	profiles.Connector
+}
+
 type Application struct {
 	cfg           config.Application
-	db            *postgresql.DB
-	dbConnector   *connector.PostgresConnector
+	logger        *slog.Logger
+	postgres      *postgresql.DB
+	mongo         *mongodb.DB
+	memory        *memory.DB
+	dbConnector   storageConnector
 	senderManager *sender.Manager
-	sender        *kafka.ProducerManager
+	sender        sender.Transport
 	server        *api.Server
+	purger        *trash.Purger
+	relay         *outbox.Relay
+	consumer      *profiles.Consumer
 	closeCtx      context.Context
 	closeCtxFunc  context.CancelFunc
 }
 
 func NewApplication(cfg config.Application) (*Application, error) {
+	logger, err := logging.New(cfg.Log, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
 	app := Application{
-		cfg: cfg,
+		cfg:    cfg,
+		logger: logger,
 	}
 
 	if err := app.bootstrap(); err != nil {
@@ -54,7 +93,14 @@ func (a *Application) bootstrap() error {
 	a.initSenderManager()
 
 	//init controllers
-	a.initServer()
+	if err := a.initServer(); err != nil {
+		return err
+	}
+	a.initPurger()
+	a.initRelay()
+	if err := a.initConsumer(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -63,47 +109,217 @@ func (a *Application) initDatabase() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	db, err := postgresql.NewDB(ctx, a.cfg.Storage.Postgres)
-	if err != nil {
-		log.Println(err) // TODO: logger
-		return err
+	switch a.cfg.Storage.Database {
+	case config.PostgresDatabase:
+		blobs, err := newBlobStore(ctx, a.cfg.Storage.Blob)
+		if err != nil {
+			a.logger.Error("failed to create blob store", slog.Any("error", err))
+			return err
+		}
+
+		db, err := postgresql.NewDB(ctx, a.cfg.Storage.Postgres, blobs, a.logger)
+		if err != nil {
+			a.logger.Error("failed to connect to postgres", slog.Any("error", err))
+			return err
+		}
+		a.postgres = db
+
+		if a.cfg.Storage.Postgres.AutoMigrate {
+			applied, err := db.MigrateUp(ctx)
+			if err != nil {
+				a.logger.Error("failed to apply migrations", slog.Any("error", err))
+				return err
+			}
+			a.logger.Info("migrations are applied", slog.Int("count", applied))
+		}
+	case config.MongoDatabase:
+		db, err := mongodb.NewDB(ctx, a.cfg.Storage.Mongo, a.logger)
+		if err != nil {
+			a.logger.Error("failed to connect to mongo", slog.Any("error", err))
+			return err
+		}
+		a.mongo = db
+	case config.MemoryDatabase:
+		a.memory = memory.NewDB()
+		a.logger.Warn("using in-memory database, data will be lost on shutdown")
+		return nil
+	default:
+		return fmt.Errorf("unknown storage database %q: must be %s, %s or %s", a.cfg.Storage.Database, config.PostgresDatabase, config.MongoDatabase, config.MemoryDatabase)
 	}
 
-	a.db = db
-	log.Println("successful connection to database") // TODO: logger
+	a.logger.Info("successful connection to database", slog.String("database", a.cfg.Storage.Database))
 	return nil
 }
 
+// newBlobStore creates the store of contents data used by the postgres database
+func newBlobStore(ctx context.Context, cfg config.Blob) (blob.Store, error) {
+	switch cfg.Store {
+	case config.FilesystemBlobStore:
+		return filesystem.NewStore(cfg.Filesystem)
+	case config.S3BlobStore:
+		return s3.NewStore(ctx, cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q: must be %s or %s", cfg.Store, config.FilesystemBlobStore, config.S3BlobStore)
+	}
+}
+
 func (a *Application) initConnector() {
-	a.dbConnector = connector.NewPostgresConnector(a.db)
+	switch a.cfg.Storage.Database {
+	case config.PostgresDatabase:
+		a.dbConnector = connector.NewConnector(a.postgres)
+	case config.MongoDatabase:
+		a.dbConnector = connector.NewConnector(a.mongo)
+	case config.MemoryDatabase:
+		a.dbConnector = connector.NewConnector(a.memory)
+	}
 }
 
 func (a *Application) initSender() error {
-	send, err := kafka.NewProducerManager(a.cfg.Kafka)
+	encoder, err := sender.NewEncoder(a.cfg.Events)
 	if err != nil {
-		log.Println(err) // TODO: logger
+		a.logger.Error("failed to create events encoder", slog.Any("error", err))
 		return err
 	}
 
-	a.sender = send
+	if len(a.cfg.Events.Transports) == 0 {
+		err = errors.New("no events transports")
+		a.logger.Error("failed to create events sender", slog.Any("error", err))
+		return err
+	}
+
+	transports := make([]sender.Transport, 0, len(a.cfg.Events.Transports))
+	for _, name := range a.cfg.Events.Transports {
+		transport, err := a.newTransport(name, encoder)
+		if err != nil {
+			for _, created := range transports {
+				created.Shutdown()
+			}
+			a.logger.Error("failed to create events transport", slog.Any("error", err))
+			return err
+		}
+		transports = append(transports, transport)
+	}
+
+	transport := transports[0]
+	if len(transports) > 1 {
+		transport = sender.NewFanout(transports...)
+	}
+
+	if !a.cfg.Spool.Enabled {
+		a.sender = transport
+		return nil
+	}
+
+	spooled, err := spool.New(a.cfg.Spool, transport, a.logger)
+	if err != nil {
+		transport.Shutdown()
+		a.logger.Error("failed to open spool", slog.Any("error", err))
+		return err
+	}
+
+	a.sender = spooled
 	return nil
 }
 
+// newTransport creates the transport of events by its name in EVENTS_TRANSPORTS
+func (a *Application) newTransport(name string, encoder *sender.Encoder) (sender.Transport, error) {
+	switch strings.TrimSpace(name) {
+	case config.KafkaTransport:
+		return kafka.NewProducerManager(a.cfg.Kafka, encoder, a.logger)
+	case config.NATSTransport:
+		return nats.NewPublisher(a.cfg.Events.NATS, encoder)
+	case config.AMQPTransport:
+		return amqp.NewPublisher(a.cfg.Events.AMQP, encoder)
+	case config.WebhookTransport:
+		return webhook.NewPoster(a.cfg.Events.Webhook, encoder)
+	case config.FileTransport:
+		return file.NewSink(a.cfg.Events.File, encoder)
+	default:
+		return nil, fmt.Errorf("unknown events transport %q: must be %s, %s, %s, %s or %s", name,
+			config.KafkaTransport, config.NATSTransport, config.AMQPTransport, config.WebhookTransport, config.FileTransport)
+	}
+}
+
+// initSenderManager creates the manager used by handlers and the purger, postgres saves events to the outbox
+// in the same transaction as changes and the relay sends them, so with postgres the manager drops events
 func (a *Application) initSenderManager() {
-	a.senderManager = sender.NewManager(a.sender)
+	if a.postgres != nil {
+		a.senderManager = sender.NewManager(sender.Discard{}, a.logger)
+		return
+	}
+
+	a.senderManager = sender.NewManager(a.sender, a.logger)
 }
 
-func (a *Application) initServer() {
-	s := api.NewServer(a.cfg.Server, a.dbConnector, a.senderManager)
+func (a *Application) initServer() error {
+	var limits ratelimit.Store
+	if a.cfg.Server.RateLimit.Enabled {
+		store, err := newRateLimitStore(a.cfg.Server.RateLimit)
+		if err != nil {
+			a.logger.Error("failed to create rate limit store", slog.Any("error", err))
+			return err
+		}
+		limits = store
+	}
+
+	s, err := api.NewServer(a.cfg.Server, a.dbConnector, a.senderManager, limits, a.logger)
+	if err != nil {
+		return err
+	}
 
 	a.server = s
+	return nil
+}
+
+// newRateLimitStore creates the store of rate limits of clients, other stores, like a shared one,
+// are added here by their names in RATE_LIMIT_STORE
+func newRateLimitStore(cfg config.RateLimit) (ratelimit.Store, error) {
+	switch cfg.Store {
+	case config.MemoryRateLimitStore:
+		return ratelimitmemory.NewStore(cfg.SweepInterval), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q: must be %s", cfg.Store, config.MemoryRateLimitStore)
+	}
+}
+
+func (a *Application) initPurger() {
+	a.purger = trash.NewPurger(a.cfg.Trash, a.dbConnector, a.senderManager, a.logger)
+}
+
+func (a *Application) initRelay() {
+	if a.postgres != nil {
+		a.relay = outbox.NewRelay(a.cfg.Outbox, a.postgres, a.sender, a.logger)
+	}
+}
+
+// initConsumer creates the consumer of profile events if it is enabled
+func (a *Application) initConsumer() error {
+	if !a.cfg.ProfileEvents.Enabled {
+		return nil
+	}
+
+	consumer, err := profiles.NewConsumer(a.cfg.ProfileEvents, a.cfg.Kafka, a.dbConnector, a.senderManager, a.logger)
+	if err != nil {
+		a.logger.Error("failed to create profile events consumer", slog.Any("error", err))
+		return err
+	}
+
+	a.consumer = consumer
+	return nil
 }
 
 func (a *Application) Run() {
 	defer a.stop()
 
-	a.sender.Run(a.closeCtx)
+	a.sender.Run()
 	a.server.Run()
+	a.purger.Run(a.closeCtx)
+	if a.relay != nil {
+		a.relay.Run(a.closeCtx)
+	}
+	if a.consumer != nil {
+		a.consumer.Run(a.closeCtx)
+	}
 
 	<-a.closeCtx.Done()
 	a.closeCtxFunc()
@@ -111,17 +327,42 @@ func (a *Application) Run() {
 
 func (a *Application) stop() {
 	if err := a.server.Shutdown(); err != nil {
-		log.Printf("incorrect closing of server: %s", err.Error()) // TODO: logger
+		a.logger.Error("incorrect closing of server", slog.Any("error", err))
 	} else {
-		log.Print("server closed") // TODO: logger
+		a.logger.Info("server closed")
+	}
+
+	if a.consumer != nil {
+		a.consumer.Shutdown()
 	}
 
-	if err := a.sender.Shutdown(); err != nil {
-		log.Print(err) // TODO: logger
+	a.purger.Shutdown()
+
+	if a.relay != nil {
+		a.relay.Shutdown()
+	}
+
+	a.sender.Shutdown()
+
+	a.closeDatabase()
+}
+
+func (a *Application) closeDatabase() {
+	if a.postgres != nil {
+		a.postgres.Close()
+	}
+
+	if a.mongo != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := a.mongo.Close(ctx); err != nil {
+			a.logger.Error("incorrect closing of database", slog.Any("error", err))
+			return
+		}
 	}
 
-	a.db.Close()
-	log.Print("database closed") // TODO: logger
+	a.logger.Info("database closed")
 }
 
 func (a *Application) readyToShutdown() {
@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+var (
+	errNoKeys       = errors.New("AUTH_HS256_SECRET or AUTH_JWKS_FILE is required")
+	errUnknownKey   = errors.New("unknown signing key")
+	errIncorrectSub = errors.New("subject must be a profile id")
+)
+
+// Roles of callers, every authenticated caller is a user, even if the token has no roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Identity is the authenticated caller, its profile is the subject of the token
+type Identity struct {
+	ProfileID int
+	Roles     []string
+}
+
+func (i Identity) HasRole(role string) bool {
+	for _, r := range i.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+type identityKey struct{}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity of the caller, ok is false for requests without tokens
+func FromContext(ctx context.Context) (identity Identity, ok bool) {
+	identity, ok = ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Verifier checks signatures and claims of tokens, exp is required
+type Verifier struct {
+	secret     []byte
+	keys       []publicKey
+	parser     *jwt.Parser
+	rolesClaim string
+}
+
+func NewVerifier(cfg config.Auth) (*Verifier, error) {
+	v := &Verifier{rolesClaim: cfg.RolesClaim}
+
+	var methods []string
+	if cfg.HS256Secret != "" {
+		v.secret = []byte(cfg.HS256Secret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create token verifier: %w", err)
+		}
+		v.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("failed to create token verifier: %w", errNoKeys)
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithLeeway(cfg.Leeway), jwt.WithExpirationRequired(), jwt.WithIssuedAt()}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(options...)
+
+	return v, nil
+}
+
+// Verify returns the identity of the valid token
+func (v *Verifier) Verify(token string) (Identity, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.key); err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+	profileID, err := strconv.Atoi(subject)
+	if err != nil || profileID <= 0 {
+		return Identity{}, fmt.Errorf("invalid token: %w", errIncorrectSub)
+	}
+
+	return Identity{ProfileID: profileID, Roles: roles(claims[v.rolesClaim])}, nil
+}
+
+// key returns the key of the signing method, keys of the JWKS file are found by kid, a token without kid
+// is checked by the only key of its type
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	if token.Method == jwt.SigningMethodHS256 {
+		return v.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	var found any
+	for _, k := range v.keys {
+		switch k.key.(type) {
+		case *rsa.PublicKey:
+			if token.Method != jwt.SigningMethodRS256 {
+				continue
+			}
+		case *ecdsa.PublicKey:
+			if token.Method != jwt.SigningMethodES256 {
+				continue
+			}
+		}
+
+		if kid != "" && k.id == kid {
+			return k.key, nil
+		}
+		if kid == "" {
+			if found != nil {
+				return nil, errUnknownKey
+			}
+			found = k.key
+		}
+	}
+
+	if found == nil {
+		return nil, errUnknownKey
+	}
+
+	return found, nil
+}
+
+// roles reads the claim which is a list of strings or a space separated string
+func roles(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		var result []string
+		for _, role := range value {
+			if role, ok := role.(string); ok {
+				result = append(result, role)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
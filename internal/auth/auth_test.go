@@ -0,0 +1,313 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+const testSecret = "secret"
+
+type testKeys struct {
+	rsa1, rsa2 *rsa.PrivateKey
+	ec         *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+
+	var keys testKeys
+	var err error
+	if keys.rsa1, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		t.Fatal(err)
+	}
+	if keys.rsa2, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		t.Fatal(err)
+	}
+	if keys.ec, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+// writeJWKS writes public keys to the JWKS file: rsa-1, rsa-2 and ec-1
+func writeJWKS(t *testing.T, keys testKeys) string {
+	t.Helper()
+
+	encode := func(n *big.Int) string { return base64.RawURLEncoding.EncodeToString(n.Bytes()) }
+	set := map[string][]jwk{"keys": {
+		{Kty: "RSA", Kid: "rsa-1", Use: "sig", N: encode(keys.rsa1.N), E: encode(big.NewInt(int64(keys.rsa1.E)))},
+		{Kty: "RSA", Kid: "rsa-2", Use: "sig", N: encode(keys.rsa2.N), E: encode(big.NewInt(int64(keys.rsa2.E)))},
+		{Kty: "EC", Kid: "ec-1", Crv: "P-256", X: encode(keys.ec.X), Y: encode(keys.ec.Y)},
+	}}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func validClaims(sub any) jwt.MapClaims {
+	return jwt.MapClaims{"sub": sub, "exp": time.Now().Add(time.Hour).Unix(), "roles": []string{RoleModerator}}
+}
+
+func TestVerifierVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	jwksFile := writeJWKS(t, keys)
+
+	hs256Only := config.Auth{HS256Secret: testSecret, RolesClaim: "roles"}
+	jwksOnly := config.Auth{JWKSFile: jwksFile, RolesClaim: "roles"}
+	both := config.Auth{HS256Secret: testSecret, JWKSFile: jwksFile, RolesClaim: "roles"}
+
+	// the public key as the HMAC secret, the verifier must not take the RSA key as a secret of HS256
+	rsaPublic, err := x509.MarshalPKIXPublicKey(keys.rsa1.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noExp := validClaims("1")
+	delete(noExp, "exp")
+
+	tests := []struct {
+		name    string
+		cfg     config.Auth
+		token   string
+		wantID  int
+		wantErr bool
+	}{
+		{
+			name:   "hs256",
+			cfg:    hs256Only,
+			token:  sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims("1")),
+			wantID: 1,
+		},
+		{
+			name:    "hs256 with wrong secret",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte("other"), validClaims("1")),
+			wantErr: true,
+		},
+		{
+			name:    "hs256 when only jwks is configured",
+			cfg:     jwksOnly,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims("1")),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 key used as hs256 secret",
+			cfg:     jwksOnly,
+			token:   sign(t, jwt.SigningMethodHS256, "rsa-1", rsaPublic, validClaims("1")),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 key used as hs256 secret when both are configured",
+			cfg:     both,
+			token:   sign(t, jwt.SigningMethodHS256, "rsa-1", rsaPublic, validClaims("1")),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 when only hs256 is configured",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa1, validClaims("1")),
+			wantErr: true,
+		},
+		{
+			name:    "missing exp",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), noExp),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), jwt.MapClaims{"sub": "1", "exp": time.Now().Add(-time.Hour).Unix()}),
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric sub",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims("profile")),
+			wantErr: true,
+		},
+		{
+			name:    "zero sub",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims("0")),
+			wantErr: true,
+		},
+		{
+			name:    "negative sub",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims("-1")),
+			wantErr: true,
+		},
+		{
+			name:    "numeric sub claim",
+			cfg:     hs256Only,
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte(testSecret), validClaims(1)),
+			wantErr: true,
+		},
+		{
+			name:   "rs256 with kid of the first key",
+			cfg:    jwksOnly,
+			token:  sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa1, validClaims("2")),
+			wantID: 2,
+		},
+		{
+			name:   "rs256 with kid of the second key",
+			cfg:    jwksOnly,
+			token:  sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa2, validClaims("3")),
+			wantID: 3,
+		},
+		{
+			name:    "rs256 with kid of another key",
+			cfg:     jwksOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa2, validClaims("2")),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 with unknown kid",
+			cfg:     jwksOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "rsa-3", keys.rsa1, validClaims("2")),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 with kid of ec key",
+			cfg:     jwksOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "ec-1", keys.rsa1, validClaims("2")),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 without kid when there are several rsa keys",
+			cfg:     jwksOnly,
+			token:   sign(t, jwt.SigningMethodRS256, "", keys.rsa1, validClaims("2")),
+			wantErr: true,
+		},
+		{
+			name:   "es256 without kid when there is the only ec key",
+			cfg:    jwksOnly,
+			token:  sign(t, jwt.SigningMethodES256, "", keys.ec, validClaims("4")),
+			wantID: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier, err := NewVerifier(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			identity, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got identity %+v, want error", identity)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			if identity.ProfileID != tt.wantID {
+				t.Errorf("got profile id %d, want %d", identity.ProfileID, tt.wantID)
+			}
+			if !slices.Equal(identity.Roles, []string{RoleModerator}) {
+				t.Errorf("got roles %v, want [%s]", identity.Roles, RoleModerator)
+			}
+		})
+	}
+}
+
+func TestVerifierKey(t *testing.T) {
+	keys := newTestKeys(t)
+
+	verifier, err := NewVerifier(config.Auth{HS256Secret: testSecret, JWKSFile: writeJWKS(t, keys)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		method  jwt.SigningMethod
+		kid     string
+		want    crypto.PublicKey
+		wantErr bool
+	}{
+		{name: "rs256 by kid", method: jwt.SigningMethodRS256, kid: "rsa-2", want: keys.rsa2.Public()},
+		{name: "es256 by kid", method: jwt.SigningMethodES256, kid: "ec-1", want: keys.ec.Public()},
+		{name: "es256 without kid", method: jwt.SigningMethodES256, want: keys.ec.Public()},
+		{name: "rs256 without kid", method: jwt.SigningMethodRS256, wantErr: true},
+		{name: "rs256 with kid of ec key", method: jwt.SigningMethodRS256, kid: "ec-1", wantErr: true},
+		{name: "unknown kid", method: jwt.SigningMethodES256, kid: "ec-2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := jwt.New(tt.method)
+			if tt.kid != "" {
+				token.Header["kid"] = tt.kid
+			}
+
+			key, err := verifier.key(token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got key %v, want error", key)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("got error %v", err)
+			}
+
+			equal, ok := key.(interface{ Equal(crypto.PublicKey) bool })
+			if !ok || !equal.Equal(tt.want) {
+				t.Errorf("got key %v, want %v", key, tt.want)
+			}
+		})
+	}
+
+	secret, err := verifier.key(jwt.New(jwt.SigningMethodHS256))
+	if err != nil || string(secret.([]byte)) != testSecret {
+		t.Errorf("got secret %v and error %v, want the configured secret", secret, err)
+	}
+}
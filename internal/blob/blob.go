@@ -0,0 +1,88 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps binary data of contents outside of the database, keys are slash-separated paths
+type Store interface {
+	// Put saves data under the key, size is -1 if it is unknown
+	Put(ctx context.Context, key string, data io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes data by the key, it is not an error if there is no such key
+	Delete(ctx context.Context, key string) error
+}
+
+// Info describes saved data
+type Info struct {
+	Key  string
+	Size int64
+	// Checksum is hex encoded SHA-256 of the data
+	Checksum string
+}
+
+// Upload saves data under a new unique key and counts its size and checksum
+func Upload(ctx context.Context, store Store, data io.Reader, size int64) (Info, error) {
+	key, err := newKey()
+	if err != nil {
+		return Info{}, err
+	}
+
+	reader := &countingReader{reader: data, hash: sha256.New()}
+	if err = store.Put(ctx, key, reader, size); err != nil {
+		return Info{}, fmt.Errorf("failed to upload blob: %w", err)
+	}
+
+	return Info{Key: key, Size: reader.size, Checksum: hex.EncodeToString(reader.hash.Sum(nil))}, nil
+}
+
+func newKey() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate blob key: %w", err)
+	}
+
+	return "contents/" + hex.EncodeToString(id), nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	size   int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.reader.Read(p)
+	cr.size += int64(n)
+	cr.hash.Write(p[:n])
+	return n, err
+}
+
+// Bytes returns data kept in memory as a blob
+func Bytes(data []byte) io.ReadSeekCloser {
+	return bytesBlob{Reader: bytes.NewReader(data)}
+}
+
+type bytesBlob struct {
+	*bytes.Reader
+}
+
+func (bytesBlob) Close() error {
+	return nil
+}
+
+// Checksum returns hex encoded SHA-256 of the data
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,100 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+// Store keeps blobs as files in the directory
+type Store struct {
+	dir string
+}
+
+func NewStore(cfg config.Filesystem) (*Store, error) {
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create blobs directory: %w", err)
+	}
+
+	return &Store{dir: cfg.Dir}, nil
+}
+
+func (s *Store) path(key string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("incorrect blob key %q", key)
+	}
+
+	return path, nil
+}
+
+// Put writes data to a temporary file first, so a partially written blob is never visible by its key
+func (s *Store) Put(ctx context.Context, key string, data io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err = os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to save blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to get blob %s: %w", key, blob.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+
+	return file, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+// Store keeps blobs as objects of the bucket in S3-compatible storage like AWS S3 or MinIO
+type Store struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewStore(ctx context.Context, cfg config.S3) (*Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check s3 bucket: %w", err)
+	}
+
+	if !exists {
+		if !cfg.CreateBucket {
+			return nil, fmt.Errorf("s3 bucket %s doesn't exist", cfg.Bucket)
+		}
+
+		if err = client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("failed to create s3 bucket: %w", err)
+		}
+	}
+
+	return &Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+func (s *Store) Put(ctx context.Context, key string, data io.Reader, size int64) error {
+	if _, err := s.client.PutObject(ctx, s.bucket, key, data, size, minio.PutObjectOptions{ContentType: "application/octet-stream"}); err != nil {
+		return fmt.Errorf("failed to put blob %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+
+	// GetObject doesn't send any request, so the missing key is found out by Stat
+	if _, err = object.Stat(); err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, fmt.Errorf("failed to get blob %s: %w", key, blob.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get blob %s: %w", key, err)
+	}
+
+	return object, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+
+	return nil
+}
@@ -1,7 +1,13 @@
 package config
 
 type Application struct {
-	Server  Server
-	Storage Storage
-	Kafka   Kafka
+	Log           Log
+	Server        Server
+	Storage       Storage
+	Kafka         Kafka
+	Events        Events
+	Spool         Spool
+	Trash         Trash
+	Outbox        Outbox
+	ProfileEvents ProfileEvents
 }
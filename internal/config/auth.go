@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// Auth configures authentication of the api by bearer JWTs, tokens are signed by HS256 with HS256Secret
+// or by RS256 and ES256 with keys from JWKSFile, at least one of them is required when auth is enabled
+type Auth struct {
+	Enabled     bool   `env:"AUTH_ENABLED" envDefault:"false"`
+	HS256Secret string `env:"AUTH_HS256_SECRET"`
+	JWKSFile    string `env:"AUTH_JWKS_FILE"`
+	// Issuer and Audience are checked if they are set
+	Issuer   string `env:"AUTH_ISSUER"`
+	Audience string `env:"AUTH_AUDIENCE"`
+	// Leeway is the allowed clock skew for exp, nbf and iat claims
+	Leeway time.Duration `env:"AUTH_LEEWAY" envDefault:"30s"`
+	// RolesClaim is the claim with roles of the caller, a list or a space separated string
+	RolesClaim string `env:"AUTH_ROLES_CLAIM" envDefault:"roles"`
+	// PublicReads lets GET and HEAD requests without tokens, PublicRoutes lets the listed routes, like GET /tags
+	PublicReads  bool     `env:"AUTH_PUBLIC_READS" envDefault:"true"`
+	PublicRoutes []string `env:"AUTH_PUBLIC_ROUTES" envSeparator:"," envDefault:"GET /swagger/*path"`
+}
@@ -0,0 +1,29 @@
+package config
+
+// Blob store names supported by BLOB_STORE
+const (
+	FilesystemBlobStore = "filesystem"
+	S3BlobStore         = "s3"
+)
+
+// Blob configures the store of contents data, it is used by the postgres database
+type Blob struct {
+	Store      string `env:"BLOB_STORE" envDefault:"filesystem"`
+	Filesystem Filesystem
+	S3         S3
+}
+
+type Filesystem struct {
+	Dir string `env:"BLOB_FS_DIR" envDefault:"./data/blobs"`
+}
+
+type S3 struct {
+	Endpoint  string `env:"BLOB_S3_ENDPOINT" envDefault:"localhost:9000"`
+	AccessKey string `env:"BLOB_S3_ACCESS_KEY"`
+	SecretKey string `env:"BLOB_S3_SECRET_KEY"`
+	Bucket    string `env:"BLOB_S3_BUCKET" envDefault:"contents"`
+	Region    string `env:"BLOB_S3_REGION"`
+	UseSSL    bool   `env:"BLOB_S3_USE_SSL" envDefault:"false"`
+	// CreateBucket creates the bucket on start if it doesn't exist
+	CreateBucket bool `env:"BLOB_S3_CREATE_BUCKET" envDefault:"false"`
+}
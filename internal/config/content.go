@@ -0,0 +1,9 @@
+package config
+
+// Content limits contents accepted by the server, sizes are in bytes
+type Content struct {
+	// AllowedTypes are MIME types detected by the data, not the ones sent by clients
+	AllowedTypes []string `env:"CONTENT_ALLOWED_TYPES" envSeparator:"," envDefault:"image/jpeg,image/png,image/gif,image/webp,video/mp4,application/pdf"`
+	MaxSize      int64    `env:"CONTENT_MAX_SIZE" envDefault:"10485760"`
+	MaxCraftSize int64    `env:"CONTENT_MAX_CRAFT_SIZE" envDefault:"104857600"`
+}
@@ -1,7 +1,42 @@
 package config
 
+import "time"
+
 type Kafka struct {
-	Host  string `env:"KAFKA_HOST" envDefault:"localhost"`
-	Port  uint16 `env:"KAFKA_PORT" envDefault:"9092"`
-	Topic string `env:"KAFKA_TOPIC"`
+	Host string `env:"KAFKA_HOST" envDefault:"localhost"`
+	Port uint16 `env:"KAFKA_PORT" envDefault:"9092"`
+	// Brokers is a list of host:port, if it is set host and port are not used
+	Brokers []string `env:"KAFKA_BROKERS" envSeparator:","`
+	Topic   string   `env:"KAFKA_TOPIC"`
+	// CatalogTopic gets events of categories, tags and tags of crafts, they are sent to Topic if it is not set
+	CatalogTopic string `env:"KAFKA_CATALOG_TOPIC"`
+	ClientID     string `env:"KAFKA_CLIENT_ID" envDefault:"portfolio-service"`
+	// Version is the version of kafka brokers, it defines which protocol features are used
+	Version string `env:"KAFKA_VERSION" envDefault:"2.1.0"`
+	// RequiredAcks can be none, leader or all
+	RequiredAcks string `env:"KAFKA_REQUIRED_ACKS" envDefault:"all"`
+	// Idempotent producer requires all acks and at least one retry
+	Idempotent   bool          `env:"KAFKA_IDEMPOTENT" envDefault:"false"`
+	RetryMax     int           `env:"KAFKA_RETRY_MAX" envDefault:"3"`
+	RetryBackoff time.Duration `env:"KAFKA_RETRY_BACKOFF" envDefault:"100ms"`
+	// Compression can be none, gzip, snappy, lz4 or zstd
+	Compression string `env:"KAFKA_COMPRESSION" envDefault:"none"`
+	TLS         KafkaTLS
+	SASL        KafkaSASL
+}
+
+// KafkaTLS enables tls, without CAFile the system roots are used, CertFile and KeyFile are set for client authentication
+type KafkaTLS struct {
+	Enabled            bool   `env:"KAFKA_TLS_ENABLED" envDefault:"false"`
+	CAFile             string `env:"KAFKA_TLS_CA_FILE"`
+	CertFile           string `env:"KAFKA_TLS_CERT_FILE"`
+	KeyFile            string `env:"KAFKA_TLS_KEY_FILE"`
+	InsecureSkipVerify bool   `env:"KAFKA_TLS_INSECURE_SKIP_VERIFY" envDefault:"false"`
+}
+
+// KafkaSASL enables authentication if the mechanism is set, it can be PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+type KafkaSASL struct {
+	Mechanism string `env:"KAFKA_SASL_MECHANISM"`
+	User      string `env:"KAFKA_SASL_USER"`
+	Password  string `env:"KAFKA_SASL_PASSWORD"`
 }
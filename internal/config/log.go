@@ -0,0 +1,13 @@
+package config
+
+// Log formats supported by LOG_FORMAT
+const (
+	TextLogFormat = "text"
+	JSONLogFormat = "json"
+)
+
+// Log configures the logger of the service, the level is debug, info, warn or error
+type Log struct {
+	Level  string `env:"LOG_LEVEL" envDefault:"info"`
+	Format string `env:"LOG_FORMAT" envDefault:"text"`
+}
@@ -7,6 +7,6 @@ type Mongo struct {
 	Port                   int    `env:"MG_PORT" envDefault:"27017"`
 	AuthenticationDatabase string `env:"MG_AUTH_DATABASE"`
 	Database               string `env:"MG_DATABASE"`
-	Collection             string `env:"MG_COLLECTION"`
+	Collection             string `env:"MG_COLLECTION" envDefault:"portfolios"`
 	HaveIndexes            bool   `env:"MG_HAVE_INDEXES"`
 }
@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// Outbox configures the relay of events saved by postgres together with changes
+type Outbox struct {
+	PollInterval time.Duration `env:"OUTBOX_POLL_INTERVAL" envDefault:"1s"`
+	BatchSize    int           `env:"OUTBOX_BATCH_SIZE" envDefault:"100"`
+	SendTimeout  time.Duration `env:"OUTBOX_SEND_TIMEOUT" envDefault:"10s"`
+	// failed sending is retried with backoff doubling from RetryMinBackoff up to RetryMaxBackoff
+	RetryMinBackoff time.Duration `env:"OUTBOX_RETRY_MIN_BACKOFF" envDefault:"1s"`
+	RetryMaxBackoff time.Duration `env:"OUTBOX_RETRY_MAX_BACKOFF" envDefault:"1m"`
+	// Retention is how long delivered events are kept, 0 keeps them forever
+	Retention time.Duration `env:"OUTBOX_RETENTION" envDefault:"24h"`
+}
@@ -6,4 +6,6 @@ type Postgres struct {
 	Host     string `env:"PG_HOST" envDefault:"localhost"`
 	Port     uint16 `env:"PG_PORT" envDefault:"5432"`
 	Database string `env:"PG_DATABASE"`
+	// AutoMigrate applies pending migrations on start of the application
+	AutoMigrate bool `env:"PG_AUTO_MIGRATE" envDefault:"false"`
 }
@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// Actions with portfolios of deleted profiles supported by PROFILE_EVENTS_ACTION
+const (
+	DeleteProfileAction    = "delete"
+	AnonymizeProfileAction = "anonymize"
+)
+
+// ProfileEvents configures the consumer of events of the profile service, it uses the connection settings of Kafka
+type ProfileEvents struct {
+	Enabled bool   `env:"PROFILE_EVENTS_ENABLED" envDefault:"false"`
+	Topic   string `env:"PROFILE_EVENTS_TOPIC" envDefault:"profile-events"`
+	GroupID string `env:"PROFILE_EVENTS_GROUP_ID" envDefault:"portfolio-service"`
+	// Action is what is done with portfolios of a deleted profile
+	Action string `env:"PROFILE_EVENTS_ACTION" envDefault:"delete"`
+	// AnonymousProfileID is the profile which gets anonymized portfolios
+	AnonymousProfileID int `env:"PROFILE_EVENTS_ANONYMOUS_PROFILE_ID" envDefault:"0"`
+	// RetryMinBackoff and RetryMaxBackoff limit pauses between attempts to handle the event which failed
+	RetryMinBackoff time.Duration `env:"PROFILE_EVENTS_RETRY_MIN_BACKOFF" envDefault:"1s"`
+	RetryMaxBackoff time.Duration `env:"PROFILE_EVENTS_RETRY_MAX_BACKOFF" envDefault:"1m"`
+}
@@ -0,0 +1,28 @@
+package config
+
+import "time"
+
+// Rate limit store names supported by RATE_LIMIT_STORE
+const (
+	MemoryRateLimitStore = "memory"
+)
+
+// RateLimit configures token buckets of clients, each budget allows Limit requests at once and refills
+// them during Window, zero Limit disables the budget
+type RateLimit struct {
+	Enabled      bool          `env:"RATE_LIMIT_ENABLED" envDefault:"false"`
+	Store        string        `env:"RATE_LIMIT_STORE" envDefault:"memory"`
+	ReadLimit    int           `env:"RATE_LIMIT_READ_LIMIT" envDefault:"300"`
+	ReadWindow   time.Duration `env:"RATE_LIMIT_READ_WINDOW" envDefault:"1m"`
+	WriteLimit   int           `env:"RATE_LIMIT_WRITE_LIMIT" envDefault:"60"`
+	WriteWindow  time.Duration `env:"RATE_LIMIT_WRITE_WINDOW" envDefault:"1m"`
+	UploadLimit  int           `env:"RATE_LIMIT_UPLOAD_LIMIT" envDefault:"10"`
+	UploadWindow time.Duration `env:"RATE_LIMIT_UPLOAD_WINDOW" envDefault:"1m"`
+	// IPLimit limits all requests of the IP before authentication, including ones with invalid tokens
+	IPLimit  int           `env:"RATE_LIMIT_IP_LIMIT" envDefault:"600"`
+	IPWindow time.Duration `env:"RATE_LIMIT_IP_WINDOW" envDefault:"1m"`
+	// TrustedProxies are IPs or CIDRs of proxies, the client IP is taken from X-Forwarded-For only behind them
+	TrustedProxies []string `env:"RATE_LIMIT_TRUSTED_PROXIES" envSeparator:","`
+	// SweepInterval is how often the memory store forgets buckets which are full again
+	SweepInterval time.Duration `env:"RATE_LIMIT_SWEEP_INTERVAL" envDefault:"1m"`
+}
@@ -7,4 +7,10 @@ type Server struct {
 	ReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT" envDefault:"5s"`
 	WriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT" envDefault:"5s"`
 	IdleTimeout  time.Duration `env:"SERVER_IDLE_TIMEOUT" envDefault:"30s"`
+	// TransferTimeout replaces read and write timeouts of uploads and downloads of contents data
+	TransferTimeout time.Duration `env:"SERVER_TRANSFER_TIMEOUT" envDefault:"10m"`
+	Content         Content
+	Thumbnail       Thumbnail
+	Auth            Auth
+	RateLimit       RateLimit
 }
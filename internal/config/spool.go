@@ -0,0 +1,18 @@
+package config
+
+import "time"
+
+// Spool configures the disk spool of events which failed to be sent, sizes are in bytes
+type Spool struct {
+	Enabled bool   `env:"SPOOL_ENABLED" envDefault:"false"`
+	Dir     string `env:"SPOOL_DIR" envDefault:"./data/spool"`
+	// MaxSize limits all segments together, events are not spooled when it is reached
+	MaxSize     int64 `env:"SPOOL_MAX_SIZE" envDefault:"1073741824"`
+	SegmentSize int64 `env:"SPOOL_SEGMENT_SIZE" envDefault:"16777216"`
+	// Sync flushes every spooled event and replay position to the disk
+	Sync        bool          `env:"SPOOL_SYNC" envDefault:"true"`
+	SendTimeout time.Duration `env:"SPOOL_SEND_TIMEOUT" envDefault:"10s"`
+	// failed replay is retried with backoff doubling from RetryMinBackoff up to RetryMaxBackoff
+	RetryMinBackoff time.Duration `env:"SPOOL_RETRY_MIN_BACKOFF" envDefault:"1s"`
+	RetryMaxBackoff time.Duration `env:"SPOOL_RETRY_MAX_BACKOFF" envDefault:"1m"`
+}
@@ -1,7 +1,15 @@
 package config
 
+// Database names supported by STORAGE_DATABASE
+const (
+	PostgresDatabase = "postgres"
+	MongoDatabase    = "mongo"
+	MemoryDatabase   = "memory"
+)
+
 type Storage struct {
-	Database string `env:"STORAGE_DATABASE"`
+	Database string `env:"STORAGE_DATABASE" envDefault:"postgres"`
 	Postgres Postgres
 	Mongo    Mongo
+	Blob     Blob
 }
@@ -0,0 +1,12 @@
+package config
+
+type Thumbnail struct {
+	// Sizes limit the longest side of thumbnails, images smaller than the size don't get its thumbnail
+	Sizes       []int `env:"THUMBNAIL_SIZES" envSeparator:"," envDefault:"160,320,640"`
+	JPEGQuality int   `env:"THUMBNAIL_JPEG_QUALITY" envDefault:"85"`
+	// MaxSourcePixels protects from decoding of huge images, bigger images don't get thumbnails
+	MaxSourcePixels int `env:"THUMBNAIL_MAX_SOURCE_PIXELS" envDefault:"50000000"`
+	// Workers make thumbnails at once, contents wait for them in the queue of QueueSize, contents don't get thumbnails if it is full
+	Workers   int `env:"THUMBNAIL_WORKERS" envDefault:"2"`
+	QueueSize int `env:"THUMBNAIL_QUEUE_SIZE" envDefault:"100"`
+}
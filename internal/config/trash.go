@@ -0,0 +1,9 @@
+package config
+
+import "time"
+
+type Trash struct {
+	// Retention is how long deleted objects are kept in the trash, 0 disables purging
+	Retention     time.Duration `env:"TRASH_RETENTION" envDefault:"720h"`
+	PurgeInterval time.Duration `env:"TRASH_PURGE_INTERVAL" envDefault:"1h"`
+}
@@ -0,0 +1,129 @@
+package connector
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// Database is the storage of portfolios: postgresql, mongodb or memory
+type Database interface {
+	GetAllPortfolios(ctx context.Context, page models.Page, id int, filterType models.PortfoliosFilterType) ([]models.Portfolio, error)
+	CountPortfoliosPages(ctx context.Context, id int, filterType models.PortfoliosFilterType) (int, error)
+	GetPortfolioByID(ctx context.Context, portfolioID int) (*models.Portfolio, error)
+	CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (int, error)
+	PatchPortfolio(ctx context.Context, authorID int, portfolio models.Portfolio) error
+	DeletePortfolio(ctx context.Context, portfolioID int) error
+	CreateCategory(ctx context.Context, name string) (int, error)
+	DeleteCategory(ctx context.Context, id int) error
+	ProposeCategory(ctx context.Context, name string, profileID int) (int, error)
+	ReviewCategory(ctx context.Context, id int, approve bool) error
+	GetAllCategories(ctx context.Context, page models.Page, pending bool) ([]models.Category, error)
+	CountCategoriesPages(ctx context.Context, pending bool) (int, error)
+	GetAllCraftsByPortfolioID(ctx context.Context, portfolioID int, page models.Page) ([]models.Craft, error)
+	GetAllCraftsByTagID(ctx context.Context, tagID int, page models.Page) ([]models.Craft, error)
+	CountCraftsPages(ctx context.Context, id int, isPortfolioID bool) (int, error)
+	GetCraftByID(ctx context.Context, craftID int) (*models.Craft, error)
+	CreateCraft(ctx context.Context, portfolioID int, craft models.Craft) (int, error)
+	AddTagToCraft(ctx context.Context, craftID int, tagID int) error
+	DeleteTagFromCraft(ctx context.Context, craftID int, tagID int) error
+	PatchCraft(ctx context.Context, authorID int, craft models.Craft) error
+	DeleteCraft(ctx context.Context, id int) error
+	GetAllTags(ctx context.Context, page models.Page, pending bool) ([]models.Tag, error)
+	CountTagsPages(ctx context.Context, pending bool) (int, error)
+	CreateTag(ctx context.Context, name string) (int, error)
+	DeleteTag(ctx context.Context, id int) error
+	ProposeTag(ctx context.Context, name string, profileID int) (int, error)
+	ReviewTag(ctx context.Context, id int, approve bool) error
+	CreateContent(ctx context.Context, craftID int, content models.Content) (int, error)
+	CreateContentFromReader(ctx context.Context, craftID int, content models.Content, data io.Reader) (int, error)
+	GetContentData(ctx context.Context, contentID int) (*models.ContentData, error)
+	SaveThumbnails(ctx context.Context, contentID int, checksum string, thumbnails []models.Thumbnail) error
+	GetThumbnailData(ctx context.Context, contentID, size int) (*models.ContentData, error)
+	ReorderCrafts(ctx context.Context, portfolioID int, order models.Order) error
+	ReorderContents(ctx context.Context, craftID int, order models.Order) error
+	GetCraftContentsSize(ctx context.Context, craftID, exceptContentID int) (int64, error)
+	DeleteContent(ctx context.Context, id int) error
+	PatchContent(ctx context.Context, authorID int, content models.Content) error
+	Search(ctx context.Context, query models.SearchQuery, page models.Page) ([]models.SearchResult, error)
+	CountSearchResults(ctx context.Context, query models.SearchQuery) (int, error)
+	GetTrash(ctx context.Context, profileID int) ([]models.TrashItem, error)
+	RestorePortfolio(ctx context.Context, profileID, portfolioID int) error
+	RestoreCraft(ctx context.Context, profileID, craftID int) error
+	RestoreContent(ctx context.Context, profileID, contentID int) error
+	PurgeTrash(ctx context.Context, before time.Time) ([]models.TrashItem, error)
+	DeleteProfilePortfolios(ctx context.Context, profileID int) ([]models.TrashItem, error)
+	AnonymizeProfilePortfolios(ctx context.Context, profileID, anonymousID int) ([]models.TrashItem, error)
+	GetRevisions(ctx context.Context, object string, id int, page models.Page) ([]models.Revision, error)
+	CountRevisions(ctx context.Context, object string, id int) (int, error)
+	GetRevision(ctx context.Context, object string, id, revision int) (*models.Revision, error)
+	Revert(ctx context.Context, authorID int, object string, id, revision int) error
+	CheckPath(ctx context.Context, path models.Path) error
+}
+
+// Connector adds amounts of pages to lists of the database, other methods of the database are used as they are
+type Connector struct {
+	Database
+}
+
+func NewConnector(db Database) *Connector {
+	return &Connector{Database: db}
+}
+
+func (c *Connector) GetAllPortfolios(ctx context.Context, page models.Page, id int, filterType models.PortfoliosFilterType) ([]models.Portfolio, int, error) {
+	return getPage(page, func() ([]models.Portfolio, error) {
+		return c.Database.GetAllPortfolios(ctx, page, id, filterType)
+	}, func() (int, error) {
+		return c.Database.CountPortfoliosPages(ctx, id, filterType)
+	})
+}
+
+func (c *Connector) GetAllCategories(ctx context.Context, page models.Page, pending bool) ([]models.Category, int, error) {
+	return getPage(page, func() ([]models.Category, error) {
+		return c.Database.GetAllCategories(ctx, page, pending)
+	}, func() (int, error) {
+		return c.Database.CountCategoriesPages(ctx, pending)
+	})
+}
+
+func (c *Connector) GetAllCraftsByPortfolioID(ctx context.Context, portfolioID int, page models.Page) ([]models.Craft, int, error) {
+	return getPage(page, func() ([]models.Craft, error) {
+		return c.Database.GetAllCraftsByPortfolioID(ctx, portfolioID, page)
+	}, func() (int, error) {
+		return c.Database.CountCraftsPages(ctx, portfolioID, true)
+	})
+}
+
+func (c *Connector) GetAllCraftsByTagID(ctx context.Context, tagID int, page models.Page) ([]models.Craft, int, error) {
+	return getPage(page, func() ([]models.Craft, error) {
+		return c.Database.GetAllCraftsByTagID(ctx, tagID, page)
+	}, func() (int, error) {
+		return c.Database.CountCraftsPages(ctx, tagID, false)
+	})
+}
+
+func (c *Connector) GetAllTags(ctx context.Context, page models.Page, pending bool) ([]models.Tag, int, error) {
+	return getPage(page, func() ([]models.Tag, error) {
+		return c.Database.GetAllTags(ctx, page, pending)
+	}, func() (int, error) {
+		return c.Database.CountTagsPages(ctx, pending)
+	})
+}
+
+func (c *Connector) Search(ctx context.Context, query models.SearchQuery, page models.Page) ([]models.SearchResult, int, error) {
+	return getPage(page, func() ([]models.SearchResult, error) {
+		return c.Database.Search(ctx, query, page)
+	}, func() (int, error) {
+		return c.Database.CountSearchResults(ctx, query)
+	})
+}
+
+func (c *Connector) GetRevisions(ctx context.Context, object string, id int, page models.Page) ([]models.Revision, int, error) {
+	return getPage(page, func() ([]models.Revision, error) {
+		return c.Database.GetRevisions(ctx, object, id, page)
+	}, func() (int, error) {
+		return c.Database.CountRevisions(ctx, object, id)
+	})
+}
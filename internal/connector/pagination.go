@@ -0,0 +1,34 @@
+package connector
+
+import (
+	"fmt"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// getPage gets the page of rows and the amount of pages, pages are not counted in cursor mode
+func getPage[T any](page models.Page, get func() ([]T, error), count func() (int, error)) ([]T, int, error) {
+	rows, err := get()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get data from database: %w", err)
+	}
+
+	if page.IsCursor() {
+		return rows, 0, nil
+	}
+
+	rowsAmount, err := count()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count pages: %w", err)
+	}
+
+	return rows, pagesAmount(rowsAmount, page.Limit), nil
+}
+
+func pagesAmount(rowsAmount int, limit int) int {
+	if rowsAmount%limit != 0 {
+		return rowsAmount/limit + 1
+	}
+
+	return rowsAmount / limit
+}
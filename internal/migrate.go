@@ -0,0 +1,115 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/logging"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/storage/postgresql"
+)
+
+// defaultBlobsBatch is the amount of contents read at once by the blobs command
+const defaultBlobsBatch = 100
+
+// Migrate runs the migrate command: up (default), down [steps], status or blobs [batch]
+func Migrate(cfg config.Application, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	logger, err := logging.New(cfg.Log, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	if command == "blobs" {
+		return moveBlobs(cfg, args, logger)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// schema migrations don't touch contents data, so the blob store is not needed
+	db, err := postgresql.NewDB(ctx, cfg.Storage.Postgres, nil, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch command {
+	case "up":
+		applied, err := db.MigrateUp(ctx)
+		if err != nil {
+			return err
+		}
+		logger.Info("migrations are applied", slog.Int("count", applied))
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("incorrect amount of steps %q: must be greater than 0", args[1])
+			}
+		}
+
+		reverted, err := db.MigrateDown(ctx, steps)
+		if err != nil {
+			return err
+		}
+		logger.Info("migrations are reverted", slog.Int("count", reverted))
+	case "status":
+		statuses, err := db.MigrationsStatus(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.Applied {
+				fmt.Printf("%04d_%s\tapplied at %s\n", status.Version, status.Name, status.AppliedAt.Format(time.RFC3339))
+			} else {
+				fmt.Printf("%04d_%s\tpending\n", status.Version, status.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q: must be up, down [steps], status or blobs [batch]", command)
+	}
+
+	return nil
+}
+
+// moveBlobs moves data of contents from the postgres table to the blob store, it has no timeout because of the data size
+func moveBlobs(cfg config.Application, args []string, logger *slog.Logger) error {
+	batch := defaultBlobsBatch
+	if len(args) > 1 {
+		var err error
+		if batch, err = strconv.Atoi(args[1]); err != nil || batch <= 0 {
+			return fmt.Errorf("incorrect batch size %q: must be greater than 0", args[1])
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	blobs, err := newBlobStore(ctx, cfg.Storage.Blob)
+	if err != nil {
+		return err
+	}
+
+	db, err := postgresql.NewDB(ctx, cfg.Storage.Postgres, blobs, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	moved, err := db.MoveContentDataToBlobs(ctx, batch)
+	logger.Info("data of contents is moved to the blob store", slog.Int("count", moved))
+
+	return err
+}
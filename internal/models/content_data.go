@@ -0,0 +1,12 @@
+package models
+
+import "io"
+
+// ContentData is raw data of the content, the caller must close Data
+type ContentData struct {
+	Data     io.ReadSeekCloser
+	MIMEType string
+	Size     int64
+	// Checksum is hex encoded SHA-256 of the data
+	Checksum string
+}
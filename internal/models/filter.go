@@ -0,0 +1,9 @@
+package models
+
+type PortfoliosFilterType string
+
+const (
+	FilterEmpty        PortfoliosFilterType = ""
+	FilterByProfileID  PortfoliosFilterType = "ByProfileID"
+	FilterByCategoryID PortfoliosFilterType = "ByCategoryID"
+)
@@ -0,0 +1,11 @@
+package models
+
+// Names of objects in search results, trash and events, the same as sender objects
+const (
+	ObjectPortfolio = "portfolio"
+	ObjectCraft     = "craft"
+	ObjectContent   = "content"
+	ObjectCategory  = "category"
+	ObjectTag       = "tag"
+	ObjectCraftTag  = "craft_tag"
+)
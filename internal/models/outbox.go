@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// OutboxEvent is an event saved by the database together with the change, it is sent to the broker by the relay.
+// EventID is kept on redeliveries, parents which the object doesn't have are zero, RequestID is empty for changes
+// made outside of requests
+type OutboxEvent struct {
+	ID          int64
+	EventID     string
+	RequestID   string
+	ProfileID   int
+	PortfolioID int
+	CraftID     int
+	Object      string
+	ObjectID    int
+	Change      string
+	Snapshot    []byte
+	CreatedAt   time.Time
+	Attempts    int
+}
@@ -1,10 +1,66 @@
 package models
 
+// Page describes requested part of a list: by page number (Offset) or after the cursor (After)
+type Page struct {
+	Limit  int
+	Offset int
+	// After is set in cursor mode, rows are returned starting after it and pages aren't counted
+	After *Cursor
+}
+
+// IsCursor reports whether the page is requested in cursor mode
+func (p Page) IsCursor() bool {
+	return p.After != nil
+}
+
+// AfterID returns id of the last row of the previous page, 0 in page number mode
+func (p Page) AfterID() int {
+	if p.After == nil {
+		return 0
+	}
+	return p.After.ID
+}
+
+// AfterPosition returns position of the last row of the previous page for lists sorted by position, 0 in page number mode
+func (p Page) AfterPosition() int {
+	if p.After == nil {
+		return 0
+	}
+	return p.After.Position
+}
+
+// Cursor points to the last row of the previous page, lists are sorted by id, crafts of the portfolio - by position and id
+type Cursor struct {
+	ID int `json:"id"`
+	// Position is used by crafts of the portfolio only
+	Position int `json:"position,omitempty"`
+	// Rank and Object are used by search results only
+	Rank   float32 `json:"rank,omitempty"`
+	Object string  `json:"object,omitempty"`
+}
+
+func (p Portfolio) Cursor() Cursor {
+	return Cursor{ID: p.ID}
+}
+
+func (c Category) Cursor() Cursor {
+	return Cursor{ID: c.ID}
+}
+
+func (c Craft) Cursor() Cursor {
+	return Cursor{ID: c.ID, Position: c.Position}
+}
+
+func (t Tag) Cursor() Cursor {
+	return Cursor{ID: t.ID}
+}
+
 type PortfoliosPage struct {
 	Portfolios  []Portfolio `json:"portfolios"`
 	PageNo      int         `json:"page_number"`
 	Limit       int         `json:"limit"`
 	PagesAmount int         `json:"pages_amount"`
+	NextCursor  string      `json:"next_cursor,omitempty"`
 }
 
 type CategoriesPage struct {
@@ -12,6 +68,7 @@ type CategoriesPage struct {
 	PageNo      int        `json:"page_number"`
 	Limit       int        `json:"limit"`
 	PagesAmount int        `json:"pages_amount"`
+	NextCursor  string     `json:"next_cursor,omitempty"`
 }
 
 type CraftsPage struct {
@@ -19,11 +76,13 @@ type CraftsPage struct {
 	PageNo      int     `json:"page_number"`
 	Limit       int     `json:"limit"`
 	PagesAmount int     `json:"pages_amount"`
+	NextCursor  string  `json:"next_cursor,omitempty"`
 }
 
 type TagsPage struct {
-	Tags        []Tag `json:"tags"`
-	PageNo      int   `json:"page_number"`
-	Limit       int   `json:"limit"`
-	PagesAmount int   `json:"pages_amount"`
+	Tags        []Tag  `json:"tags"`
+	PageNo      int    `json:"page_number"`
+	Limit       int    `json:"limit"`
+	PagesAmount int    `json:"pages_amount"`
+	NextCursor  string `json:"next_cursor,omitempty"`
 }
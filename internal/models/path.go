@@ -0,0 +1,10 @@
+package models
+
+// Path is the hierarchy of the object in the api: the profile owns the portfolio, the portfolio has the craft
+// and the craft has the content, ids of objects which are not on the path are zero
+type Path struct {
+	ProfileID   int
+	PortfolioID int
+	CraftID     int
+	ContentID   int
+}
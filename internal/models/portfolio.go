@@ -3,32 +3,86 @@ package models
 type Portfolio struct {
 	ID          int      `json:"portfolio_id" bson:"_id"`
 	ProfileID   int      `json:"profile_id" bson:"profile_id"`
-	Name        string   `json:"name" bson:"name, omitempty"`
-	Category    Category `json:"category" bson:"category, omitempty"`
-	Description string   `json:"description" bson:"description, omitempty"`
-	Crafts      []Craft  `json:"crafts" bson:"crafts, omitempty"`
+	Name        string   `json:"name" bson:"name,omitempty"`
+	Category    Category `json:"category" bson:"category,omitempty"`
+	Description string   `json:"description" bson:"description,omitempty"`
+	Crafts      []Craft  `json:"crafts" bson:"crafts,omitempty"`
 }
 
+// StatusPending marks categories and tags proposed by users, they can't be used until they are approved,
+// approved ones have no status
+const StatusPending = "pending"
+
 type Category struct {
 	ID   int    `json:"category_id" bson:"_id"`
 	Name string `json:"category_name" bson:"category_name"`
+	// Status and ProposedBy are set for proposals only
+	Status     string `json:"status,omitempty" bson:"status,omitempty"`
+	ProposedBy int    `json:"proposed_by,omitempty" bson:"proposed_by,omitempty"`
 }
 
 type Craft struct {
 	ID          int       `json:"craft_id" bson:"_id"`
 	Name        string    `json:"craft_name" bson:"craft_name"`
-	Tags        []Tag     `json:"tags" bson:"tags, omitempty"`
-	Description string    `json:"craft_description" bson:"craft_description, omitempty"`
+	Tags        []Tag     `json:"tags" bson:"tags,omitempty"`
+	Description string    `json:"craft_description" bson:"craft_description,omitempty"`
 	Contents    []Content `json:"contents" bson:"contents"`
+	// Position orders crafts of the portfolio, it is changed by reordering only
+	Position int `json:"position" bson:"position"`
+	// PortfolioID and ProfileID are filled on reading only, they are kept by the portfolio
+	PortfolioID int `json:"portfolio_id,omitempty" bson:"portfolio_id,omitempty"`
+	ProfileID   int `json:"profile_id,omitempty" bson:"profile_id,omitempty"`
 }
 
 type Tag struct {
 	ID   int    `json:"tag_id" bson:"_id"`
 	Name string `json:"tag_name" bson:"tag_name"`
+	// Status and ProposedBy are set for proposals only
+	Status     string `json:"status,omitempty" bson:"status,omitempty"`
+	ProposedBy int    `json:"proposed_by,omitempty" bson:"proposed_by,omitempty"`
 }
 
 type Content struct {
 	ID          int    `json:"content_id" bson:"_id"`
-	Description string `json:"content_description" bson:"content_description, omitempty"`
-	Data        []byte `json:"data" bson:"data"`
+	Description string `json:"content_description" bson:"content_description,omitempty"`
+	Data        []byte `json:"data,omitempty" bson:"data"`
+	// Position orders contents of the craft, it is changed by reordering only
+	Position int `json:"position" bson:"position"`
+	// DataURL replaces Data in responses when data is requested by url
+	DataURL string `json:"data_url,omitempty" bson:"-"`
+	// metadata is detected by the data, values sent by clients are ignored
+	MIMEType string `json:"mime_type,omitempty" bson:"mime_type,omitempty"`
+	Size     int64  `json:"size,omitempty" bson:"size,omitempty"`
+	// Checksum is hex encoded SHA-256 of the data
+	Checksum string `json:"sha256,omitempty" bson:"sha256,omitempty"`
+	// Width and Height are set for images only
+	Width  int `json:"width,omitempty" bson:"width,omitempty"`
+	Height int `json:"height,omitempty" bson:"height,omitempty"`
+	// Thumbnails are made for images after the content is saved, so they may be missing for a while
+	Thumbnails []Thumbnail `json:"thumbnails,omitempty" bson:"thumbnails,omitempty"`
+}
+
+// Order is the full list of ids of crafts of the portfolio or contents of the craft in their new order
+type Order struct {
+	IDs []int `json:"ids"`
+}
+
+// Matches reports whether the order contains every one of ids exactly once and nothing else
+func (o Order) Matches(ids []int) bool {
+	if len(o.IDs) != len(ids) {
+		return false
+	}
+
+	left := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		left[id] = true
+	}
+	for _, id := range o.IDs {
+		if !left[id] {
+			return false
+		}
+		delete(left, id)
+	}
+
+	return true
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Revision keeps values of portfolio, craft or content before the change, revisions are numbered from 1 for every object,
+// name is empty for contents and category id is used by portfolios only
+type Revision struct {
+	Object      string    `json:"object"`
+	ObjectID    int       `json:"object_id"`
+	Number      int       `json:"revision"`
+	AuthorID    int       `json:"author_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Name        string    `json:"name,omitempty"`
+	Description string    `json:"description"`
+	CategoryID  int       `json:"category_id,omitempty"`
+}
+
+// Cursor of revision keeps its number, revisions are sorted by number
+func (r Revision) Cursor() Cursor {
+	return Cursor{ID: r.Number}
+}
+
+type RevisionsPage struct {
+	Revisions   []Revision `json:"revisions"`
+	PageNo      int        `json:"page_number"`
+	Limit       int        `json:"limit"`
+	PagesAmount int        `json:"pages_amount"`
+	NextCursor  string     `json:"next_cursor,omitempty"`
+}
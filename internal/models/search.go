@@ -0,0 +1,30 @@
+package models
+
+type SearchQuery struct {
+	Text       string
+	CategoryID int
+	TagID      int
+}
+
+type SearchResult struct {
+	Object      string  `json:"object"`
+	ID          int     `json:"id"`
+	PortfolioID int     `json:"portfolio_id"`
+	ProfileID   int     `json:"profile_id"`
+	Name        string  `json:"name"`
+	Snippet     string  `json:"snippet"`
+	Rank        float32 `json:"rank"`
+}
+
+// Cursor of search result keeps its rank, results are sorted by rank, object and id
+func (sr SearchResult) Cursor() Cursor {
+	return Cursor{ID: sr.ID, Rank: sr.Rank, Object: sr.Object}
+}
+
+type SearchPage struct {
+	Results     []SearchResult `json:"results"`
+	PageNo      int            `json:"page_number"`
+	Limit       int            `json:"limit"`
+	PagesAmount int            `json:"pages_amount"`
+	NextCursor  string         `json:"next_cursor,omitempty"`
+}
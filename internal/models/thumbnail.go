@@ -0,0 +1,11 @@
+package models
+
+// Thumbnail is a downscaled copy of the image content, Size is the limit of its longest side
+type Thumbnail struct {
+	Size     int    `json:"size" bson:"size"`
+	Width    int    `json:"width" bson:"width"`
+	Height   int    `json:"height" bson:"height"`
+	MIMEType string `json:"mime_type" bson:"mime_type"`
+	URL      string `json:"url,omitempty" bson:"-"`
+	Data     []byte `json:"-" bson:"data"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TrashItem is a deleted portfolio, craft or content which can be restored until it is purged,
+// crafts and contents of a deleted portfolio are not listed separately
+type TrashItem struct {
+	Object      string    `json:"object"`
+	ID          int       `json:"id"`
+	ProfileID   int       `json:"profile_id"`
+	PortfolioID int       `json:"portfolio_id"`
+	CraftID     int       `json:"craft_id,omitempty"`
+	Name        string    `json:"name"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
+
+type Trash struct {
+	Items []TrashItem `json:"items"`
+}
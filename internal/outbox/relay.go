@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// cleanupInterval is how often delivered events older than retention are deleted
+const cleanupInterval = time.Minute
+
+type Store interface {
+	RelayEvents(ctx context.Context, limit int, send func(ctx context.Context, event models.OutboxEvent) error) (int, error)
+	DeleteDeliveredEvents(ctx context.Context, before time.Time) (int, error)
+}
+
+// Relay sends events saved to the outbox by the database, an event is marked as delivered only after it is sent,
+// so every event is delivered at least once and events are delivered in the order they were saved
+type Relay struct {
+	store           Store
+	sender          sender.Sender
+	interval        time.Duration
+	batchSize       int
+	sendTimeout     time.Duration
+	retryMinBackoff time.Duration
+	retryMaxBackoff time.Duration
+	retention       time.Duration
+	logger          *slog.Logger
+	finishClosing   sync.WaitGroup
+}
+
+func NewRelay(cfg config.Outbox, store Store, s sender.Sender, logger *slog.Logger) *Relay {
+	return &Relay{
+		store:           store,
+		sender:          s,
+		interval:        cfg.PollInterval,
+		batchSize:       cfg.BatchSize,
+		sendTimeout:     cfg.SendTimeout,
+		retryMinBackoff: cfg.RetryMinBackoff,
+		retryMaxBackoff: cfg.RetryMaxBackoff,
+		retention:       cfg.Retention,
+		logger:          logger,
+		finishClosing:   sync.WaitGroup{},
+	}
+}
+
+func (r *Relay) Run(ctx context.Context) {
+	r.finishClosing.Add(1)
+
+	go func() {
+		defer r.finishClosing.Done()
+
+		var backoff time.Duration
+		var cleaned time.Time
+		for {
+			wait := r.interval
+			delivered, err := r.store.RelayEvents(ctx, r.batchSize, r.send)
+			switch {
+			case err != nil:
+				r.logger.Error("failed to relay events", slog.Any("error", err))
+				backoff = min(max(backoff*2, r.retryMinBackoff), r.retryMaxBackoff)
+				wait = backoff
+			case delivered == r.batchSize:
+				// there may be more events, they are relayed without waiting
+				backoff, wait = 0, 0
+			default:
+				backoff = 0
+			}
+
+			if time.Since(cleaned) >= cleanupInterval {
+				r.deleteDelivered(ctx)
+				cleaned = time.Now()
+			}
+
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (r *Relay) send(ctx context.Context, event models.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, r.sendTimeout)
+	defer cancel()
+
+	return r.sender.Send(ctx, sender.Event{
+		ID:          event.EventID,
+		Time:        event.CreatedAt,
+		RequestID:   event.RequestID,
+		ProfileID:   event.ProfileID,
+		PortfolioID: event.PortfolioID,
+		CraftID:     event.CraftID,
+		Object:      sender.Object(event.Object),
+		ObjectID:    event.ObjectID,
+		Change:      sender.Change(event.Change),
+		Snapshot:    event.Snapshot,
+	})
+}
+
+func (r *Relay) deleteDelivered(ctx context.Context) {
+	if r.retention <= 0 {
+		return
+	}
+
+	if _, err := r.store.DeleteDeliveredEvents(ctx, time.Now().Add(-r.retention)); err != nil {
+		r.logger.Error("failed to delete delivered events", slog.Any("error", err))
+	}
+}
+
+func (r *Relay) Shutdown() {
+	r.finishClosing.Wait()
+}
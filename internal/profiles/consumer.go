@@ -0,0 +1,202 @@
+package profiles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/logging"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender/kafka"
+)
+
+type Connector interface {
+	DeleteProfilePortfolios(ctx context.Context, profileID int) ([]models.TrashItem, error)
+	AnonymizeProfilePortfolios(ctx context.Context, profileID, anonymousID int) ([]models.TrashItem, error)
+}
+
+type Sender interface {
+	SendEvent(ctx context.Context, event sender.Event)
+}
+
+// Consumer reads events of the profile service in a consumer group and deletes or anonymizes portfolios of deleted profiles.
+// An event is committed only after its portfolios are handled, a failed event is retried and blocks the next events of its partition
+type Consumer struct {
+	group              sarama.ConsumerGroup
+	topic              string
+	connector          Connector
+	sender             Sender
+	anonymize          bool
+	anonymousProfileID int
+	retryMinBackoff    time.Duration
+	retryMaxBackoff    time.Duration
+	logger             *slog.Logger
+	finishClosing      sync.WaitGroup
+}
+
+func NewConsumer(cfg config.ProfileEvents, kafkaCfg config.Kafka, connector Connector, notifier Sender, logger *slog.Logger) (*Consumer, error) {
+	if cfg.Action != config.DeleteProfileAction && cfg.Action != config.AnonymizeProfileAction {
+		return nil, fmt.Errorf("failed to create profile events consumer: unknown action %q: must be %s or %s",
+			cfg.Action, config.DeleteProfileAction, config.AnonymizeProfileAction)
+	}
+	if cfg.Action == config.AnonymizeProfileAction && cfg.AnonymousProfileID <= 0 {
+		return nil, fmt.Errorf("failed to create profile events consumer: anonymous profile id must be positive, got %d", cfg.AnonymousProfileID)
+	}
+
+	saramaCfg, err := kafka.NewConfig(kafkaCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile events consumer: %w", err)
+	}
+	// deletions sent before the group was created are not skipped
+	saramaCfg.Consumer.Offsets.Initial = sarama.OffsetOldest
+	saramaCfg.Consumer.Return.Errors = true
+
+	group, err := sarama.NewConsumerGroup(kafka.Brokers(kafkaCfg), cfg.GroupID, saramaCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create profile events consumer: %w", err)
+	}
+
+	return &Consumer{
+		group:              group,
+		topic:              cfg.Topic,
+		connector:          connector,
+		sender:             notifier,
+		anonymize:          cfg.Action == config.AnonymizeProfileAction,
+		anonymousProfileID: cfg.AnonymousProfileID,
+		retryMinBackoff:    cfg.RetryMinBackoff,
+		retryMaxBackoff:    cfg.RetryMaxBackoff,
+		logger:             logger,
+		finishClosing:      sync.WaitGroup{},
+	}, nil
+}
+
+func (c *Consumer) Run(ctx context.Context) {
+	c.finishClosing.Add(1)
+
+	go func() {
+		for err := range c.group.Errors() {
+			c.logger.Error("profile events consumer error", slog.Any("error", err))
+		}
+	}()
+
+	go func() {
+		defer c.finishClosing.Done()
+
+		// Consume returns on every rebalance, so it is called again until the context is done
+		for ctx.Err() == nil {
+			err := c.group.Consume(ctx, []string{c.topic}, c)
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return
+			}
+			if err == nil {
+				continue
+			}
+
+			c.logger.Error("failed to consume profile events", slog.Any("error", err))
+			select {
+			case <-time.After(c.retryMinBackoff):
+			case <-ctx.Done():
+			}
+		}
+	}()
+}
+
+func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (c *Consumer) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+// ConsumeClaim handles events of the partition in order until the session is over
+func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if !c.handle(session.Context(), msg) {
+				return nil
+			}
+			session.MarkMessage(msg, "")
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// handle retries the event until it is handled, returns false if the context is done before.
+// The request id of the event is kept, so logs and events of its portfolios are correlated with it
+func (c *Consumer) handle(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	if requestID := header(msg, kafka.RequestIDHeader); requestID != "" {
+		ctx = logging.WithRequestID(ctx, requestID)
+	}
+
+	event, err := parseEvent(msg)
+	if err != nil {
+		// the event will never be parsed, so it is skipped
+		c.logger.WarnContext(ctx, "skipped profile event", slog.String("topic", msg.Topic), slog.Int("partition", int(msg.Partition)),
+			slog.Int64("offset", msg.Offset), slog.Any("error", err))
+		return true
+	}
+	if !event.deleted() {
+		return true
+	}
+
+	var backoff time.Duration
+	for {
+		err = c.handleDeletion(ctx, event.ProfileID)
+		if err == nil {
+			return true
+		}
+		c.logger.ErrorContext(ctx, "failed to handle profile deletion", slog.Int("profile_id", event.ProfileID), slog.Any("error", err))
+
+		backoff = min(max(backoff*2, c.retryMinBackoff), c.retryMaxBackoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// handleDeletion deletes or anonymizes portfolios of the profile and sends their deleted events,
+// the profile doesn't own them anymore in both cases
+func (c *Consumer) handleDeletion(ctx context.Context, profileID int) error {
+	var portfolios []models.TrashItem
+	var err error
+	if c.anonymize {
+		portfolios, err = c.connector.AnonymizeProfilePortfolios(ctx, profileID, c.anonymousProfileID)
+	} else {
+		portfolios, err = c.connector.DeleteProfilePortfolios(ctx, profileID)
+	}
+
+	// portfolios handled before an error are not restored, so their events are sent too
+	for _, portfolio := range portfolios {
+		c.sender.SendEvent(ctx, sender.PortfolioEvent(portfolio.ProfileID, portfolio.ID, sender.DeleteObj))
+	}
+
+	if len(portfolios) != 0 {
+		c.logger.InfoContext(ctx, "handled portfolios of deleted profile", slog.Int("profile_id", profileID), slog.Int("count", len(portfolios)))
+	}
+
+	return err
+}
+
+// Shutdown waits until the current event is handled and leaves the group
+func (c *Consumer) Shutdown() {
+	c.finishClosing.Wait()
+
+	if err := c.group.Close(); err != nil {
+		c.logger.Error("incorrect closing of profile events consumer", slog.Any("error", err))
+	}
+}
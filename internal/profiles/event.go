@@ -0,0 +1,77 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+var errNoProfileID = errors.New("no profile id")
+
+// event is the data of an event of the profile service, it is in the value of the message in binary mode
+// and in the data field of the value in structured mode of CloudEvents
+type event struct {
+	// typ is the type attribute of CloudEvents, it is empty for plain JSON messages
+	typ       string
+	ProfileID int    `json:"profile_id"`
+	Object    string `json:"object"`
+	Change    string `json:"change"`
+}
+
+// deleted reports whether the event is the deletion of the profile, by the change in the data
+// or by the type attribute, like com.tikkichest.profile.deleted
+func (e event) deleted() bool {
+	if e.Object != "" && e.Object != "profile" {
+		return false
+	}
+
+	return e.Change == string(sender.DeleteObj) || strings.HasSuffix(e.typ, "."+string(sender.DeleteObj))
+}
+
+// structuredEvent is the value of the message in structured mode
+type structuredEvent struct {
+	SpecVersion string          `json:"specversion"`
+	Type        string          `json:"type"`
+	Data        json.RawMessage `json:"data"`
+}
+
+func parseEvent(msg *sarama.ConsumerMessage) (event, error) {
+	var e event
+	data := msg.Value
+
+	var structured structuredEvent
+	if err := json.Unmarshal(msg.Value, &structured); err != nil {
+		return e, fmt.Errorf("incorrect event: %w", err)
+	}
+
+	if structured.SpecVersion != "" {
+		e.typ, data = structured.Type, structured.Data
+	} else {
+		e.typ = header(msg, "ce_type")
+	}
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		return e, fmt.Errorf("incorrect event data: %w", err)
+	}
+	if e.ProfileID == 0 && e.deleted() {
+		return e, errNoProfileID
+	}
+
+	return e, nil
+}
+
+// header returns the value of the header of the message, it is empty if there is no such header
+func header(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
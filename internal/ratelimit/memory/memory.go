@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/ratelimit"
+)
+
+type entry struct {
+	bucket ratelimit.Bucket
+	// full is the time when the bucket is full again, then it is the same as a new one
+	full time.Time
+}
+
+// Store keeps buckets in the memory of one replica of the service, buckets which are full again
+// are forgotten every sweep interval
+type Store struct {
+	mu            sync.Mutex
+	buckets       map[string]*entry
+	sweepInterval time.Duration
+	swept         time.Time
+}
+
+func NewStore(sweepInterval time.Duration) *Store {
+	return &Store{buckets: make(map[string]*entry), sweepInterval: sweepInterval, swept: time.Now()}
+}
+
+func (s *Store) Take(_ context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.swept) >= s.sweepInterval {
+		s.sweep(now)
+	}
+
+	e, ok := s.buckets[key]
+	if !ok {
+		e = &entry{}
+		s.buckets[key] = e
+	}
+
+	result := e.bucket.Take(limit, now)
+	e.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+func (s *Store) sweep(now time.Time) {
+	for key, e := range s.buckets {
+		if !e.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+	s.swept = now
+}
@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/ratelimit"
+)
+
+func TestStoreTake(t *testing.T) {
+	limit := ratelimit.Limit{Burst: 2, Window: time.Hour}
+
+	// every step takes a token from the bucket of the key of the same store
+	steps := []struct {
+		key         string
+		wantAllowed bool
+		wantLeft    int
+	}{
+		{key: "a", wantAllowed: true, wantLeft: 1},
+		{key: "a", wantAllowed: true, wantLeft: 0},
+		{key: "a", wantAllowed: false, wantLeft: 0},
+		{key: "b", wantAllowed: true, wantLeft: 1},
+		{key: "a", wantAllowed: false, wantLeft: 0},
+	}
+
+	store := NewStore(time.Hour)
+	for i, step := range steps {
+		result, err := store.Take(context.Background(), step.key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Allowed != step.wantAllowed || result.Remaining != step.wantLeft {
+			t.Fatalf("step %d, key %q: got %+v, want allowed %t and remaining %d", i, step.key, result, step.wantAllowed, step.wantLeft)
+		}
+	}
+}
+
+func TestStoreSweep(t *testing.T) {
+	store := NewStore(time.Hour)
+	ctx := context.Background()
+
+	if _, err := store.Take(ctx, "full", ratelimit.Limit{Burst: 1, Window: time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Take(ctx, "used", ratelimit.Limit{Burst: 1, Window: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		swept    time.Duration
+		wantKeys []string
+	}{
+		{name: "before the sweep interval", swept: 0, wantKeys: []string{"full", "used", "new"}},
+		{name: "after the sweep interval", swept: time.Hour, wantKeys: []string{"used", "new"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			time.Sleep(2 * time.Millisecond)
+			store.mu.Lock()
+			store.swept = time.Now().Add(-tt.swept)
+			store.mu.Unlock()
+
+			if _, err := store.Take(ctx, "new", ratelimit.Limit{Burst: 1, Window: time.Hour}); err != nil {
+				t.Fatal(err)
+			}
+
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			if len(store.buckets) != len(tt.wantKeys) {
+				t.Fatalf("got %d buckets, want %v", len(store.buckets), tt.wantKeys)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := store.buckets[key]; !ok {
+					t.Errorf("bucket %q is swept", key)
+				}
+			}
+		})
+	}
+}
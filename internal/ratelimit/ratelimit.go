@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit is the token bucket, Burst requests are allowed at once and the bucket is refilled by Burst tokens per Window
+type Limit struct {
+	Burst  int
+	Window time.Duration
+}
+
+// Result is the state of the bucket after the request
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token, it is zero for allowed requests
+	RetryAfter time.Duration
+}
+
+// Store keeps buckets of clients by keys, a store shared by replicas of the service, like redis,
+// lets them limit clients together
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Bucket is the state of one bucket, stores keep it and take tokens by Take
+type Bucket struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take refills the bucket for the time since the last request and takes one token if there is one,
+// a new bucket (zero Updated) is full
+func (b *Bucket) Take(limit Limit, now time.Time) Result {
+	burst := float64(limit.Burst)
+	perToken := limit.Window / time.Duration(limit.Burst)
+
+	if b.Updated.IsZero() {
+		b.Tokens = burst
+	} else if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		b.Tokens = math.Min(burst, b.Tokens+float64(elapsed)/float64(perToken))
+	}
+	b.Updated = now
+
+	result := Result{Allowed: b.Tokens >= 1}
+	if result.Allowed {
+		b.Tokens--
+	} else {
+		result.RetryAfter = time.Duration((1 - b.Tokens) * float64(perToken))
+	}
+	result.Remaining = int(b.Tokens)
+	result.Reset = time.Duration((burst - b.Tokens) * float64(perToken))
+
+	return result
+}
@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketTake(t *testing.T) {
+	// a token is refilled every second
+	limit := Limit{Burst: 2, Window: 2 * time.Second}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// every step takes a token from the same bucket at the time after the start
+	steps := []struct {
+		name  string
+		after time.Duration
+		want  Result
+	}{
+		{name: "new bucket is full", want: Result{Allowed: true, Remaining: 1, Reset: time.Second}},
+		{name: "last token", want: Result{Allowed: true, Remaining: 0, Reset: 2 * time.Second}},
+		{name: "empty bucket", want: Result{Allowed: false, Remaining: 0, Reset: 2 * time.Second, RetryAfter: time.Second}},
+		{name: "half of token refilled", after: 500 * time.Millisecond, want: Result{Allowed: false, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{name: "token and a half refilled", after: 1500 * time.Millisecond, want: Result{Allowed: true, Remaining: 0, Reset: 1500 * time.Millisecond}},
+		{name: "clock goes back", after: time.Second, want: Result{Allowed: false, Remaining: 0, Reset: 1500 * time.Millisecond, RetryAfter: 500 * time.Millisecond}},
+		{name: "refill is capped by burst", after: time.Minute, want: Result{Allowed: true, Remaining: 1, Reset: time.Second}},
+	}
+
+	var bucket Bucket
+	for _, step := range steps {
+		got := bucket.Take(limit, start.Add(step.after))
+		if got != step.want {
+			t.Fatalf("%s: got %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
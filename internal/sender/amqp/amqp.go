@@ -0,0 +1,141 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	amqp091 "github.com/rabbitmq/amqp091-go"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// closeTimeout limits closing of the connection on shutdown
+const closeTimeout = 5 * time.Second
+
+var errNotConfirmed = errors.New("event is not confirmed by the broker")
+
+// Publisher publishes events to the exchange in confirm mode, a closed connection is opened again by the next event
+type Publisher struct {
+	url          string
+	exchange     string
+	exchangeType string
+	encoder      *sender.Encoder
+	mu           sync.Mutex
+	conn         *amqp091.Connection
+	channel      *amqp091.Channel
+}
+
+func NewPublisher(cfg config.AMQP, encoder *sender.Encoder) (*Publisher, error) {
+	p := &Publisher{
+		url:          cfg.URL,
+		exchange:     cfg.Exchange,
+		exchangeType: cfg.ExchangeType,
+		encoder:      encoder,
+	}
+
+	if _, err := p.openChannel(); err != nil {
+		return nil, fmt.Errorf("failed to create amqp publisher: %w", err)
+	}
+
+	return p, nil
+}
+
+// openChannel returns the channel, it connects and declares the exchange if the channel is closed, p.mu must be held
+// by callers other than the constructor
+func (p *Publisher) openChannel() (*amqp091.Channel, error) {
+	if p.channel != nil && !p.channel.IsClosed() {
+		return p.channel, nil
+	}
+
+	if p.conn == nil || p.conn.IsClosed() {
+		conn, err := amqp091.Dial(p.url)
+		if err != nil {
+			return nil, fmt.Errorf("connection error: %w", err)
+		}
+		p.conn = conn
+	}
+
+	channel, err := p.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("channel error: %w", err)
+	}
+
+	if err = channel.Confirm(false); err != nil {
+		_ = channel.Close()
+		return nil, fmt.Errorf("channel error: %w", err)
+	}
+
+	if err = channel.ExchangeDeclare(p.exchange, p.exchangeType, true, false, false, false, nil); err != nil {
+		return nil, fmt.Errorf("failed to declare exchange %s: %w", p.exchange, err)
+	}
+
+	p.channel = channel
+	return channel, nil
+}
+
+// Run does nothing, connections are opened by Send
+func (p *Publisher) Run() {}
+
+// Send publishes the persistent message with routing key {object}.{change} and waits for the confirmation of the broker,
+// in binary mode attributes of the event are sent as headers with cloudEvents: prefix
+func (p *Publisher) Send(ctx context.Context, event sender.Event) error {
+	value, attributes, err := p.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	headers := amqp091.Table{}
+	for name, attribute := range attributes {
+		headers["cloudEvents:"+name] = attribute
+	}
+
+	msg := amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  p.encoder.ContentType(),
+		DeliveryMode: amqp091.Persistent,
+		MessageId:    event.ID,
+		Timestamp:    event.Time,
+		Body:         value,
+	}
+
+	confirmation, err := p.publish(ctx, fmt.Sprintf("%s.%s", event.Object, event.Change), msg)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("failed to send event: %w", errNotConfirmed)
+	}
+
+	return nil
+}
+
+func (p *Publisher) publish(ctx context.Context, key string, msg amqp091.Publishing) (*amqp091.DeferredConfirmation, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	channel, err := p.openChannel()
+	if err != nil {
+		return nil, err
+	}
+
+	return channel.PublishWithDeferredConfirmWithContext(ctx, p.exchange, key, false, false, msg)
+}
+
+// Shutdown closes the connection
+func (p *Publisher) Shutdown() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.conn != nil {
+		_ = p.conn.CloseDeadline(time.Now().Add(closeTimeout))
+	}
+}
@@ -0,0 +1,158 @@
+package sender
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	// StructuredContentType is the content type of events in structured mode, in binary mode it is DataContentType
+	StructuredContentType = "application/cloudevents+json"
+	DataContentType       = "application/json"
+)
+
+// CloudEvent is the event in CloudEvents 1.0 format, schemaversion and partitionkey are extension attributes
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	SchemaVersion   string          `json:"schemaversion"`
+	PartitionKey    string          `json:"partitionkey"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// Attributes returns context attributes of the event, they are sent as headers in binary mode
+func (ce CloudEvent) Attributes() map[string]string {
+	return map[string]string{
+		"specversion":   ce.SpecVersion,
+		"id":            ce.ID,
+		"source":        ce.Source,
+		"type":          ce.Type,
+		"subject":       ce.Subject,
+		"time":          ce.Time.Format(time.RFC3339Nano),
+		"schemaversion": ce.SchemaVersion,
+		"partitionkey":  ce.PartitionKey,
+	}
+}
+
+// Encoder makes CloudEvents of events
+type Encoder struct {
+	format     string
+	source     string
+	typePrefix string
+	snapshots  bool
+}
+
+func NewEncoder(cfg config.Events) (*Encoder, error) {
+	if cfg.Format != config.BinaryEventsFormat && cfg.Format != config.StructuredEventsFormat {
+		return nil, fmt.Errorf("unknown events format %q: must be %s or %s", cfg.Format, config.BinaryEventsFormat, config.StructuredEventsFormat)
+	}
+
+	return &Encoder{format: cfg.Format, source: cfg.Source, typePrefix: cfg.TypePrefix, snapshots: cfg.Snapshots}, nil
+}
+
+// Structured reports whether events are sent in structured mode, otherwise they are sent in binary mode
+func (e *Encoder) Structured() bool {
+	return e.format == config.StructuredEventsFormat
+}
+
+func (e *Encoder) CloudEvent(event Event) (CloudEvent, error) {
+	if !e.snapshots {
+		event.Snapshot = nil
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return CloudEvent{}, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              event.ID,
+		Source:          e.source,
+		Type:            strings.Join([]string{e.typePrefix, string(event.Object), string(event.Change)}, "."),
+		Subject:         subject(event),
+		Time:            event.Time,
+		DataContentType: DataContentType,
+		SchemaVersion:   SchemaVersion,
+		PartitionKey:    event.Key(),
+		Data:            data,
+	}, nil
+}
+
+// Encode returns the value of the message and its attributes, in structured mode the value is the whole event
+// and attributes are empty, in binary mode the value is the data of the event
+func (e *Encoder) Encode(event Event) (value []byte, attributes map[string]string, err error) {
+	ce, err := e.CloudEvent(event)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !e.Structured() {
+		return ce.Data, ce.Attributes(), nil
+	}
+
+	value, err = e.EncodeStructured(event)
+	return value, nil, err
+}
+
+// EncodeStructured returns the whole event whatever the format is, it is used by transports without headers
+func (e *Encoder) EncodeStructured(event Event) ([]byte, error) {
+	ce, err := e.CloudEvent(event)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(ce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return value, nil
+}
+
+// ContentType is the content type of values made by Encode
+func (e *Encoder) ContentType() string {
+	if e.Structured() {
+		return StructuredContentType
+	}
+
+	return DataContentType
+}
+
+// collections are names of objects in paths of the api
+var collections = map[Object]string{
+	Portfolio: "portfolios",
+	Craft:     "crafts",
+	Content:   "contents",
+	Category:  "categories",
+	Tag:       "tags",
+	CraftTag:  "tags",
+}
+
+// subject is the path of the object in the api, parents which are not known are skipped,
+// categories and tags are not owned by profiles
+func subject(event Event) string {
+	var parts []string
+	if event.Object != Category && event.Object != Tag {
+		parts = append(parts, "profiles", strconv.Itoa(event.ProfileID))
+	}
+	if event.PortfolioID != 0 {
+		parts = append(parts, "portfolios", strconv.Itoa(event.PortfolioID))
+	}
+	if event.CraftID != 0 {
+		parts = append(parts, "crafts", strconv.Itoa(event.CraftID))
+	}
+
+	return strings.Join(append(parts, collections[event.Object], strconv.Itoa(event.ObjectID)), "/")
+}
@@ -0,0 +1,52 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Transport is a Sender with a lifecycle, Run starts its background work and Shutdown flushes and closes it
+type Transport interface {
+	Sender
+	Run()
+	Shutdown()
+}
+
+// Fanout sends every event to all transports at once, the event is sent only if all of them accepted it.
+// An event which failed on one transport is sent to all of them again, so the others may get duplicates
+type Fanout struct {
+	transports []Transport
+}
+
+func NewFanout(transports ...Transport) *Fanout {
+	return &Fanout{transports: transports}
+}
+
+func (f *Fanout) Run() {
+	for _, transport := range f.transports {
+		transport.Run()
+	}
+}
+
+func (f *Fanout) Send(ctx context.Context, event Event) error {
+	errs := make([]error, len(f.transports))
+
+	var wg sync.WaitGroup
+	for i, transport := range f.transports {
+		wg.Add(1)
+		go func(i int, transport Transport) {
+			defer wg.Done()
+			errs[i] = transport.Send(ctx, event)
+		}(i, transport)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func (f *Fanout) Shutdown() {
+	for _, transport := range f.transports {
+		transport.Shutdown()
+	}
+}
@@ -0,0 +1,69 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// Stdout is the path of the standard output
+const Stdout = "-"
+
+// Sink writes events as structured CloudEvents, one JSON object per line, whatever the format of events is
+type Sink struct {
+	mu      sync.Mutex
+	out     io.Writer
+	file    *os.File
+	encoder *sender.Encoder
+}
+
+// NewSink opens the file for appending, it is created if it doesn't exist
+func NewSink(cfg config.EventsFile, encoder *sender.Encoder) (*Sink, error) {
+	if cfg.Path == Stdout || cfg.Path == "" {
+		return &Sink{out: os.Stdout, encoder: encoder}, nil
+	}
+
+	file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create events file sink: %w", err)
+	}
+
+	return &Sink{out: file, file: file, encoder: encoder}, nil
+}
+
+// Run does nothing, events are written by Send
+func (s *Sink) Run() {}
+
+func (s *Sink) Send(_ context.Context, event sender.Event) error {
+	value, err := s.encoder.EncodeStructured(event)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err = s.out.Write(append(value, '\n')); err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown syncs and closes the file, stdout is kept open
+func (s *Sink) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return
+	}
+
+	_ = s.file.Sync()
+	_ = s.file.Close()
+}
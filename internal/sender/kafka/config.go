@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/IBM/sarama"
+	"github.com/xdg-go/scram"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+)
+
+var requiredAcks = map[string]sarama.RequiredAcks{
+	"none":   sarama.NoResponse,
+	"leader": sarama.WaitForLocal,
+	"all":    sarama.WaitForAll,
+}
+
+// Brokers returns KAFKA_BROKERS or KAFKA_HOST:KAFKA_PORT if the list is not set
+func Brokers(cfg config.Kafka) []string {
+	if len(cfg.Brokers) != 0 {
+		return cfg.Brokers
+	}
+
+	return []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}
+}
+
+// NewConfig configures the producer and the connection, consumers of the service use the same connection settings
+func NewConfig(cfg config.Kafka) (*sarama.Config, error) {
+	saramaCfg := sarama.NewConfig()
+	saramaCfg.ClientID = cfg.ClientID
+
+	version, err := sarama.ParseKafkaVersion(cfg.Version)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect kafka version: %w", err)
+	}
+	saramaCfg.Version = version
+
+	acks, ok := requiredAcks[cfg.RequiredAcks]
+	if !ok {
+		return nil, fmt.Errorf("unknown required acks %q: must be none, leader or all", cfg.RequiredAcks)
+	}
+	saramaCfg.Producer.RequiredAcks = acks
+
+	if err = saramaCfg.Producer.Compression.UnmarshalText([]byte(cfg.Compression)); err != nil {
+		return nil, fmt.Errorf("unknown compression %q: must be none, gzip, snappy, lz4 or zstd", cfg.Compression)
+	}
+
+	saramaCfg.Producer.Retry.Max = cfg.RetryMax
+	saramaCfg.Producer.Retry.Backoff = cfg.RetryBackoff
+
+	// idempotence keeps order of retried messages only if there is one request in flight to a broker
+	saramaCfg.Producer.Idempotent = cfg.Idempotent
+	if cfg.Idempotent {
+		saramaCfg.Net.MaxOpenRequests = 1
+	}
+
+	// successes are reported back to senders waiting for delivery
+	saramaCfg.Producer.Return.Successes = true
+
+	if cfg.TLS.Enabled {
+		tlsCfg, err := newTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		saramaCfg.Net.TLS.Enable = true
+		saramaCfg.Net.TLS.Config = tlsCfg
+	}
+
+	if cfg.SASL.Mechanism != "" {
+		if err = setSASL(saramaCfg, cfg.SASL); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = saramaCfg.Validate(); err != nil {
+		return nil, fmt.Errorf("incorrect kafka config: %w", err)
+	}
+
+	return saramaCfg, nil
+}
+
+func newTLSConfig(cfg config.KafkaTLS) (*tls.Config, error) {
+	tlsCfg := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+		}
+
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("failed to read kafka CA file: no certificates in %s", cfg.CAFile)
+		}
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kafka client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+func setSASL(saramaCfg *sarama.Config, cfg config.KafkaSASL) error {
+	saramaCfg.Net.SASL.Enable = true
+	saramaCfg.Net.SASL.User = cfg.User
+	saramaCfg.Net.SASL.Password = cfg.Password
+
+	switch cfg.Mechanism {
+	case sarama.SASLTypePlaintext:
+		saramaCfg.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+	case sarama.SASLTypeSCRAMSHA256:
+		saramaCfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+		saramaCfg.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: scram.SHA256} }
+	case sarama.SASLTypeSCRAMSHA512:
+		saramaCfg.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+		saramaCfg.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: scram.SHA512} }
+	default:
+		return fmt.Errorf("unknown sasl mechanism %q: must be %s, %s or %s", cfg.Mechanism, sarama.SASLTypePlaintext, sarama.SASLTypeSCRAMSHA256, sarama.SASLTypeSCRAMSHA512)
+	}
+
+	return nil
+}
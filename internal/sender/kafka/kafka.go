@@ -2,26 +2,33 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
-	"log"
-	"strconv"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 
 	"github.com/IBM/sarama"
 
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
 )
 
 type ProducerManager struct {
 	producer      sarama.AsyncProducer
 	topic         string
+	catalogTopic  string
+	encoder       *sender.Encoder
+	logger        *slog.Logger
 	finishClosing sync.WaitGroup
 }
 
-func NewProducerManager(cfg config.Kafka) (*ProducerManager, error) {
-	prod, err := sarama.NewAsyncProducer([]string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}, nil)
+func NewProducerManager(cfg config.Kafka, encoder *sender.Encoder, logger *slog.Logger) (*ProducerManager, error) {
+	saramaCfg, err := NewConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create producer manager: %w", err)
+	}
+
+	prod, err := sarama.NewAsyncProducer(Brokers(cfg), saramaCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create producer manager: %w", err)
 	}
@@ -29,43 +36,124 @@ func NewProducerManager(cfg config.Kafka) (*ProducerManager, error) {
 	return &ProducerManager{
 		producer:      prod,
 		topic:         cfg.Topic,
+		catalogTopic:  cfg.CatalogTopic,
+		encoder:       encoder,
+		logger:        logger,
 		finishClosing: sync.WaitGroup{},
 	}, nil
 }
 
-func (pm *ProducerManager) Run(ctx context.Context) {
+// Run reports results of sent messages until the producer is shut down
+func (pm *ProducerManager) Run() {
 	pm.finishClosing.Add(1)
 
 	go func() {
 		defer pm.finishClosing.Done()
-		for {
+
+		successes, errs := pm.producer.Successes(), pm.producer.Errors()
+		for successes != nil || errs != nil {
 			select {
-			case err := <-pm.producer.Errors():
-				log.Println(err) // TODO: logger
-			case <-ctx.Done():
-				return
+			case msg, ok := <-successes:
+				if !ok {
+					successes = nil
+					continue
+				}
+				pm.report(msg, nil)
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+				pm.report(err.Msg, err.Err)
 			}
 		}
 	}()
 }
 
-func (pm *ProducerManager) Send(id int, event sender.Event) {
-	eventJSON, err := json.Marshal(event)
-	if err != nil {
-		log.Println(err) //TODO logger
+// delivery passes the result of the message to its sender, claimed is set by the first of them:
+// the report of the result or the sender which stops waiting
+type delivery struct {
+	result  chan error
+	claimed atomic.Bool
+}
+
+// report passes the result to the sender of the message, failures of messages abandoned by their senders
+// are logged, because nobody else knows about them
+func (pm *ProducerManager) report(msg *sarama.ProducerMessage, err error) {
+	d, ok := msg.Metadata.(*delivery)
+	if !ok {
+		return
+	}
+
+	if d.claimed.CompareAndSwap(false, true) {
+		d.result <- err
 		return
 	}
+	if err != nil {
+		pm.logger.Error("failed to send abandoned event", slog.String("topic", msg.Topic), slog.Any("error", err))
+	}
+}
+
+// RequestIDHeader is the header with the id of the request which made the change
+const RequestIDHeader = "x-request-id"
+
+// Send waits until the message is acknowledged by kafka or the context is done,
+// in binary mode attributes of the event are sent as headers with ce_ prefix
+func (pm *ProducerManager) Send(ctx context.Context, event sender.Event) error {
+	value, attributes, err := pm.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
 
+	headers := []sarama.RecordHeader{{Key: []byte("content-type"), Value: []byte(pm.encoder.ContentType())}}
+	for name, attribute := range attributes {
+		headers = append(headers, sarama.RecordHeader{Key: []byte("ce_" + name), Value: []byte(attribute)})
+	}
+	if event.RequestID != "" {
+		headers = append(headers, sarama.RecordHeader{Key: []byte(RequestIDHeader), Value: []byte(event.RequestID)})
+	}
+
+	delivered := &delivery{result: make(chan error, 1)}
 	message := sarama.ProducerMessage{
-		Topic: pm.topic,
-		Key:   sarama.StringEncoder(strconv.Itoa(id)),
-		Value: sarama.ByteEncoder(eventJSON)}
+		Topic:    pm.topicOf(event),
+		Key:      sarama.StringEncoder(event.Key()),
+		Value:    sarama.ByteEncoder(value),
+		Headers:  headers,
+		Metadata: delivered}
+
+	select {
+	case pm.producer.Input() <- &message:
+	case <-ctx.Done():
+		return fmt.Errorf("failed to send event: %w", ctx.Err())
+	}
+
+	select {
+	case err = <-delivered.result:
+	case <-ctx.Done():
+		if delivered.claimed.CompareAndSwap(false, true) {
+			return fmt.Errorf("failed to send event: %w", ctx.Err())
+		}
+		// the result is already reported
+		err = <-delivered.result
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	return nil
+}
+
+// topicOf returns the catalog topic for events of the catalog if it is set
+func (pm *ProducerManager) topicOf(event sender.Event) string {
+	if event.Object.Catalog() && pm.catalogTopic != "" {
+		return pm.catalogTopic
+	}
 
-	pm.producer.Input() <- &message
+	return pm.topic
 }
 
-func (pm *ProducerManager) Shutdown() error {
-	err := pm.producer.Close()
+// Shutdown flushes buffered messages and waits until their results are reported
+func (pm *ProducerManager) Shutdown() {
+	pm.producer.AsyncClose()
 	pm.finishClosing.Wait()
-	return err
 }
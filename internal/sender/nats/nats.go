@@ -0,0 +1,113 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	natsgo "github.com/nats-io/nats.go"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// Publisher publishes events to JetStream, the id of the event is the message id,
+// so the stream drops duplicates within its deduplication window
+type Publisher struct {
+	conn    *natsgo.Conn
+	js      natsgo.JetStreamContext
+	subject string
+	encoder *sender.Encoder
+	closed  chan struct{}
+}
+
+func NewPublisher(cfg config.NATS, encoder *sender.Encoder) (*Publisher, error) {
+	closed := make(chan struct{})
+	options := []natsgo.Option{
+		natsgo.Name("portfolio-service"),
+		natsgo.MaxReconnects(-1),
+		natsgo.ClosedHandler(func(*natsgo.Conn) { close(closed) }),
+	}
+	switch {
+	case cfg.CredentialsFile != "":
+		options = append(options, natsgo.UserCredentials(cfg.CredentialsFile))
+	case cfg.User != "":
+		options = append(options, natsgo.UserInfo(cfg.User, cfg.Password))
+	}
+
+	conn, err := natsgo.Connect(cfg.URL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create nats publisher: %w", err)
+	}
+
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create nats publisher: %w", err)
+	}
+
+	if cfg.Stream != "" {
+		if err = addStream(js, cfg.Stream, cfg.Subject); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to create nats publisher: %w", err)
+		}
+	}
+
+	return &Publisher{
+		conn:    conn,
+		js:      js,
+		subject: cfg.Subject,
+		encoder: encoder,
+		closed:  closed,
+	}, nil
+}
+
+// addStream creates the stream of events if it doesn't exist, an existing stream is kept as it is
+func addStream(js natsgo.JetStreamContext, stream, subject string) error {
+	_, err := js.StreamInfo(stream)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, natsgo.ErrStreamNotFound) {
+		return fmt.Errorf("stream %s: %w", stream, err)
+	}
+
+	if _, err = js.AddStream(&natsgo.StreamConfig{Name: stream, Subjects: []string{subject + ".>"}}); err != nil {
+		return fmt.Errorf("failed to add stream %s: %w", stream, err)
+	}
+
+	return nil
+}
+
+// Run does nothing, the connection is kept by the nats client
+func (p *Publisher) Run() {}
+
+// Send publishes the event to {subject}.{object}.{change} and waits for the acknowledgement of the stream,
+// in binary mode attributes of the event are sent as headers with ce- prefix
+func (p *Publisher) Send(ctx context.Context, event sender.Event) error {
+	value, attributes, err := p.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	msg := natsgo.NewMsg(fmt.Sprintf("%s.%s.%s", p.subject, event.Object, event.Change))
+	msg.Data = value
+	msg.Header.Set("content-type", p.encoder.ContentType())
+	for name, attribute := range attributes {
+		msg.Header.Set("ce-"+name, attribute)
+	}
+
+	if _, err = p.js.PublishMsg(msg, natsgo.Context(ctx), natsgo.MsgId(event.ID)); err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown flushes published messages and waits until the connection is closed
+func (p *Publisher) Shutdown() {
+	if err := p.conn.Drain(); err != nil {
+		p.conn.Close()
+	}
+	<-p.closed
+}
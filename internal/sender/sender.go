@@ -1,46 +1,132 @@
 package sender
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/logging"
+)
+
+// Sender sends the event keyed by Event.Key and returns when the broker has accepted it
 type Sender interface {
-	Send(id int, event Event)
+	Send(ctx context.Context, event Event) error
+}
+
+// Discard drops events, it is used when events are sent by other means, like the outbox relay
+type Discard struct{}
+
+func (Discard) Send(context.Context, Event) error {
+	return nil
 }
 
 type Manager struct {
 	sender Sender
+	logger *slog.Logger
 }
 
-// Object can be Portfolio, Craft or Content
+// Object can be Portfolio, Craft, Content, Category, Tag or CraftTag
 type Object string
 
 const (
 	Portfolio Object = "portfolio"
 	Craft     Object = "craft"
 	Content   Object = "content"
+	Category  Object = "category"
+	Tag       Object = "tag"
+	// CraftTag is the link of the tag to the craft, its id is the id of the tag
+	CraftTag Object = "craft_tag"
 )
 
-// Change can be CreateObj, UpdateObj or DeleteObj
+// Catalog reports whether events of the object belong to the catalog of categories and tags, they are sent to the catalog topic
+func (o Object) Catalog() bool {
+	return o == Category || o == Tag || o == CraftTag
+}
+
+// Change can be CreateObj, UpdateObj, DeleteObj, TrashObj, RestoreObj, PurgeObj, AttachObj or DetachObj
 type Change string
 
 const (
-	CreateObj Change = "created"
-	UpdateObj Change = "changed"
-	DeleteObj Change = "deleted"
+	CreateObj  Change = "created"
+	UpdateObj  Change = "changed"
+	DeleteObj  Change = "deleted"
+	TrashObj   Change = "trashed"
+	RestoreObj Change = "restored"
+	PurgeObj   Change = "purged"
+	// AttachObj and DetachObj are changes of CraftTag
+	AttachObj Change = "attached"
+	DetachObj Change = "detached"
 )
 
+// SchemaVersion is the version of the event data, it is increased on incompatible changes of Event
+const SchemaVersion = "2"
+
+// Event is the data of the event, ID and Time are attributes of its envelope. ID is kept on redeliveries,
+// so consumers can use it to skip duplicates. Parents which are not known are zero, categories and tags have no profile.
+// RequestID is the id of the request which made the change, transports with headers send it as a header
 type Event struct {
-	Object   Object `json:"object"`
-	ObjectID int    `json:"object_id"`
-	Change   Change `json:"change"`
+	ID          string    `json:"-"`
+	Time        time.Time `json:"-"`
+	RequestID   string    `json:"-"`
+	ProfileID   int       `json:"profile_id"`
+	PortfolioID int       `json:"portfolio_id,omitempty"`
+	CraftID     int       `json:"craft_id,omitempty"`
+	Object      Object    `json:"object"`
+	ObjectID    int       `json:"object_id"`
+	Change      Change    `json:"change"`
+	// Snapshot is the object after the change, it is set for created and changed events saved to the outbox
+	Snapshot json.RawMessage `json:"snapshot,omitempty"`
+}
+
+func PortfolioEvent(profileID, portfolioID int, change Change) Event {
+	return Event{ProfileID: profileID, Object: Portfolio, ObjectID: portfolioID, Change: change}
+}
+
+func CraftEvent(profileID, portfolioID, craftID int, change Change) Event {
+	return Event{ProfileID: profileID, PortfolioID: portfolioID, Object: Craft, ObjectID: craftID, Change: change}
 }
 
-func NewManager(sender Sender) *Manager {
-	return &Manager{sender: sender}
+func ContentEvent(profileID, portfolioID, craftID, contentID int, change Change) Event {
+	return Event{ProfileID: profileID, PortfolioID: portfolioID, CraftID: craftID, Object: Content, ObjectID: contentID, Change: change}
 }
 
-func (n *Manager) SendEvent(userID int, obj Object, objID int, change Change) {
-	event := Event{
-		Object:   obj,
-		ObjectID: objID,
-		Change:   change,
+func CategoryEvent(categoryID int, change Change) Event {
+	return Event{Object: Category, ObjectID: categoryID, Change: change}
+}
+
+func TagEvent(tagID int, change Change) Event {
+	return Event{Object: Tag, ObjectID: tagID, Change: change}
+}
+
+func CraftTagEvent(profileID, portfolioID, craftID, tagID int, change Change) Event {
+	return Event{ProfileID: profileID, PortfolioID: portfolioID, CraftID: craftID, Object: CraftTag, ObjectID: tagID, Change: change}
+}
+
+// Key is the partition key of the event, events of an object owned by a profile are keyed by the profile,
+// so they keep their order, categories and tags are keyed by themselves, like category-1
+func (e Event) Key() string {
+	if e.Object == Category || e.Object == Tag {
+		return fmt.Sprintf("%s-%d", e.Object, e.ObjectID)
+	}
+
+	return strconv.Itoa(e.ProfileID)
+}
+
+func NewManager(sender Sender, logger *slog.Logger) *Manager {
+	return &Manager{sender: sender, logger: logger}
+}
+
+// SendEvent sets a new id, the current time and the request id of the context of the event and sends it,
+// the event is sent even if the context is done, like the context of the finished request
+func (n *Manager) SendEvent(ctx context.Context, event Event) {
+	event.ID, event.Time, event.RequestID = uuid.NewString(), time.Now().UTC(), logging.RequestID(ctx)
+
+	if err := n.sender.Send(context.WithoutCancel(ctx), event); err != nil {
+		n.logger.ErrorContext(ctx, "failed to send event", slog.String("event_id", event.ID), slog.Any("error", err))
 	}
-	n.sender.Send(userID, event)
 }
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// Headers of the signature, the signature is hex encoded HMAC-SHA256 of "{timestamp}.{body}" with sha256= prefix,
+// receivers should reject requests with old timestamps
+const (
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+var errNoURL = errors.New("webhook url is not set")
+
+// Poster posts events to the url, an event is sent when the receiver responds with 2xx status
+type Poster struct {
+	url     string
+	secret  []byte
+	client  *http.Client
+	encoder *sender.Encoder
+}
+
+func NewPoster(cfg config.Webhook, encoder *sender.Encoder) (*Poster, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("failed to create webhook poster: %w", errNoURL)
+	}
+
+	return &Poster{
+		url:     cfg.URL,
+		secret:  []byte(cfg.Secret),
+		client:  &http.Client{Timeout: cfg.Timeout},
+		encoder: encoder,
+	}, nil
+}
+
+// Run does nothing, requests are made by Send
+func (p *Poster) Run() {}
+
+// Send posts the event, in binary mode attributes of the event are sent as headers with ce- prefix
+func (p *Poster) Send(ctx context.Context, event sender.Event) error {
+	value, attributes, err := p.encoder.Encode(event)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(value))
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	req.Header.Set("Content-Type", p.encoder.ContentType())
+	for name, attribute := range attributes {
+		req.Header.Set("ce-"+name, attribute)
+	}
+	if len(p.secret) != 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(TimestampHeader, timestamp)
+		req.Header.Set(SignatureHeader, "sha256="+p.sign(timestamp, value))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("failed to send event: webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (p *Poster) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Shutdown closes idle connections
+func (p *Poster) Shutdown() {
+	p.client.CloseIdleConnections()
+}
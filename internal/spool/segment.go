@@ -0,0 +1,155 @@
+package spool
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+const (
+	segmentExt = ".seg"
+	// headerSize is the length and the checksum of the record
+	headerSize = 8
+)
+
+var errCorrupted = errors.New("corrupted record")
+
+// record is the spooled event, the id, the time and the request id are kept outside of the event, because they are not in its json
+type record struct {
+	ID        string       `json:"id"`
+	Time      time.Time    `json:"time"`
+	RequestID string       `json:"request_id,omitempty"`
+	Event     sender.Event `json:"event"`
+}
+
+func newRecord(event sender.Event) record {
+	return record{ID: event.ID, Time: event.Time, RequestID: event.RequestID, Event: event}
+}
+
+func (r record) event() sender.Event {
+	r.Event.ID, r.Event.Time, r.Event.RequestID = r.ID, r.Time, r.RequestID
+	return r.Event
+}
+
+// encode returns the record with the header: big endian length and crc32 of the payload
+func (r record) encode() ([]byte, error) {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode record: %w", err)
+	}
+
+	data := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(data[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(data[4:8], crc32.ChecksumIEEE(payload))
+	copy(data[headerSize:], payload)
+
+	return data, nil
+}
+
+// readRecord reads the record at the offset and returns it with the offset of the next record,
+// a record cut by a crash or damaged on the disk is errCorrupted
+func readRecord(file io.ReaderAt, offset int64) (record, int64, error) {
+	var r record
+
+	header := make([]byte, headerSize)
+	if _, err := file.ReadAt(header, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return r, 0, errCorrupted
+		}
+		return r, 0, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := file.ReadAt(payload, offset+headerSize); err != nil {
+		if errors.Is(err, io.EOF) {
+			return r, 0, errCorrupted
+		}
+		return r, 0, err
+	}
+
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return r, 0, errCorrupted
+	}
+	if err := json.Unmarshal(payload, &r); err != nil {
+		return r, 0, errCorrupted
+	}
+
+	return r, offset + headerSize + int64(len(payload)), nil
+}
+
+// segment is an append-only file of records, segments are numbered in order of creation
+type segment struct {
+	number uint64
+	path   string
+	size   int64
+}
+
+func segmentName(number uint64) string {
+	return fmt.Sprintf("%020d%s", number, segmentExt)
+}
+
+// listSegments returns segments of the directory in order
+func listSegments(dir string) ([]*segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []*segment
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+
+		number, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, &segment{number: number, path: filepath.Join(dir, name), size: info.Size()})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].number < segments[j].number })
+
+	return segments, nil
+}
+
+// countRecords counts valid records of the segment from the offset and returns the offset after the last one
+func countRecords(seg *segment, offset int64) (int, int64, error) {
+	file, err := os.Open(seg.path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	count := 0
+	for offset < seg.size {
+		_, next, err := readRecord(file, offset)
+		if errors.Is(err, errCorrupted) {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		count, offset = count+1, next
+	}
+
+	return count, offset, nil
+}
@@ -0,0 +1,493 @@
+package spool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// positionFile keeps the segment and the offset of the next record to replay
+const positionFile = "position"
+
+var (
+	errFull   = errors.New("spool is full")
+	errClosed = errors.New("spool is closed")
+)
+
+// metrics are published by expvar as "spool": depth and size_bytes are the current number of spooled events and size of segments,
+// spooled, replayed and dropped are counters of events since the start
+var (
+	metrics         = expvar.NewMap("spool")
+	depthMetric     = new(expvar.Int)
+	sizeBytesMetric = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("depth", depthMetric)
+	metrics.Set("size_bytes", sizeBytesMetric)
+}
+
+type position struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// Spool sends events by the next transport and writes events which failed to be sent to segment files in the directory.
+// Spooled events are replayed in order in the background and on the next start, while there are spooled events
+// new ones are spooled too, so the order of events is kept. A replayed event may be sent again after a crash
+type Spool struct {
+	next            sender.Transport
+	dir             string
+	maxSize         int64
+	segmentSize     int64
+	sync            bool
+	sendTimeout     time.Duration
+	retryMinBackoff time.Duration
+	retryMaxBackoff time.Duration
+	logger          *slog.Logger
+
+	mu sync.Mutex
+	// segments are in order, the first one is replayed and the last one is written
+	segments   []*segment
+	writer     *os.File
+	reader     *os.File
+	readOffset int64
+	depth      int
+	size       int64
+	// spooled is signaled when an event is spooled
+	spooled chan struct{}
+
+	closeCtx      context.Context
+	closeCtxFunc  context.CancelFunc
+	finishClosing sync.WaitGroup
+}
+
+// New opens the spool in the directory, events left by the previous run are replayed after Run
+func New(cfg config.Spool, next sender.Transport, logger *slog.Logger) (*Spool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Spool{
+		next:            next,
+		dir:             cfg.Dir,
+		maxSize:         cfg.MaxSize,
+		segmentSize:     cfg.SegmentSize,
+		sync:            cfg.Sync,
+		sendTimeout:     cfg.SendTimeout,
+		retryMinBackoff: cfg.RetryMinBackoff,
+		retryMaxBackoff: cfg.RetryMaxBackoff,
+		logger:          logger,
+		spooled:         make(chan struct{}, 1),
+		closeCtx:        ctx,
+		closeCtxFunc:    cancel,
+		finishClosing:   sync.WaitGroup{},
+	}
+
+	if err := s.open(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to open spool: %w", err)
+	}
+
+	if s.depth != 0 {
+		logger.Info("spooled events will be replayed", slog.Int("depth", s.depth))
+	}
+
+	return s, nil
+}
+
+// open finds spooled events, deletes replayed segments and cuts the record the previous run failed to write
+func (s *Spool) open() error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	segments, err := listSegments(s.dir)
+	if err != nil {
+		return err
+	}
+
+	pos, err := s.readPosition()
+	if err != nil {
+		return err
+	}
+
+	for len(segments) != 0 && segments[0].number < pos.Segment {
+		if err = os.Remove(segments[0].path); err != nil {
+			return err
+		}
+		segments = segments[1:]
+	}
+	if len(segments) == 0 || segments[0].number != pos.Segment {
+		pos.Offset = 0
+	}
+
+	for i, seg := range segments {
+		offset := int64(0)
+		if i == 0 {
+			offset = pos.Offset
+		}
+
+		count, end, err := countRecords(seg, offset)
+		if err != nil {
+			return err
+		}
+		s.depth += count
+
+		if end >= seg.size {
+			continue
+		}
+		if i != len(segments)-1 {
+			s.logger.Warn("spool segment is corrupted, the rest of it will be skipped", slog.String("segment", seg.path), slog.Int64("offset", end))
+			continue
+		}
+		if err = os.Truncate(seg.path, end); err != nil {
+			return err
+		}
+		seg.size = end
+	}
+
+	if len(segments) == 0 {
+		seg := &segment{number: pos.Segment + 1}
+		seg.path = filepath.Join(s.dir, segmentName(seg.number))
+		segments = append(segments, seg)
+		pos.Offset = 0
+	}
+
+	s.segments, s.readOffset = segments, pos.Offset
+	for _, seg := range segments {
+		s.size += seg.size
+	}
+
+	if s.writer, err = os.OpenFile(segments[len(segments)-1].path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+		return err
+	}
+
+	s.updateMetrics()
+	return nil
+}
+
+func (s *Spool) readPosition() (position, error) {
+	var pos position
+
+	data, err := os.ReadFile(filepath.Join(s.dir, positionFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return pos, nil
+	}
+	if err != nil {
+		return pos, err
+	}
+
+	if err = json.Unmarshal(data, &pos); err != nil {
+		return pos, fmt.Errorf("incorrect position file: %w", err)
+	}
+
+	return pos, nil
+}
+
+// writePosition replaces the position file by the current position, must be called under lock
+func (s *Spool) writePosition() error {
+	data, err := json.Marshal(position{Segment: s.segments[0].number, Offset: s.readOffset})
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(s.dir, positionFile)
+	file, err := os.Create(path + ".tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if err == nil && s.sync {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
+
+// Run starts the next transport and the replay of spooled events
+func (s *Spool) Run() {
+	s.next.Run()
+
+	s.finishClosing.Add(1)
+
+	go func() {
+		defer s.finishClosing.Done()
+		s.replay(s.closeCtx)
+	}()
+}
+
+// Send sends the event by the next transport or spools it if sending failed or there are spooled events,
+// returns an error only if the event is neither sent nor spooled
+func (s *Spool) Send(ctx context.Context, event sender.Event) error {
+	if s.Depth() == 0 {
+		err := s.next.Send(ctx, event)
+		if err == nil {
+			return nil
+		}
+		s.logger.WarnContext(ctx, "event is spooled", slog.String("event_id", event.ID), slog.Any("error", err))
+	}
+
+	if err := s.append(event); err != nil {
+		return fmt.Errorf("failed to send event: %w", err)
+	}
+
+	return nil
+}
+
+// Depth returns the number of spooled events
+func (s *Spool) Depth() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.depth
+}
+
+func (s *Spool) append(event sender.Event) error {
+	data, err := newRecord(event).encode()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.writer == nil {
+		return errClosed
+	}
+	if s.size+int64(len(data)) > s.maxSize {
+		metrics.Add("dropped", 1)
+		return errFull
+	}
+
+	seg := s.segments[len(s.segments)-1]
+	if seg.size != 0 && seg.size+int64(len(data)) > s.segmentSize {
+		if err = s.rotate(); err != nil {
+			return fmt.Errorf("failed to rotate segment: %w", err)
+		}
+		seg = s.segments[len(s.segments)-1]
+	}
+
+	_, err = s.writer.Write(data)
+	if err == nil && s.sync {
+		err = s.writer.Sync()
+	}
+	if err != nil {
+		// a part of the record may be written
+		_ = s.writer.Truncate(seg.size)
+		return fmt.Errorf("failed to write segment: %w", err)
+	}
+
+	seg.size += int64(len(data))
+	s.size += int64(len(data))
+	s.depth++
+	metrics.Add("spooled", 1)
+	s.updateMetrics()
+
+	select {
+	case s.spooled <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// rotate closes the written segment and starts the next one, must be called under lock
+func (s *Spool) rotate() error {
+	number := s.segments[len(s.segments)-1].number + 1
+	path := filepath.Join(s.dir, segmentName(number))
+
+	writer, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_ = s.writer.Close()
+	s.writer = writer
+	s.segments = append(s.segments, &segment{number: number, path: path})
+
+	return nil
+}
+
+// replay sends spooled events in order until the context is done, an event is retried until it is sent
+func (s *Spool) replay(ctx context.Context) {
+	var backoff time.Duration
+	for {
+		event, next, ok, err := s.peek()
+		if err == nil && !ok {
+			select {
+			case <-s.spooled:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err == nil {
+			err = s.send(ctx, event)
+		}
+		if err == nil {
+			backoff = 0
+			metrics.Add("replayed", 1)
+			if err = s.commit(next); err == nil {
+				continue
+			}
+		}
+
+		s.logger.Error("failed to replay spool", slog.Any("error", err))
+		backoff = min(max(backoff*2, s.retryMinBackoff), s.retryMaxBackoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *Spool) send(ctx context.Context, event sender.Event) error {
+	ctx, cancel := context.WithTimeout(ctx, s.sendTimeout)
+	defer cancel()
+
+	if err := s.next.Send(ctx, event); err != nil {
+		return fmt.Errorf("failed to replay event %s: %w", event.ID, err)
+	}
+
+	return nil
+}
+
+// peek reads the next spooled event and returns it with the offset of the record after it,
+// ok is false if there are no spooled events
+func (s *Spool) peek() (event sender.Event, next int64, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.depth > 0 {
+		seg, last := s.segments[0], len(s.segments) == 1
+
+		if s.readOffset >= seg.size {
+			if last {
+				// records were counted wrong, there is nothing to replay
+				s.depth = 0
+				s.updateMetrics()
+				break
+			}
+			if err = s.dropFirst(); err != nil {
+				return event, 0, false, fmt.Errorf("failed to delete replayed segment: %w", err)
+			}
+			continue
+		}
+
+		if s.reader == nil {
+			if s.reader, err = os.Open(seg.path); err != nil {
+				return event, 0, false, fmt.Errorf("failed to read spool: %w", err)
+			}
+		}
+
+		r, next, err := readRecord(s.reader, s.readOffset)
+		if errors.Is(err, errCorrupted) && !last {
+			s.logger.Warn("spool segment is corrupted, the rest of it is skipped", slog.String("segment", seg.path), slog.Int64("offset", s.readOffset))
+			if err = s.dropFirst(); err != nil {
+				return event, 0, false, fmt.Errorf("failed to delete corrupted segment: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return event, 0, false, fmt.Errorf("failed to read spool: %w", err)
+		}
+
+		return r.event(), next, true, nil
+	}
+
+	return event, 0, false, nil
+}
+
+// commit moves the position after the replayed event, segments are deleted when all their events are replayed
+func (s *Spool) commit(next int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.readOffset = next
+	s.depth--
+
+	if s.depth == 0 {
+		// everything is replayed, so the spool is emptied
+		for len(s.segments) > 1 {
+			if err := s.dropFirst(); err != nil {
+				return fmt.Errorf("failed to delete replayed segment: %w", err)
+			}
+		}
+		if err := s.writer.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate replayed segment: %w", err)
+		}
+		s.size -= s.segments[0].size
+		s.segments[0].size, s.readOffset = 0, 0
+	}
+
+	s.updateMetrics()
+
+	if err := s.writePosition(); err != nil {
+		return fmt.Errorf("failed to save spool position: %w", err)
+	}
+
+	return nil
+}
+
+// dropFirst deletes the first segment, it is never the written one, must be called under lock
+func (s *Spool) dropFirst() error {
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+
+	seg := s.segments[0]
+	if err := os.Remove(seg.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	s.size -= seg.size
+	s.segments, s.readOffset = s.segments[1:], 0
+
+	return s.writePosition()
+}
+
+// updateMetrics must be called under lock
+func (s *Spool) updateMetrics() {
+	depthMetric.Set(int64(s.depth))
+	sizeBytesMetric.Set(s.size)
+}
+
+// Shutdown stops the replay, shuts down the next transport and closes segments, spooled events are kept for the next start
+func (s *Spool) Shutdown() {
+	s.closeCtxFunc()
+	s.finishClosing.Wait()
+
+	s.next.Shutdown()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reader != nil {
+		_ = s.reader.Close()
+		s.reader = nil
+	}
+	if s.writer != nil {
+		_ = s.writer.Sync()
+		_ = s.writer.Close()
+		s.writer = nil
+	}
+}
@@ -0,0 +1,261 @@
+package spool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+var errUnavailable = errors.New("transport is unavailable")
+
+// fakeTransport fails the first failures sends and keeps events sent after them
+type fakeTransport struct {
+	mu       sync.Mutex
+	failures int
+	sent     []sender.Event
+}
+
+func (ft *fakeTransport) Send(_ context.Context, event sender.Event) error {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	if ft.failures > 0 {
+		ft.failures--
+		return errUnavailable
+	}
+
+	ft.sent = append(ft.sent, event)
+	return nil
+}
+
+func (ft *fakeTransport) Run() {}
+
+func (ft *fakeTransport) Shutdown() {}
+
+func (ft *fakeTransport) sentIDs() []string {
+	ft.mu.Lock()
+	defer ft.mu.Unlock()
+
+	ids := make([]string, 0, len(ft.sent))
+	for _, event := range ft.sent {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func testConfig(dir string) config.Spool {
+	return config.Spool{
+		Enabled:         true,
+		Dir:             dir,
+		MaxSize:         1 << 20,
+		SegmentSize:     256,
+		SendTimeout:     time.Second,
+		RetryMinBackoff: time.Millisecond,
+		RetryMaxBackoff: 10 * time.Millisecond,
+	}
+}
+
+func newTestSpool(t *testing.T, cfg config.Spool, next sender.Transport) *Spool {
+	t.Helper()
+
+	s, err := New(cfg, next, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("failed to open spool: %v", err)
+	}
+	return s
+}
+
+func testEvent(i int) sender.Event {
+	return sender.Event{ID: strconv.Itoa(i), Time: time.Now().UTC(), ProfileID: 1, Object: sender.Craft, ObjectID: i, Change: sender.CreateObj}
+}
+
+// sendEvents sends events with ids from first to last, they must be sent or spooled
+func sendEvents(t *testing.T, s *Spool, first, last int) {
+	t.Helper()
+
+	for i := first; i <= last; i++ {
+		if err := s.Send(context.Background(), testEvent(i)); err != nil {
+			t.Fatalf("failed to send event %d: %v", i, err)
+		}
+	}
+}
+
+func waitReplayed(t *testing.T, s *Spool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Depth() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("spool is not replayed, depth %d", s.Depth())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func assertIDs(t *testing.T, got []string, want ...string) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got events %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got events %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSpoolKeepsOrderAcrossRestart(t *testing.T) {
+	cfg := testConfig(t.TempDir())
+
+	s := newTestSpool(t, cfg, &fakeTransport{failures: 1 << 30})
+	sendEvents(t, s, 1, 5)
+	if depth := s.Depth(); depth != 5 {
+		t.Fatalf("got depth %d, want 5", depth)
+	}
+	s.Shutdown()
+
+	if segments, _ := listSegments(cfg.Dir); len(segments) < 2 {
+		t.Fatalf("got %d segments, want events spread over several segments", len(segments))
+	}
+
+	next := &fakeTransport{failures: 3}
+	s = newTestSpool(t, cfg, next)
+	defer s.Shutdown()
+
+	if depth := s.Depth(); depth != 5 {
+		t.Fatalf("got depth %d after restart, want 5", depth)
+	}
+
+	// the spool isn't empty, so the new event is spooled after the old ones instead of being sent first
+	sendEvents(t, s, 6, 6)
+	s.Run()
+	waitReplayed(t, s)
+
+	assertIDs(t, next.sentIDs(), "1", "2", "3", "4", "5", "6")
+}
+
+func TestSpoolTruncatesTornRecord(t *testing.T) {
+	cfg := testConfig(t.TempDir())
+	cfg.SegmentSize = 1 << 20
+
+	s := newTestSpool(t, cfg, &fakeTransport{failures: 1 << 30})
+	sendEvents(t, s, 1, 3)
+	s.Shutdown()
+
+	segments, err := listSegments(cfg.Dir)
+	if err != nil || len(segments) != 1 {
+		t.Fatalf("got segments %v, error %v, want one segment", segments, err)
+	}
+	size := segments[0].size
+
+	// the crash cut the fourth record in the middle
+	data, err := newRecord(testEvent(4)).encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(segments[0].path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = file.Write(data[:len(data)/2]); err != nil {
+		t.Fatal(err)
+	}
+	_ = file.Close()
+
+	next := &fakeTransport{}
+	s = newTestSpool(t, cfg, next)
+	defer s.Shutdown()
+
+	if depth := s.Depth(); depth != 3 {
+		t.Fatalf("got depth %d, want 3", depth)
+	}
+	info, err := os.Stat(segments[0].path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != size {
+		t.Fatalf("got segment size %d, want torn record truncated to %d", info.Size(), size)
+	}
+
+	sendEvents(t, s, 5, 5)
+	s.Run()
+	waitReplayed(t, s)
+
+	assertIDs(t, next.sentIDs(), "1", "2", "3", "5")
+}
+
+func TestSpoolFull(t *testing.T) {
+	data, err := newRecord(testEvent(1)).encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig(t.TempDir())
+	cfg.MaxSize = int64(2 * len(data))
+
+	s := newTestSpool(t, cfg, &fakeTransport{failures: 1 << 30})
+	defer s.Shutdown()
+
+	sendEvents(t, s, 1, 2)
+
+	if err = s.Send(context.Background(), testEvent(3)); !errors.Is(err, errFull) {
+		t.Fatalf("got error %v, want %v", err, errFull)
+	}
+	if depth := s.Depth(); depth != 2 {
+		t.Fatalf("got depth %d, want 2", depth)
+	}
+}
+
+func TestSpoolDepthResetsAfterReplay(t *testing.T) {
+	cfg := testConfig(t.TempDir())
+
+	next := &fakeTransport{failures: 4}
+	s := newTestSpool(t, cfg, next)
+	defer s.Shutdown()
+
+	// the first event fails and the rest are spooled after it
+	sendEvents(t, s, 1, 4)
+	s.Run()
+	waitReplayed(t, s)
+
+	segments, err := listSegments(cfg.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) != 1 || segments[0].size != 0 {
+		t.Fatalf("got segments %v, want one empty segment", segments)
+	}
+
+	pos, err := s.readPosition()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos != (position{Segment: segments[0].number}) {
+		t.Fatalf("got position %+v, want the beginning of segment %d", pos, segments[0].number)
+	}
+	if depth, size := depthMetric.Value(), sizeBytesMetric.Value(); depth != 0 || size != 0 {
+		t.Fatalf("got metrics depth %d and size %d, want zeros", depth, size)
+	}
+
+	// the spool is empty, so the event is sent directly
+	sendEvents(t, s, 5, 5)
+	if depth := s.Depth(); depth != 0 {
+		t.Fatalf("got depth %d, want 0", depth)
+	}
+	assertIDs(t, next.sentIDs(), "1", "2", "3", "4", "5")
+
+	if _, err = os.Stat(filepath.Join(cfg.Dir, positionFile)); err != nil {
+		t.Fatalf("position file is not saved: %v", err)
+	}
+}
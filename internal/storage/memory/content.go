@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+func (row contentRow) model() models.Content {
+	return models.Content{
+		ID:          row.id,
+		Description: row.description,
+		Data:        bytes.Clone(row.data),
+		Position:    row.position,
+		MIMEType:    row.mimeType,
+		Size:        int64(len(row.data)),
+		Checksum:    row.checksum,
+		Width:       row.width,
+		Height:      row.height,
+		Thumbnails:  row.thumbnailsModel(),
+	}
+}
+
+// thumbnailsModel returns thumbnails without their data, the data is returned by GetThumbnail only
+func (row contentRow) thumbnailsModel() []models.Thumbnail {
+	var thumbnails []models.Thumbnail
+	for _, thumbnail := range row.thumbnails {
+		thumbnail.Data = nil
+		thumbnails = append(thumbnails, thumbnail)
+	}
+
+	return thumbnails
+}
+
+// setContent copies the content with its metadata to the row, size and checksum are counted by the data,
+// thumbnails of the previous data are dropped
+func (row *contentRow) setContent(content models.Content) {
+	row.description, row.data, row.checksum = content.Description, bytes.Clone(content.Data), blob.Checksum(content.Data)
+	row.mimeType, row.width, row.height = content.MIMEType, content.Width, content.Height
+	row.thumbnails = nil
+}
+
+func (db *DB) CreateContent(ctx context.Context, craftID int, content models.Content) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.crafts[craftID]; !ok {
+		return 0, fmt.Errorf("failed to create content: craft: %w", errForeignKey)
+	}
+
+	position := 0
+	for _, row := range db.contents {
+		if row.craftID == craftID {
+			position = max(position, row.position+1)
+		}
+	}
+
+	id := db.nextID("contents")
+	row := contentRow{id: id, craftID: craftID, position: position}
+	row.setContent(content)
+	db.contents[id] = row
+
+	return id, nil
+}
+
+// CreateContentFromReader reads the whole data, because the storage keeps it inside the content
+func (db *DB) CreateContentFromReader(ctx context.Context, craftID int, content models.Content, data io.Reader) (int, error) {
+	var err error
+	if content.Data, err = io.ReadAll(data); err != nil {
+		return 0, fmt.Errorf("failed to read content data: %w", err)
+	}
+
+	return db.CreateContent(ctx, craftID, content)
+}
+
+// GetContent returns the visible content with its data
+func (db *DB) GetContent(ctx context.Context, id int) (*models.Content, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.contents[id]
+	if !ok || !row.deletedAt.IsZero() || !db.craftVisible(row.craftID) {
+		return nil, fmt.Errorf("failed to get content: %w", response_errors.ErrNotFound)
+	}
+
+	content := row.model()
+	return &content, nil
+}
+
+// GetContentData returns data of the visible content
+func (db *DB) GetContentData(ctx context.Context, contentID int) (*models.ContentData, error) {
+	content, err := db.GetContent(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ContentData{Data: blob.Bytes(content.Data), MIMEType: content.MIMEType, Size: int64(len(content.Data)), Checksum: blob.Checksum(content.Data)}, nil
+}
+
+// SaveThumbnails replaces thumbnails of the content if its data still matches the checksum
+func (db *DB) SaveThumbnails(ctx context.Context, contentID int, checksum string, thumbnails []models.Thumbnail) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.contents[contentID]
+	if !ok || row.checksum != checksum {
+		return nil
+	}
+
+	row.thumbnails = nil
+	for _, thumbnail := range thumbnails {
+		thumbnail.Data = bytes.Clone(thumbnail.Data)
+		row.thumbnails = append(row.thumbnails, thumbnail)
+	}
+	db.contents[contentID] = row
+
+	return nil
+}
+
+// GetThumbnail returns the thumbnail of the visible content with its data
+func (db *DB) GetThumbnail(ctx context.Context, contentID, size int) (*models.Thumbnail, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.contents[contentID]
+	if !ok || !row.deletedAt.IsZero() || !db.craftVisible(row.craftID) {
+		return nil, fmt.Errorf("failed to get thumbnail: content: %w", response_errors.ErrNotFound)
+	}
+
+	for _, thumbnail := range row.thumbnails {
+		if thumbnail.Size == size {
+			thumbnail.Data = bytes.Clone(thumbnail.Data)
+			return &thumbnail, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to get thumbnail: %w", response_errors.ErrNotFound)
+}
+
+// GetThumbnailData returns data of the thumbnail of the visible content
+func (db *DB) GetThumbnailData(ctx context.Context, contentID, size int) (*models.ContentData, error) {
+	thumbnail, err := db.GetThumbnail(ctx, contentID, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ContentData{Data: blob.Bytes(thumbnail.Data), MIMEType: thumbnail.MIMEType, Size: int64(len(thumbnail.Data)), Checksum: blob.Checksum(thumbnail.Data)}, nil
+}
+
+// GetCraftContentsSize returns size of not deleted contents of the craft except the given one
+func (db *DB) GetCraftContentsSize(ctx context.Context, craftID, exceptContentID int) (int64, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var size int64
+	for _, content := range db.craftContents(craftID) {
+		if content.id != exceptContentID {
+			size += int64(len(content.data))
+		}
+	}
+
+	return size, nil
+}
+
+func (db *DB) DeleteContent(ctx context.Context, id int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.contents[id]
+	if !ok || !row.deletedAt.IsZero() {
+		return nil
+	}
+
+	row.deletedAt = time.Now()
+	db.contents[id] = row
+
+	return nil
+}
+
+func (db *DB) PatchContent(ctx context.Context, authorID int, content models.Content) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.contents[content.ID]
+	if !ok || !row.deletedAt.IsZero() || !db.craftVisible(row.craftID) {
+		return fmt.Errorf("failed to update content: %w", response_errors.ErrNotFound)
+	}
+
+	db.addRevision(models.ObjectContent, content.ID, authorID, models.Revision{Description: row.description})
+
+	row.setContent(content)
+	db.contents[content.ID] = row
+
+	return nil
+}
+
+// deleteContent cascades to revisions, must be called under write lock
+func (db *DB) deleteContent(id int) {
+	delete(db.revisions, revisionKey{object: models.ObjectContent, id: id})
+	delete(db.contents, id)
+}
+
+// craftContents returns not deleted contents of the craft ordered by position and id, must be called under read lock
+func (db *DB) craftContents(craftID int) []contentRow {
+	var contents []contentRow
+	for _, id := range sortedKeys(db.contents) {
+		if content := db.contents[id]; content.craftID == craftID && content.deletedAt.IsZero() {
+			contents = append(contents, content)
+		}
+	}
+	sort.SliceStable(contents, func(i, j int) bool { return contents[i].position < contents[j].position })
+
+	return contents
+}
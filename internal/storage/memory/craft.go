@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+func (db *DB) CreateCraft(ctx context.Context, portfolioID int, craft models.Craft) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.portfolios[portfolioID]; !ok {
+		return 0, fmt.Errorf("failed to create craft: portfolio: %w", errForeignKey)
+	}
+
+	tagIDs := make(map[int]struct{}, len(craft.Tags))
+	for _, tag := range craft.Tags {
+		if err := db.checkTag(tag.ID); err != nil {
+			return 0, fmt.Errorf("failed to create craft: tags error: %w", err)
+		}
+		if _, ok := tagIDs[tag.ID]; ok {
+			return 0, fmt.Errorf("failed to create craft: tags error: %w", errPrimaryKey)
+		}
+		tagIDs[tag.ID] = struct{}{}
+	}
+
+	position := 0
+	for _, row := range db.crafts {
+		if row.portfolioID == portfolioID {
+			position = max(position, row.position+1)
+		}
+	}
+
+	craftID := db.nextID("crafts")
+	db.crafts[craftID] = craftRow{id: craftID, portfolioID: portfolioID, name: craft.Name, description: craft.Description, position: position}
+
+	for tagID := range tagIDs {
+		db.craftsTags[craftTag{craftID: craftID, tagID: tagID}] = struct{}{}
+	}
+
+	return craftID, nil
+}
+
+func (db *DB) CreateTag(ctx context.Context, name string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id := db.nextID("tags")
+	db.tags[id] = models.Tag{ID: id, Name: name}
+
+	return id, nil
+}
+
+func (db *DB) DeleteTag(ctx context.Context, id int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for relation := range db.craftsTags {
+		if relation.tagID == id {
+			return fmt.Errorf("failed to delete tag: crafts_tags: %w", errForeignKey)
+		}
+	}
+
+	delete(db.tags, id)
+
+	return nil
+}
+
+// GetAllTags returns approved tags or pending ones with their proposers
+func (db *DB) GetAllTags(ctx context.Context, page models.Page, pending bool) ([]models.Tag, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var tags []models.Tag
+	for _, id := range paginate(db.tagIDs(pending), page) {
+		tags = append(tags, db.tags[id])
+	}
+
+	return tags, nil
+}
+
+func (db *DB) CountTagsPages(ctx context.Context, pending bool) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return len(db.tagIDs(pending)), nil
+}
+
+func (db *DB) AddTagToCraft(ctx context.Context, craftID, tagID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.crafts[craftID]; !ok {
+		return fmt.Errorf("failed to add tag: craft: %w", errForeignKey)
+	}
+	if err := db.checkTag(tagID); err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+
+	relation := craftTag{craftID: craftID, tagID: tagID}
+	if _, ok := db.craftsTags[relation]; ok {
+		return fmt.Errorf("failed to add tag: %w", errPrimaryKey)
+	}
+	db.craftsTags[relation] = struct{}{}
+
+	return nil
+}
+
+func (db *DB) DeleteTagFromCraft(ctx context.Context, craftID, tagID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.craftsTags, craftTag{craftID: craftID, tagID: tagID})
+
+	return nil
+}
+
+func (db *DB) DeleteCraft(ctx context.Context, id int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.crafts[id]
+	if !ok || !row.deletedAt.IsZero() {
+		return nil
+	}
+
+	row.deletedAt = time.Now()
+	db.crafts[id] = row
+
+	return nil
+}
+
+// deleteCraft cascades to crafts_tags, contents and revisions, must be called under write lock
+func (db *DB) deleteCraft(id int) {
+	for relation := range db.craftsTags {
+		if relation.craftID == id {
+			delete(db.craftsTags, relation)
+		}
+	}
+
+	for contentID, content := range db.contents {
+		if content.craftID == id {
+			db.deleteContent(contentID)
+		}
+	}
+
+	delete(db.revisions, revisionKey{object: models.ObjectCraft, id: id})
+	delete(db.crafts, id)
+}
+
+func (db *DB) PatchCraft(ctx context.Context, authorID int, craft models.Craft) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !db.craftVisible(craft.ID) {
+		return fmt.Errorf("failed to update craft: %w", response_errors.ErrNotFound)
+	}
+	row := db.crafts[craft.ID]
+
+	db.addRevision(models.ObjectCraft, craft.ID, authorID, models.Revision{Name: row.name, Description: row.description})
+
+	row.name, row.description = craft.Name, craft.Description
+	db.crafts[craft.ID] = row
+
+	return nil
+}
+
+func (db *DB) GetCraftByID(ctx context.Context, craftID int) (*models.Craft, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if !db.craftVisible(craftID) {
+		return nil, fmt.Errorf("failed to get craft: %w", response_errors.ErrNotFound)
+	}
+	row := db.crafts[craftID]
+
+	craft := models.Craft{ID: row.id, Name: row.name, Description: row.description, Tags: db.craftTags(craftID), Position: row.position, PortfolioID: row.portfolioID, ProfileID: db.portfolios[row.portfolioID].profileID}
+	for _, content := range db.craftContents(craftID) {
+		craft.Contents = append(craft.Contents, content.model())
+	}
+
+	return &craft, nil
+}
+
+func (db *DB) GetAllCraftsByPortfolioID(ctx context.Context, portfolioID int, page models.Page) ([]models.Craft, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	craftIDs := db.portfolioCrafts(portfolioID)
+	isAfter := func(id int) bool {
+		position := db.crafts[id].position
+		return position > page.AfterPosition() || position == page.AfterPosition() && id > page.AfterID()
+	}
+
+	return db.previews(paginateAfter(craftIDs, page, isAfter)), nil
+}
+
+// portfolioCrafts returns ids of visible crafts of the portfolio ordered by position and id, must be called under read lock
+func (db *DB) portfolioCrafts(portfolioID int) []int {
+	var craftIDs []int
+	for _, id := range sortedKeys(db.crafts) {
+		if db.crafts[id].portfolioID == portfolioID && db.craftVisible(id) {
+			craftIDs = append(craftIDs, id)
+		}
+	}
+	sort.SliceStable(craftIDs, func(i, j int) bool { return db.crafts[craftIDs[i]].position < db.crafts[craftIDs[j]].position })
+
+	return craftIDs
+}
+
+func (db *DB) GetAllCraftsByTagID(ctx context.Context, tagID int, page models.Page) ([]models.Craft, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.previews(paginate(db.craftIDsByTag(tagID), page)), nil
+}
+
+func (db *DB) CountCraftsPages(ctx context.Context, id int, isPortfolioID bool) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if !isPortfolioID {
+		return len(db.craftIDsByTag(id)), nil
+	}
+
+	var amount int
+	for _, craft := range db.crafts {
+		if craft.portfolioID == id && db.craftVisible(craft.id) {
+			amount++
+		}
+	}
+
+	return amount, nil
+}
+
+func (db *DB) craftIDsByTag(tagID int) []int {
+	var craftIDs []int
+	for relation := range db.craftsTags {
+		if relation.tagID == tagID && db.craftVisible(relation.craftID) {
+			craftIDs = append(craftIDs, relation.craftID)
+		}
+	}
+	sort.Ints(craftIDs)
+
+	return craftIDs
+}
+
+// previews returns crafts with tags and the first content only, as the list endpoints do, data of the content is not returned
+func (db *DB) previews(craftIDs []int) []models.Craft {
+	var crafts []models.Craft
+	for _, id := range craftIDs {
+		row := db.crafts[id]
+		craft := models.Craft{ID: row.id, Name: row.name, Description: row.description, Tags: db.craftTags(id), Position: row.position, PortfolioID: row.portfolioID, ProfileID: db.portfolios[row.portfolioID].profileID}
+
+		var preview models.Content
+		if contents := db.craftContents(id); len(contents) != 0 {
+			preview = contents[0].model()
+			preview.Data = nil
+		}
+		craft.Contents = []models.Content{preview}
+
+		crafts = append(crafts, craft)
+	}
+
+	return crafts
+}
+
+func (db *DB) craftTags(craftID int) []models.Tag {
+	var tags []models.Tag
+	for _, id := range sortedKeys(db.tags) {
+		if _, ok := db.craftsTags[craftTag{craftID: craftID, tagID: id}]; ok {
+			tags = append(tags, db.tags[id])
+		}
+	}
+
+	return tags
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+var (
+	errForeignKey = errors.New("violates foreign key constraint")
+	errPrimaryKey = errors.New("violates primary key constraint")
+)
+
+type portfolioRow struct {
+	id          int
+	profileID   int
+	name        string
+	categoryID  int
+	description string
+	deletedAt   time.Time
+}
+
+type craftRow struct {
+	id          int
+	portfolioID int
+	name        string
+	description string
+	position    int
+	deletedAt   time.Time
+}
+
+type craftTag struct {
+	craftID int
+	tagID   int
+}
+
+type contentRow struct {
+	id          int
+	craftID     int
+	description string
+	position    int
+	data        []byte
+	mimeType    string
+	checksum    string
+	width       int
+	height      int
+	thumbnails  []models.Thumbnail
+	deletedAt   time.Time
+}
+
+// DB keeps the tables of postgresql migrations in maps and follows its foreign keys and ON DELETE CASCADE rules
+type DB struct {
+	mu         sync.RWMutex
+	categories map[int]models.Category
+	portfolios map[int]portfolioRow
+	crafts     map[int]craftRow
+	tags       map[int]models.Tag
+	craftsTags map[craftTag]struct{}
+	contents   map[int]contentRow
+	revisions  map[revisionKey][]models.Revision
+	sequences  map[string]int
+}
+
+func NewDB() *DB {
+	return &DB{
+		categories: make(map[int]models.Category),
+		portfolios: make(map[int]portfolioRow),
+		crafts:     make(map[int]craftRow),
+		tags:       make(map[int]models.Tag),
+		craftsTags: make(map[craftTag]struct{}),
+		contents:   make(map[int]contentRow),
+		revisions:  make(map[revisionKey][]models.Revision),
+		sequences:  make(map[string]int),
+	}
+}
+
+// nextID works like BIGSERIAL, must be called under write lock
+func (db *DB) nextID(table string) int {
+	db.sequences[table]++
+	return db.sequences[table]
+}
+
+// sortedKeys returns ids of the table in ascending order, so listings are stable between calls
+func sortedKeys[T any](table map[int]T) []int {
+	keys := make([]int, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	return keys
+}
+
+// paginate returns page of ids sorted in ascending order, in cursor mode ids up to the cursor are skipped
+func paginate(ids []int, page models.Page) []int {
+	return paginateAfter(ids, page, func(id int) bool { return id > page.AfterID() })
+}
+
+// paginateAfter returns page of sorted ids, isAfter reports whether the id is sorted after the cursor, it is true for all ids in page number mode
+func paginateAfter(ids []int, page models.Page, isAfter func(id int) bool) []int {
+	start := sort.Search(len(ids), func(i int) bool { return isAfter(ids[i]) }) + page.Offset
+	if start >= len(ids) {
+		return nil
+	}
+
+	end := start + page.Limit
+	if end > len(ids) {
+		end = len(ids)
+	}
+
+	return ids[start:end]
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// ReorderCrafts sets positions of not deleted crafts of the portfolio by the order, deleted crafts keep their positions
+func (db *DB) ReorderCrafts(ctx context.Context, portfolioID int, order models.Order) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if portfolio, ok := db.portfolios[portfolioID]; !ok || !portfolio.deletedAt.IsZero() {
+		return fmt.Errorf("failed to reorder crafts: portfolio: %w", response_errors.ErrNotFound)
+	}
+
+	if !order.Matches(db.portfolioCrafts(portfolioID)) {
+		return fmt.Errorf("failed to reorder crafts: %w", response_errors.ErrIncorrectOrder)
+	}
+
+	for position, id := range order.IDs {
+		row := db.crafts[id]
+		row.position = position
+		db.crafts[id] = row
+	}
+
+	return nil
+}
+
+// ReorderContents sets positions of not deleted contents of the craft by the order, deleted contents keep their positions
+func (db *DB) ReorderContents(ctx context.Context, craftID int, order models.Order) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if !db.craftVisible(craftID) {
+		return fmt.Errorf("failed to reorder contents: craft: %w", response_errors.ErrNotFound)
+	}
+
+	var ids []int
+	for _, content := range db.craftContents(craftID) {
+		ids = append(ids, content.id)
+	}
+	if !order.Matches(ids) {
+		return fmt.Errorf("failed to reorder contents: %w", response_errors.ErrIncorrectOrder)
+	}
+
+	for position, id := range order.IDs {
+		row := db.contents[id]
+		row.position = position
+		db.contents[id] = row
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// CheckPath checks that each object of the path belongs to the previous one, objects in the trash are checked too
+func (db *DB) CheckPath(ctx context.Context, path models.Path) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if path.ContentID != 0 {
+		if content, ok := db.contents[path.ContentID]; !ok || content.craftID != path.CraftID {
+			return fmt.Errorf("failed to check path: content: %w", response_errors.ErrPathNotFound)
+		}
+	}
+	if path.CraftID != 0 {
+		if craft, ok := db.crafts[path.CraftID]; !ok || craft.portfolioID != path.PortfolioID {
+			return fmt.Errorf("failed to check path: craft: %w", response_errors.ErrPathNotFound)
+		}
+	}
+	if path.PortfolioID != 0 {
+		if portfolio, ok := db.portfolios[path.PortfolioID]; !ok || portfolio.profileID != path.ProfileID {
+			return fmt.Errorf("failed to check path: portfolio: %w", response_errors.ErrPathNotFound)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+func (db *DB) CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if err := db.checkCategory(portfolio.Category.ID); err != nil {
+		return 0, fmt.Errorf("failed to create portfolio: creation error: %w", err)
+	}
+
+	id := db.nextID("portfolios")
+	db.portfolios[id] = portfolioRow{
+		id:          id,
+		profileID:   portfolio.ProfileID,
+		name:        portfolio.Name,
+		categoryID:  portfolio.Category.ID,
+		description: portfolio.Description,
+	}
+
+	return id, nil
+}
+
+func (db *DB) CreateCategory(ctx context.Context, name string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id := db.nextID("categories")
+	db.categories[id] = models.Category{ID: id, Name: name}
+
+	return id, nil
+}
+
+func (db *DB) DeleteCategory(ctx context.Context, id int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, portfolio := range db.portfolios {
+		if portfolio.categoryID == id {
+			return fmt.Errorf("failed to delete category: portfolios: %w", errForeignKey)
+		}
+	}
+
+	delete(db.categories, id)
+
+	return nil
+}
+
+// GetAllCategories returns approved categories or pending ones with their proposers
+func (db *DB) GetAllCategories(ctx context.Context, page models.Page, pending bool) ([]models.Category, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var categories []models.Category
+	for _, id := range paginate(db.categoryIDs(pending), page) {
+		categories = append(categories, db.categories[id])
+	}
+
+	return categories, nil
+}
+
+func (db *DB) CountCategoriesPages(ctx context.Context, pending bool) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return len(db.categoryIDs(pending)), nil
+}
+
+func (db *DB) DeletePortfolio(ctx context.Context, portfolioID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.portfolios[portfolioID]
+	if !ok || !row.deletedAt.IsZero() {
+		return nil
+	}
+
+	row.deletedAt = time.Now()
+	db.portfolios[portfolioID] = row
+
+	return nil
+}
+
+func (db *DB) PatchPortfolio(ctx context.Context, authorID int, portfolio models.Portfolio) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.portfolios[portfolio.ID]
+	if !ok || !row.deletedAt.IsZero() {
+		return fmt.Errorf("failed to update portfolio: %w", response_errors.ErrNotFound)
+	}
+
+	if err := db.checkCategory(portfolio.Category.ID); err != nil {
+		return fmt.Errorf("failed to update portfolio: %w", err)
+	}
+
+	db.addRevision(models.ObjectPortfolio, portfolio.ID, authorID, models.Revision{Name: row.name, Description: row.description, CategoryID: row.categoryID})
+
+	row.name, row.description, row.categoryID = portfolio.Name, portfolio.Description, portfolio.Category.ID
+	db.portfolios[portfolio.ID] = row
+
+	return nil
+}
+
+func (db *DB) GetPortfolioByID(ctx context.Context, portfolioID int) (*models.Portfolio, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.portfolios[portfolioID]
+	if !ok || !row.deletedAt.IsZero() {
+		return nil, fmt.Errorf("failed to get portfolio: %w", response_errors.ErrNotFound)
+	}
+
+	portfolio := db.portfolio(row)
+	return &portfolio, nil
+}
+
+func (db *DB) GetAllPortfolios(ctx context.Context, page models.Page, id int, filterType models.PortfoliosFilterType) ([]models.Portfolio, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	portfolioIDs, err := db.filterPortfolios(id, filterType)
+	if err != nil {
+		return nil, err
+	}
+
+	var portfolios []models.Portfolio
+	for _, portfolioID := range paginate(portfolioIDs, page) {
+		portfolios = append(portfolios, db.portfolio(db.portfolios[portfolioID]))
+	}
+
+	return portfolios, nil
+}
+
+func (db *DB) CountPortfoliosPages(ctx context.Context, id int, filterType models.PortfoliosFilterType) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	portfolioIDs, err := db.filterPortfolios(id, filterType)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(portfolioIDs), nil
+}
+
+// filterPortfolios returns sorted ids of matching portfolios
+func (db *DB) filterPortfolios(id int, filterType models.PortfoliosFilterType) ([]int, error) {
+	var match func(row portfolioRow) bool
+
+	switch filterType {
+	case models.FilterEmpty:
+		match = func(row portfolioRow) bool { return true }
+	case models.FilterByProfileID:
+		match = func(row portfolioRow) bool { return row.profileID == id }
+	case models.FilterByCategoryID:
+		match = func(row portfolioRow) bool { return row.categoryID == id }
+	default:
+		return nil, response_errors.ErrIncorrectPortfoliosFilterType
+	}
+
+	var portfolioIDs []int
+	for _, portfolioID := range sortedKeys(db.portfolios) {
+		if row := db.portfolios[portfolioID]; row.deletedAt.IsZero() && match(row) {
+			portfolioIDs = append(portfolioIDs, portfolioID)
+		}
+	}
+
+	return portfolioIDs, nil
+}
+
+// portfolio joins the row with its category, must be called under read lock
+func (db *DB) portfolio(row portfolioRow) models.Portfolio {
+	return models.Portfolio{
+		ID:          row.id,
+		ProfileID:   row.profileID,
+		Name:        row.name,
+		Description: row.description,
+		Category:    db.categories[row.categoryID],
+	}
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// DeleteProfilePortfolios deletes all portfolios of the profile including ones in the trash and returns them,
+// crafts and contents are removed with them and are not returned
+func (db *DB) DeleteProfilePortfolios(ctx context.Context, profileID int) ([]models.TrashItem, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var deleted []models.TrashItem
+	for _, id := range sortedKeys(db.portfolios) {
+		portfolio := db.portfolios[id]
+		if portfolio.profileID != profileID {
+			continue
+		}
+		deleted = append(deleted, portfolioTrashItem(portfolio))
+
+		for craftID, craft := range db.crafts {
+			if craft.portfolioID == id {
+				db.deleteCraft(craftID)
+			}
+		}
+		delete(db.revisions, revisionKey{object: models.ObjectPortfolio, id: id})
+		delete(db.portfolios, id)
+	}
+
+	return deleted, nil
+}
+
+// AnonymizeProfilePortfolios moves all portfolios of the profile to the anonymous profile, replaces the profile
+// in revisions by it and returns moved portfolios
+func (db *DB) AnonymizeProfilePortfolios(ctx context.Context, profileID, anonymousID int) ([]models.TrashItem, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var anonymized []models.TrashItem
+	for _, id := range sortedKeys(db.portfolios) {
+		portfolio := db.portfolios[id]
+		if portfolio.profileID != profileID {
+			continue
+		}
+		anonymized = append(anonymized, portfolioTrashItem(portfolio))
+
+		portfolio.profileID = anonymousID
+		db.portfolios[id] = portfolio
+	}
+
+	for _, revisions := range db.revisions {
+		for i := range revisions {
+			if revisions[i].AuthorID == profileID {
+				revisions[i].AuthorID = anonymousID
+			}
+		}
+	}
+
+	return anonymized, nil
+}
@@ -0,0 +1,129 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// revisionKey identifies the object which revisions are kept
+type revisionKey struct {
+	object string
+	id     int
+}
+
+// addRevision saves values of the object before the change as its next revision, must be called under write lock
+func (db *DB) addRevision(object string, id, authorID int, values models.Revision) {
+	key := revisionKey{object: object, id: id}
+
+	values.Object, values.ObjectID = object, id
+	values.Number = len(db.revisions[key]) + 1
+	values.AuthorID = authorID
+	values.CreatedAt = time.Now()
+
+	db.revisions[key] = append(db.revisions[key], values)
+}
+
+// currentValues returns values of the visible object as they are kept in revisions, must be called under read lock
+func (db *DB) currentValues(object string, id int) (models.Revision, bool) {
+	switch object {
+	case models.ObjectPortfolio:
+		if row, ok := db.portfolios[id]; ok && row.deletedAt.IsZero() {
+			return models.Revision{Name: row.name, Description: row.description, CategoryID: row.categoryID}, true
+		}
+	case models.ObjectCraft:
+		if db.craftVisible(id) {
+			row := db.crafts[id]
+			return models.Revision{Name: row.name, Description: row.description}, true
+		}
+	case models.ObjectContent:
+		if row, ok := db.contents[id]; ok && row.deletedAt.IsZero() && db.craftVisible(row.craftID) {
+			return models.Revision{Description: row.description}, true
+		}
+	}
+
+	return models.Revision{}, false
+}
+
+func (db *DB) Revert(ctx context.Context, authorID int, object string, id, revision int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current, ok := db.currentValues(object, id)
+	if !ok {
+		return fmt.Errorf("failed to revert %s: %w", object, response_errors.ErrNotFound)
+	}
+
+	revisions := db.revisions[revisionKey{object: object, id: id}]
+	if revision <= 0 || revision > len(revisions) {
+		return fmt.Errorf("failed to revert %s: revision: %w", object, response_errors.ErrNotFound)
+	}
+	values := revisions[revision-1]
+
+	if object == models.ObjectPortfolio {
+		if err := db.checkCategory(values.CategoryID); err != nil {
+			return fmt.Errorf("failed to revert %s: %w", object, err)
+		}
+	}
+
+	db.addRevision(object, id, authorID, current)
+
+	switch object {
+	case models.ObjectPortfolio:
+		row := db.portfolios[id]
+		row.name, row.description, row.categoryID = values.Name, values.Description, values.CategoryID
+		db.portfolios[id] = row
+	case models.ObjectCraft:
+		row := db.crafts[id]
+		row.name, row.description = values.Name, values.Description
+		db.crafts[id] = row
+	case models.ObjectContent:
+		row := db.contents[id]
+		row.description = values.Description
+		db.contents[id] = row
+	}
+
+	return nil
+}
+
+func (db *DB) GetRevisions(ctx context.Context, object string, id int, page models.Page) ([]models.Revision, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	all := db.revisions[revisionKey{object: object, id: id}]
+
+	numbers := make([]int, len(all))
+	for i := range all {
+		numbers[i] = i + 1
+	}
+
+	var revisions []models.Revision
+	for _, number := range paginate(numbers, page) {
+		revisions = append(revisions, all[number-1])
+	}
+
+	return revisions, nil
+}
+
+func (db *DB) CountRevisions(ctx context.Context, object string, id int) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return len(db.revisions[revisionKey{object: object, id: id}]), nil
+}
+
+func (db *DB) GetRevision(ctx context.Context, object string, id, revision int) (*models.Revision, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	revisions := db.revisions[revisionKey{object: object, id: id}]
+	if revision <= 0 || revision > len(revisions) {
+		return nil, fmt.Errorf("failed to get revision: %w", response_errors.ErrNotFound)
+	}
+
+	result := revisions[revision-1]
+	return &result, nil
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// weights of the document fields, the same order as in search_vector of postgresql migrations
+const (
+	weightName        = 1.0
+	weightTags        = 0.4
+	weightDescription = 0.2
+	weightContents    = 0.1
+)
+
+type searchField struct {
+	text   string
+	weight float32
+}
+
+// Search is a simple replacement of postgresql full-text search: every word of the query must be found in the document,
+// rank is a sum of weights of the best fields containing the words
+func (db *DB) Search(ctx context.Context, query models.SearchQuery, page models.Page) ([]models.SearchResult, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	results := db.search(query)
+
+	start := 0
+	if page.After != nil && page.After.Object != "" {
+		after := models.SearchResult{Object: page.After.Object, ID: page.After.ID, Rank: page.After.Rank}
+		start = sort.Search(len(results), func(i int) bool { return searchResultLess(after, results[i]) })
+	}
+	start += page.Offset
+	if start >= len(results) {
+		return nil, nil
+	}
+
+	end := start + page.Limit
+	if end > len(results) {
+		end = len(results)
+	}
+
+	return results[start:end], nil
+}
+
+func (db *DB) CountSearchResults(ctx context.Context, query models.SearchQuery) (int, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return len(db.search(query)), nil
+}
+
+// search returns all matching results sorted by rank, object and id, must be called under read lock
+func (db *DB) search(query models.SearchQuery) []models.SearchResult {
+	terms := words(query.Text)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []models.SearchResult
+
+	for _, portfolio := range db.portfolios {
+		if !portfolio.deletedAt.IsZero() {
+			continue
+		}
+		if query.CategoryID != 0 && portfolio.categoryID != query.CategoryID {
+			continue
+		}
+		if query.TagID != 0 && !db.portfolioHasTag(portfolio.id, query.TagID) {
+			continue
+		}
+
+		fields := []searchField{{portfolio.name, weightName}, {portfolio.description, weightDescription}}
+		if rank, ok := match(terms, fields); ok {
+			results = append(results, models.SearchResult{
+				Object:      models.ObjectPortfolio,
+				ID:          portfolio.id,
+				PortfolioID: portfolio.id,
+				ProfileID:   portfolio.profileID,
+				Name:        portfolio.name,
+				Snippet:     highlight(terms, fields),
+				Rank:        rank,
+			})
+		}
+	}
+
+	for _, craft := range db.crafts {
+		if !db.craftVisible(craft.id) {
+			continue
+		}
+
+		portfolio := db.portfolios[craft.portfolioID]
+		if query.CategoryID != 0 && portfolio.categoryID != query.CategoryID {
+			continue
+		}
+		if _, ok := db.craftsTags[craftTag{craftID: craft.id, tagID: query.TagID}]; query.TagID != 0 && !ok {
+			continue
+		}
+
+		var tags, contents []string
+		for _, tag := range db.craftTags(craft.id) {
+			tags = append(tags, tag.Name)
+		}
+		for _, content := range db.craftContents(craft.id) {
+			contents = append(contents, content.description)
+		}
+
+		fields := []searchField{
+			{craft.name, weightName},
+			{strings.Join(tags, " "), weightTags},
+			{craft.description, weightDescription},
+			{strings.Join(contents, " "), weightContents},
+		}
+		if rank, ok := match(terms, fields); ok {
+			results = append(results, models.SearchResult{
+				Object:      models.ObjectCraft,
+				ID:          craft.id,
+				PortfolioID: craft.portfolioID,
+				ProfileID:   portfolio.profileID,
+				Name:        craft.name,
+				Snippet:     highlight(terms, fields),
+				Rank:        rank,
+			})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return searchResultLess(results[i], results[j]) })
+
+	return results
+}
+
+func searchResultLess(a, b models.SearchResult) bool {
+	if a.Rank != b.Rank {
+		return a.Rank > b.Rank
+	}
+	if a.Object != b.Object {
+		return a.Object < b.Object
+	}
+	return a.ID < b.ID
+}
+
+func (db *DB) portfolioHasTag(portfolioID, tagID int) bool {
+	for relation := range db.craftsTags {
+		if relation.tagID == tagID && db.crafts[relation.craftID].portfolioID == portfolioID && db.craftVisible(relation.craftID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match reports whether all terms are found in the fields and returns the rank of the document
+func match(terms []string, fields []searchField) (float32, bool) {
+	fieldsWords := make([]map[string]struct{}, len(fields))
+	for i, field := range fields {
+		fieldsWords[i] = make(map[string]struct{})
+		for _, word := range words(field.text) {
+			fieldsWords[i][word] = struct{}{}
+		}
+	}
+
+	var rank float32
+	for _, term := range terms {
+		var best float32
+		for i, field := range fields {
+			if _, ok := fieldsWords[i][term]; ok && field.weight > best {
+				best = field.weight
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		rank += best
+	}
+
+	return rank, true
+}
+
+// highlight joins the fields and wraps found words in <b></b> as ts_headline does
+func highlight(terms []string, fields []searchField) string {
+	found := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		found[term] = struct{}{}
+	}
+
+	var texts []string
+	for _, field := range fields {
+		if field.text != "" {
+			texts = append(texts, field.text)
+		}
+	}
+
+	var snippet strings.Builder
+	for i, word := range strings.Fields(strings.Join(texts, " ")) {
+		if i != 0 {
+			snippet.WriteByte(' ')
+		}
+		if _, ok := found[strings.ToLower(strings.TrimFunc(word, isSeparator))]; ok {
+			snippet.WriteString("<b>" + word + "</b>")
+			continue
+		}
+		snippet.WriteString(word)
+	}
+
+	return snippet.String()
+}
+
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), isSeparator)
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
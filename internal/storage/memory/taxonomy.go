@@ -0,0 +1,121 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+func (db *DB) ProposeCategory(ctx context.Context, name string, profileID int) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id := db.nextID("categories")
+	db.categories[id] = models.Category{ID: id, Name: name, Status: models.StatusPending, ProposedBy: profileID}
+
+	return id, nil
+}
+
+func (db *DB) ProposeTag(ctx context.Context, name string, profileID int) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	id := db.nextID("tags")
+	db.tags[id] = models.Tag{ID: id, Name: name, Status: models.StatusPending, ProposedBy: profileID}
+
+	return id, nil
+}
+
+// ReviewCategory approves the pending category or deletes it if it is rejected
+func (db *DB) ReviewCategory(ctx context.Context, id int, approve bool) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	category, ok := db.categories[id]
+	if !ok || category.Status != models.StatusPending {
+		return fmt.Errorf("failed to review category: %w", response_errors.ErrNotFound)
+	}
+
+	if !approve {
+		delete(db.categories, id)
+		return nil
+	}
+
+	category.Status, category.ProposedBy = "", 0
+	db.categories[id] = category
+
+	return nil
+}
+
+// ReviewTag approves the pending tag or deletes it if it is rejected
+func (db *DB) ReviewTag(ctx context.Context, id int, approve bool) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tag, ok := db.tags[id]
+	if !ok || tag.Status != models.StatusPending {
+		return fmt.Errorf("failed to review tag: %w", response_errors.ErrNotFound)
+	}
+
+	if !approve {
+		delete(db.tags, id)
+		return nil
+	}
+
+	tag.Status, tag.ProposedBy = "", 0
+	db.tags[id] = tag
+
+	return nil
+}
+
+// checkCategory works like the foreign key and also rejects pending categories, must be called under lock
+func (db *DB) checkCategory(id int) error {
+	category, ok := db.categories[id]
+	if !ok {
+		return fmt.Errorf("category: %w", errForeignKey)
+	}
+	if category.Status == models.StatusPending {
+		return fmt.Errorf("category %d: %w", id, response_errors.ErrNotApproved)
+	}
+
+	return nil
+}
+
+// checkTag works like the foreign key and also rejects pending tags, must be called under lock
+func (db *DB) checkTag(id int) error {
+	tag, ok := db.tags[id]
+	if !ok {
+		return fmt.Errorf("tag: %w", errForeignKey)
+	}
+	if tag.Status == models.StatusPending {
+		return fmt.Errorf("tag %d: %w", id, response_errors.ErrNotApproved)
+	}
+
+	return nil
+}
+
+// categoryIDs returns sorted ids of approved or pending categories, must be called under lock
+func (db *DB) categoryIDs(pending bool) []int {
+	var ids []int
+	for _, id := range sortedKeys(db.categories) {
+		if (db.categories[id].Status == models.StatusPending) == pending {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// tagIDs returns sorted ids of approved or pending tags, must be called under lock
+func (db *DB) tagIDs(pending bool) []int {
+	var ids []int
+	for _, id := range sortedKeys(db.tags) {
+		if (db.tags[id].Status == models.StatusPending) == pending {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// craftVisible reports whether the craft exists and neither it nor its portfolio is deleted, must be called under read lock
+func (db *DB) craftVisible(craftID int) bool {
+	craft, ok := db.crafts[craftID]
+	return ok && craft.deletedAt.IsZero() && db.portfolios[craft.portfolioID].deletedAt.IsZero()
+}
+
+func (db *DB) GetTrash(ctx context.Context, profileID int) ([]models.TrashItem, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var items []models.TrashItem
+
+	for _, portfolio := range db.portfolios {
+		if portfolio.profileID == profileID && !portfolio.deletedAt.IsZero() {
+			items = append(items, portfolioTrashItem(portfolio))
+		}
+	}
+
+	for _, craft := range db.crafts {
+		portfolio := db.portfolios[craft.portfolioID]
+		if portfolio.profileID == profileID && !craft.deletedAt.IsZero() && portfolio.deletedAt.IsZero() {
+			items = append(items, craftTrashItem(craft, portfolio))
+		}
+	}
+
+	for _, content := range db.contents {
+		craft := db.crafts[content.craftID]
+		portfolio := db.portfolios[craft.portfolioID]
+		if portfolio.profileID == profileID && !content.deletedAt.IsZero() && db.craftVisible(craft.id) {
+			items = append(items, contentTrashItem(content, craft, portfolio))
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		}
+		if items[i].Object != items[j].Object {
+			return items[i].Object < items[j].Object
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	return items, nil
+}
+
+func (db *DB) RestorePortfolio(ctx context.Context, profileID, portfolioID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.portfolios[portfolioID]
+	if !ok || row.profileID != profileID || row.deletedAt.IsZero() {
+		return fmt.Errorf("failed to restore portfolio: %w", response_errors.ErrNotFound)
+	}
+
+	row.deletedAt = time.Time{}
+	db.portfolios[portfolioID] = row
+
+	return nil
+}
+
+func (db *DB) RestoreCraft(ctx context.Context, profileID, craftID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.crafts[craftID]
+	portfolio := db.portfolios[row.portfolioID]
+	if !ok || portfolio.profileID != profileID || row.deletedAt.IsZero() || !portfolio.deletedAt.IsZero() {
+		return fmt.Errorf("failed to restore craft: %w", response_errors.ErrNotFound)
+	}
+
+	row.deletedAt = time.Time{}
+	db.crafts[craftID] = row
+
+	return nil
+}
+
+func (db *DB) RestoreContent(ctx context.Context, profileID, contentID int) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	row, ok := db.contents[contentID]
+	craft := db.crafts[row.craftID]
+	if !ok || db.portfolios[craft.portfolioID].profileID != profileID || row.deletedAt.IsZero() || !db.craftVisible(craft.id) {
+		return fmt.Errorf("failed to restore content: %w", response_errors.ErrNotFound)
+	}
+
+	row.deletedAt = time.Time{}
+	db.contents[contentID] = row
+
+	return nil
+}
+
+// PurgeTrash hard-deletes everything deleted before the given time and returns purged items,
+// children of purged portfolios and crafts are removed with them and are not returned
+func (db *DB) PurgeTrash(ctx context.Context, before time.Time) ([]models.TrashItem, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var purged []models.TrashItem
+
+	for id, portfolio := range db.portfolios {
+		if !portfolio.deletedAt.IsZero() && portfolio.deletedAt.Before(before) {
+			purged = append(purged, portfolioTrashItem(portfolio))
+
+			for craftID, craft := range db.crafts {
+				if craft.portfolioID == id {
+					db.deleteCraft(craftID)
+				}
+			}
+			delete(db.revisions, revisionKey{object: models.ObjectPortfolio, id: id})
+			delete(db.portfolios, id)
+		}
+	}
+
+	for id, craft := range db.crafts {
+		if !craft.deletedAt.IsZero() && craft.deletedAt.Before(before) {
+			purged = append(purged, craftTrashItem(craft, db.portfolios[craft.portfolioID]))
+			db.deleteCraft(id)
+		}
+	}
+
+	for id, content := range db.contents {
+		if !content.deletedAt.IsZero() && content.deletedAt.Before(before) {
+			craft := db.crafts[content.craftID]
+			purged = append(purged, contentTrashItem(content, craft, db.portfolios[craft.portfolioID]))
+			db.deleteContent(id)
+		}
+	}
+
+	return purged, nil
+}
+
+func portfolioTrashItem(portfolio portfolioRow) models.TrashItem {
+	return models.TrashItem{
+		Object:      models.ObjectPortfolio,
+		ID:          portfolio.id,
+		ProfileID:   portfolio.profileID,
+		PortfolioID: portfolio.id,
+		Name:        portfolio.name,
+		DeletedAt:   portfolio.deletedAt,
+	}
+}
+
+func craftTrashItem(craft craftRow, portfolio portfolioRow) models.TrashItem {
+	return models.TrashItem{
+		Object:      models.ObjectCraft,
+		ID:          craft.id,
+		ProfileID:   portfolio.profileID,
+		PortfolioID: craft.portfolioID,
+		Name:        craft.name,
+		DeletedAt:   craft.deletedAt,
+	}
+}
+
+func contentTrashItem(content contentRow, craft craftRow, portfolio portfolioRow) models.TrashItem {
+	return models.TrashItem{
+		Object:      models.ObjectContent,
+		ID:          content.id,
+		ProfileID:   portfolio.profileID,
+		PortfolioID: craft.portfolioID,
+		CraftID:     content.craftID,
+		Name:        content.description,
+		DeletedAt:   content.deletedAt,
+	}
+}
@@ -0,0 +1,221 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+func (db *DB) CreateContent(ctx context.Context, craftID int, content models.Content) (int, error) {
+	position, err := db.nextPosition(ctx, mongo.Pipeline{
+		constructor("$match", constructor("crafts._id", craftID)),
+		constructor("$unwind", "$crafts"),
+		constructor("$match", constructor("crafts._id", craftID)),
+		constructor("$unwind", "$crafts.contents"),
+		constructor("$replaceRoot", constructor("newRoot", "$crafts.contents")),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create content: %w", err)
+	}
+
+	contentID, err := db.nextID(ctx, contentsSeq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create content: %w", err)
+	}
+
+	content.ID, content.Position = contentID, position
+	content.Size, content.Checksum = int64(len(content.Data)), blob.Checksum(content.Data)
+	content.Thumbnails = nil
+
+	result, err := db.portfolios.UpdateOne(ctx, constructor("crafts._id", craftID), constructor("$push", constructor("crafts.$.contents", content)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create content: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return 0, fmt.Errorf("failed to create content: craft: %w", response_errors.ErrNotFound)
+	}
+
+	return contentID, nil
+}
+
+// visibleContent finds the content if it and its craft and portfolio are not deleted
+func visibleContent(id int) mongo.Pipeline {
+	return mongo.Pipeline{
+		constructor("$match", bson.D{{Key: "crafts.contents._id", Value: id}, notDeleted}),
+		constructor("$unwind", "$crafts"),
+		constructor("$match", constructor("crafts.deleted_at", nil)),
+		constructor("$unwind", "$crafts.contents"),
+		constructor("$match", bson.D{{Key: "crafts.contents._id", Value: id}, {Key: "crafts.contents.deleted_at", Value: nil}}),
+	}
+}
+
+// CreateContentFromReader reads the whole data, because the storage keeps it inside the content
+func (db *DB) CreateContentFromReader(ctx context.Context, craftID int, content models.Content, data io.Reader) (int, error) {
+	var err error
+	if content.Data, err = io.ReadAll(data); err != nil {
+		return 0, fmt.Errorf("failed to read content data: %w", err)
+	}
+
+	return db.CreateContent(ctx, craftID, content)
+}
+
+// GetContent returns the visible content with its data
+func (db *DB) GetContent(ctx context.Context, id int) (*models.Content, error) {
+	pipeline := append(visibleContent(id), constructor("$replaceRoot", constructor("newRoot", "$crafts.contents")))
+
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content: %w", err)
+	}
+
+	var contents []models.Content
+	if err = cursor.All(ctx, &contents); err != nil {
+		return nil, fmt.Errorf("failed to get content: decode error: %w", err)
+	}
+
+	if len(contents) == 0 {
+		return nil, fmt.Errorf("failed to get content: %w", response_errors.ErrNotFound)
+	}
+
+	return &contents[0], nil
+}
+
+// GetContentData returns data of the visible content
+func (db *DB) GetContentData(ctx context.Context, contentID int) (*models.ContentData, error) {
+	content, err := db.GetContent(ctx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ContentData{Data: blob.Bytes(content.Data), MIMEType: content.MIMEType, Size: int64(len(content.Data)), Checksum: blob.Checksum(content.Data)}, nil
+}
+
+// SaveThumbnails replaces thumbnails of the content if its data still matches the checksum
+func (db *DB) SaveThumbnails(ctx context.Context, contentID int, checksum string, thumbnails []models.Thumbnail) error {
+	update := constructor("$set", constructor("crafts.$[craft].contents.$[content].thumbnails", thumbnails))
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+		constructor("craft.contents._id", contentID),
+		bson.D{{Key: "content._id", Value: contentID}, {Key: "content.sha256", Value: checksum}},
+	}})
+
+	if _, err := db.portfolios.UpdateOne(ctx, constructor("crafts.contents._id", contentID), update, opts); err != nil {
+		return fmt.Errorf("failed to save thumbnails: %w", err)
+	}
+
+	return nil
+}
+
+// GetThumbnail returns the thumbnail of the visible content with its data
+func (db *DB) GetThumbnail(ctx context.Context, contentID, size int) (*models.Thumbnail, error) {
+	pipeline := append(visibleContent(contentID),
+		constructor("$unwind", "$crafts.contents.thumbnails"),
+		constructor("$match", constructor("crafts.contents.thumbnails.size", size)),
+		constructor("$replaceRoot", constructor("newRoot", "$crafts.contents.thumbnails")),
+	)
+
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thumbnail: %w", err)
+	}
+
+	var thumbnails []models.Thumbnail
+	if err = cursor.All(ctx, &thumbnails); err != nil {
+		return nil, fmt.Errorf("failed to get thumbnail: decode error: %w", err)
+	}
+
+	if len(thumbnails) == 0 {
+		return nil, fmt.Errorf("failed to get thumbnail: %w", response_errors.ErrNotFound)
+	}
+
+	return &thumbnails[0], nil
+}
+
+// GetThumbnailData returns data of the thumbnail of the visible content
+func (db *DB) GetThumbnailData(ctx context.Context, contentID, size int) (*models.ContentData, error) {
+	thumbnail, err := db.GetThumbnail(ctx, contentID, size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ContentData{Data: blob.Bytes(thumbnail.Data), MIMEType: thumbnail.MIMEType, Size: int64(len(thumbnail.Data)), Checksum: blob.Checksum(thumbnail.Data)}, nil
+}
+
+// GetCraftContentsSize returns size of not deleted contents of the craft except the given one
+func (db *DB) GetCraftContentsSize(ctx context.Context, craftID, exceptContentID int) (int64, error) {
+	pipeline := mongo.Pipeline{
+		constructor("$match", bson.D{{Key: "crafts._id", Value: craftID}, notDeleted}),
+		constructor("$unwind", "$crafts"),
+		constructor("$match", constructor("crafts._id", craftID)),
+		constructor("$unwind", "$crafts.contents"),
+		constructor("$match", bson.D{{Key: "crafts.contents._id", Value: constructor("$ne", exceptContentID)}, {Key: "crafts.contents.deleted_at", Value: nil}}),
+		constructor("$group", bson.D{{Key: "_id", Value: nil}, {Key: "size", Value: constructor("$sum", constructor("$binarySize", "$crafts.contents.data"))}}),
+	}
+
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get size of craft contents: %w", err)
+	}
+
+	var result []struct {
+		Size int64 `bson:"size"`
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return 0, fmt.Errorf("failed to get size of craft contents: decode error: %w", err)
+	}
+
+	if len(result) == 0 {
+		return 0, nil
+	}
+
+	return result[0].Size, nil
+}
+
+func (db *DB) DeleteContent(ctx context.Context, id int) error {
+	update := constructor("$set", constructor("crafts.$[craft].contents.$[content].deleted_at", time.Now()))
+
+	if _, err := db.portfolios.UpdateOne(ctx, constructor("crafts.contents._id", id), update, contentArrayFilters(id, nil)); err != nil {
+		return fmt.Errorf("failed to delete content: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) PatchContent(ctx context.Context, authorID int, content models.Content) error {
+	if err := db.addRevision(ctx, models.ObjectContent, content.ID, authorID); err != nil {
+		return fmt.Errorf("failed to update content: %w", err)
+	}
+
+	update := constructor("$set", bson.D{
+		{Key: "crafts.$[craft].contents.$[content].content_description", Value: content.Description},
+		{Key: "crafts.$[craft].contents.$[content].data", Value: content.Data},
+		{Key: "crafts.$[craft].contents.$[content].mime_type", Value: content.MIMEType},
+		{Key: "crafts.$[craft].contents.$[content].size", Value: int64(len(content.Data))},
+		{Key: "crafts.$[craft].contents.$[content].sha256", Value: blob.Checksum(content.Data)},
+		{Key: "crafts.$[craft].contents.$[content].width", Value: content.Width},
+		{Key: "crafts.$[craft].contents.$[content].height", Value: content.Height},
+		// thumbnails of the previous data are made again for the new one
+		{Key: "crafts.$[craft].contents.$[content].thumbnails", Value: bson.A{}},
+	})
+	if _, err := db.portfolios.UpdateOne(ctx, constructor("crafts.contents._id", content.ID), update, contentArrayFilters(content.ID, nil)); err != nil {
+		return fmt.Errorf("failed to update content: %w", err)
+	}
+
+	return nil
+}
+
+// contentArrayFilters selects the content for $[craft] and $[content] placeholders, deletedAt is a condition for deleted_at of the content
+func contentArrayFilters(contentID int, deletedAt interface{}) *options.UpdateOptions {
+	return options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{
+		constructor("craft.contents._id", contentID),
+		bson.D{{Key: "content._id", Value: contentID}, {Key: "content.deleted_at", Value: deletedAt}},
+	}})
+}
@@ -0,0 +1,314 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+func (db *DB) CreateCraft(ctx context.Context, portfolioID int, craft models.Craft) (int, error) {
+	tags := make([]models.Tag, 0, len(craft.Tags))
+	for _, tag := range craft.Tags {
+		t, err := db.getTag(ctx, tag.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create craft: tags error: %w", err)
+		}
+		tags = append(tags, *t)
+	}
+
+	position, err := db.nextPosition(ctx, mongo.Pipeline{
+		constructor("$match", constructor("_id", portfolioID)),
+		constructor("$unwind", "$crafts"),
+		constructor("$replaceRoot", constructor("newRoot", "$crafts")),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to create craft: %w", err)
+	}
+
+	craftID, err := db.nextID(ctx, craftsSeq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create craft: %w", err)
+	}
+
+	craft.ID, craft.Position = craftID, position
+	craft.Tags = tags
+	craft.Contents = []models.Content{}
+	craft.PortfolioID, craft.ProfileID = 0, 0
+
+	result, err := db.portfolios.UpdateByID(ctx, portfolioID, constructor("$push", constructor("crafts", craft)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create craft: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return 0, fmt.Errorf("failed to create craft: portfolio: %w", response_errors.ErrNotFound)
+	}
+
+	return craftID, nil
+}
+
+func (db *DB) CreateTag(ctx context.Context, name string) (int, error) {
+	id, err := db.nextID(ctx, tagsSeq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	if _, err = db.tags.InsertOne(ctx, models.Tag{ID: id, Name: name}); err != nil {
+		return 0, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return id, nil
+}
+
+func (db *DB) DeleteTag(ctx context.Context, id int) error {
+	used, err := db.portfolios.CountDocuments(ctx, constructor("crafts.tags._id", id))
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if used != 0 {
+		return fmt.Errorf("failed to delete tag: tag is used by crafts of %d portfolios", used)
+	}
+
+	if _, err = db.tags.DeleteOne(ctx, constructor("_id", id)); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return nil
+}
+
+// getTag returns the tag to be embedded into crafts, pending tags can't be used
+func (db *DB) getTag(ctx context.Context, id int) (*models.Tag, error) {
+	var tag models.Tag
+	if err := db.tags.FindOne(ctx, constructor("_id", id)).Decode(&tag); err != nil {
+		return nil, fmt.Errorf("failed to get tag: %w", notFound(err))
+	}
+	if tag.Status == models.StatusPending {
+		return nil, fmt.Errorf("failed to get tag: %w", response_errors.ErrNotApproved)
+	}
+
+	return &tag, nil
+}
+
+// GetAllTags returns approved tags or pending ones with their proposers
+func (db *DB) GetAllTags(ctx context.Context, page models.Page, pending bool) ([]models.Tag, error) {
+	cursor, err := db.tags.Find(ctx, append(afterID(page), byStatus(pending)), findPage(page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all tags: %w", err)
+	}
+
+	var tags []models.Tag
+	if err = cursor.All(ctx, &tags); err != nil {
+		return nil, fmt.Errorf("failed to get all tags: decode error: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (db *DB) CountTagsPages(ctx context.Context, pending bool) (int, error) {
+	amount, err := db.tags.CountDocuments(ctx, bson.D{byStatus(pending)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tags: %w", err)
+	}
+
+	return int(amount), nil
+}
+
+func (db *DB) AddTagToCraft(ctx context.Context, craftID, tagID int) error {
+	tag, err := db.getTag(ctx, tagID)
+	if err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+
+	result, err := db.portfolios.UpdateOne(ctx, constructor("crafts._id", craftID), constructor("$addToSet", constructor("crafts.$.tags", tag)))
+	if err != nil {
+		return fmt.Errorf("failed to add tag: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("failed to add tag: craft: %w", response_errors.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteTagFromCraft(ctx context.Context, craftID, tagID int) error {
+	update := constructor("$pull", constructor("crafts.$.tags", constructor("_id", tagID)))
+
+	if _, err := db.portfolios.UpdateOne(ctx, constructor("crafts._id", craftID), update); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) DeleteCraft(ctx context.Context, id int) error {
+	update := constructor("$set", constructor("crafts.$.deleted_at", time.Now()))
+
+	if _, err := db.portfolios.UpdateOne(ctx, craftElement(id, nil), update); err != nil {
+		return fmt.Errorf("failed to delete craft: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) PatchCraft(ctx context.Context, authorID int, craft models.Craft) error {
+	if err := db.addRevision(ctx, models.ObjectCraft, craft.ID, authorID); err != nil {
+		return fmt.Errorf("failed to update craft: %w", err)
+	}
+
+	update := constructor("$set", bson.D{
+		{Key: "crafts.$.craft_name", Value: craft.Name},
+		{Key: "crafts.$.craft_description", Value: craft.Description},
+	})
+
+	if _, err := db.portfolios.UpdateOne(ctx, craftElement(craft.ID, nil), update); err != nil {
+		return fmt.Errorf("failed to update craft: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetCraftByID(ctx context.Context, craftID int) (*models.Craft, error) {
+	pipeline := mongo.Pipeline{
+		constructor("$match", bson.D{{Key: "crafts._id", Value: craftID}, notDeleted}),
+		constructor("$unwind", "$crafts"),
+		craftRoot,
+		constructor("$match", bson.D{{Key: "_id", Value: craftID}, notDeleted}),
+		constructor("$set", constructor("contents", sortedContents)),
+		constructor("$unset", "contents.thumbnails.data"),
+	}
+
+	crafts, err := db.aggregateCrafts(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get craft: %w", err)
+	}
+
+	if len(crafts) == 0 {
+		return nil, fmt.Errorf("failed to get craft: %w", response_errors.ErrNotFound)
+	}
+
+	return &crafts[0], nil
+}
+
+func (db *DB) GetAllCraftsByPortfolioID(ctx context.Context, portfolioID int, page models.Page) ([]models.Craft, error) {
+	pipeline := append(craftsByPortfolioID(portfolioID), positionPageStages(page)...)
+
+	crafts, err := db.aggregateCrafts(ctx, append(pipeline, previewStages...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crafts by portfolio id: %w", err)
+	}
+
+	return crafts, nil
+}
+
+func (db *DB) GetAllCraftsByTagID(ctx context.Context, tagID int, page models.Page) ([]models.Craft, error) {
+	pipeline := append(craftsByTagID(tagID), pageStages(page)...)
+
+	crafts, err := db.aggregateCrafts(ctx, append(pipeline, previewStages...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crafts by tag id: %w", err)
+	}
+
+	return crafts, nil
+}
+
+func (db *DB) CountCraftsPages(ctx context.Context, id int, isPortfolioID bool) (int, error) {
+	var pipeline mongo.Pipeline
+	if isPortfolioID {
+		pipeline = craftsByPortfolioID(id)
+	} else {
+		pipeline = craftsByTagID(id)
+	}
+
+	amount, err := countDocuments(ctx, db.portfolios, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count crafts: %w", err)
+	}
+
+	return amount, nil
+}
+
+// notDeleted matches documents which are not in the trash, deleted_at is either null or missing
+var notDeleted = bson.E{Key: "deleted_at", Value: nil}
+
+// notDeletedContents is an expression for contents of the craft without deleted ones
+var notDeletedContents = constructor("$filter", bson.D{
+	{Key: "input", Value: "$contents"},
+	{Key: "as", Value: "content"},
+	{Key: "cond", Value: constructor("$eq", bson.A{constructor("$ifNull", bson.A{"$$content.deleted_at", nil}), nil})},
+})
+
+// sortedContents is an expression for not deleted contents of the craft ordered by position and id
+var sortedContents = constructor("$sortArray", bson.D{
+	{Key: "input", Value: notDeletedContents},
+	{Key: "sortBy", Value: bson.D{{Key: "position", Value: 1}, {Key: "_id", Value: 1}}},
+})
+
+// previewStages leave only the first content of the craft as its preview without data of the content and its thumbnails
+var previewStages = mongo.Pipeline{
+	constructor("$set", constructor("contents", constructor("$slice", bson.A{sortedContents, 1}))),
+	constructor("$unset", bson.A{"contents.data", "contents.thumbnails.data"}),
+}
+
+// craftRoot replaces the unwound portfolio by its craft and keeps ids of the portfolio and its profile in the craft
+var craftRoot = constructor("$replaceRoot", constructor("newRoot", constructor("$mergeObjects", bson.A{
+	"$crafts",
+	bson.D{{Key: "portfolio_id", Value: "$_id"}, {Key: "profile_id", Value: "$profile_id"}},
+})))
+
+// craftElement matches portfolio by its craft, deletedAt is a condition for deleted_at of the craft
+func craftElement(craftID int, deletedAt interface{}) bson.D {
+	return constructor("crafts", constructor("$elemMatch", bson.D{{Key: "_id", Value: craftID}, {Key: "deleted_at", Value: deletedAt}}))
+}
+
+func craftsByPortfolioID(portfolioID int) mongo.Pipeline {
+	return mongo.Pipeline{
+		constructor("$match", bson.D{{Key: "_id", Value: portfolioID}, notDeleted}),
+		constructor("$unwind", "$crafts"),
+		craftRoot,
+		constructor("$match", bson.D{notDeleted}),
+		constructor("$sort", bson.D{{Key: "position", Value: 1}, {Key: "_id", Value: 1}}),
+	}
+}
+
+// positionPageStages returns aggregation stages for the page of crafts sorted by position and _id
+func positionPageStages(page models.Page) mongo.Pipeline {
+	afterPosition := constructor("$or", bson.A{
+		constructor("position", constructor("$gt", page.AfterPosition())),
+		bson.D{{Key: "position", Value: page.AfterPosition()}, {Key: "_id", Value: constructor("$gt", page.AfterID())}},
+	})
+
+	return mongo.Pipeline{
+		constructor("$match", afterPosition),
+		constructor("$skip", page.Offset),
+		constructor("$limit", page.Limit),
+	}
+}
+
+func craftsByTagID(tagID int) mongo.Pipeline {
+	return mongo.Pipeline{
+		constructor("$match", bson.D{{Key: "crafts.tags._id", Value: tagID}, notDeleted}),
+		constructor("$unwind", "$crafts"),
+		craftRoot,
+		constructor("$match", bson.D{{Key: "tags._id", Value: tagID}, notDeleted}),
+		constructor("$sort", constructor("_id", 1)),
+	}
+}
+
+func (db *DB) aggregateCrafts(ctx context.Context, pipeline mongo.Pipeline) ([]models.Craft, error) {
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var crafts []models.Craft
+	if err = cursor.All(ctx, &crafts); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+
+	return crafts, nil
+}
@@ -2,22 +2,47 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"time"
+	"log/slog"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
 )
 
+const (
+	categoriesCollection = "categories"
+	tagsCollection       = "tags"
+	countersCollection   = "counters"
+	revisionsCollection  = "revisions"
+)
+
+// sequence names for the counters collection, mongo has no autoincrement
+const (
+	portfoliosSeq = "portfolios"
+	categoriesSeq = "categories"
+	craftsSeq     = "crafts"
+	tagsSeq       = "tags"
+	contentsSeq   = "contents"
+)
+
+// DB keeps portfolios as documents with embedded crafts and contents, categories and tags have their own collections
 type DB struct {
-	*mongo.Collection
+	client     *mongo.Client
+	portfolios *mongo.Collection
+	categories *mongo.Collection
+	tags       *mongo.Collection
+	counters   *mongo.Collection
+	revisions  *mongo.Collection
+	logger     *slog.Logger
 }
 
-func NewDB(cfg config.Mongo) (*mongo.Collection, error) {
+func NewDB(ctx context.Context, cfg config.Mongo, logger *slog.Logger) (*DB, error) {
 	var connectStr string
 	var connectOpt options.ClientOptions
 
@@ -31,198 +56,130 @@ func NewDB(cfg config.Mongo) (*mongo.Collection, error) {
 		connectOpt.SetAuth(options.Credential{Username: cfg.User, Password: cfg.Password, AuthSource: cfg.AuthenticationDatabase})
 	}
 
-	client, err := mongo.Connect(context.Background(), connectOpt.ApplyURI(connectStr))
+	client, err := mongo.Connect(ctx, connectOpt.ApplyURI(connectStr))
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	timeout, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel() //TODO????
-
-	if err = client.Ping(timeout, nil); err != nil {
+	if err = client.Ping(ctx, nil); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	db := client.Database(cfg.Database).Collection(cfg.Collection)
+	database := client.Database(cfg.Database)
+	db := &DB{
+		client:     client,
+		portfolios: database.Collection(cfg.Collection),
+		categories: database.Collection(categoriesCollection),
+		tags:       database.Collection(tagsCollection),
+		counters:   database.Collection(countersCollection),
+		revisions:  database.Collection(revisionsCollection),
+		logger:     logger,
+	}
 
 	if !cfg.HaveIndexes {
-		models := []mongo.IndexModel{
-			{Keys: bson.D{{"profile_id", 1}}, Options: options.Index().SetName("profile_id_idx")},
-			{Keys: bson.D{{"category", 1}}, Options: options.Index().SetName("category_idx")},
-		}
-
-		timeout, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel() //TODO????
-
-		if _, err = db.Indexes().CreateMany(timeout, models); err != nil {
-			return nil, fmt.Errorf("failed to create profile id index: %w", err)
+		if err = db.createIndexes(ctx); err != nil {
+			return nil, err
 		}
 	}
 
 	return db, nil
 }
 
-// ByProfileID returns filter by profile ID
-func ByProfileID(id int) bson.D {
-	return constructor("profile_id", id)
-}
-
-// ByCategory returns filter by category
-func ByCategory(category string) bson.D {
-	return constructor("category", category)
-}
-
-func constructor(key string, value interface{}) bson.D {
-	return bson.D{{key, value}}
-}
-
-func CreatePortfolio(ctx context.Context, db *mongo.Collection, portfolio models.Portfolio) error {
-	if _, err := db.InsertOne(ctx, portfolio); err != nil {
-		return fmt.Errorf("failed to create portfolio: %w", err)
+func (db *DB) createIndexes(ctx context.Context) error {
+	models := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "profile_id", Value: 1}}, Options: options.Index().SetName("profile_id_idx")},
+		{Keys: bson.D{{Key: "category._id", Value: 1}}, Options: options.Index().SetName("category_idx")},
+		{Keys: bson.D{{Key: "crafts._id", Value: 1}}, Options: options.Index().SetName("craft_id_idx")},
+		{Keys: bson.D{{Key: "crafts.tags._id", Value: 1}}, Options: options.Index().SetName("craft_tag_id_idx")},
+		{Keys: bson.D{{Key: "crafts.contents._id", Value: 1}}, Options: options.Index().SetName("content_id_idx")},
 	}
 
-	return nil
-}
-
-func DeletePortfolio(ctx context.Context, db *mongo.Collection, portfolioID string) error {
-	if _, err := db.DeleteOne(ctx, constructor("_id", portfolioID)); err != nil {
-		return fmt.Errorf("failed to delete portfolio: %w", err)
+	if _, err := db.portfolios.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
 	}
 
-	return nil
-}
-
-func GetAllPortfolios(ctx context.Context, db *mongo.Collection, filter ...bson.D) ([]models.Portfolio, error) {
-	if filter == nil {
-		filter = []bson.D{{}}
+	revisionsModels := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "object", Value: 1}, {Key: "object_id", Value: 1}, {Key: "revision", Value: 1}}, Options: options.Index().SetName("object_revision_idx").SetUnique(true)},
+		{Keys: bson.D{{Key: "portfolio_id", Value: 1}}, Options: options.Index().SetName("portfolio_id_idx")},
 	}
 
-	cursor, err := db.Find(ctx, filter[0], options.Find().SetProjection(bson.D{{"crafts", 0}}))
-
-	var portfolios []models.Portfolio
-	if err = cursor.All(context.Background(), &portfolios); err != nil {
-		return nil, fmt.Errorf("failed to get portfolios: %w", err)
+	if _, err := db.revisions.Indexes().CreateMany(ctx, revisionsModels); err != nil {
+		return fmt.Errorf("failed to create indexes: %w", err)
 	}
 
-	return portfolios, nil
-}
-
-func GetPortfolio(ctx context.Context, db *mongo.Collection, portfolioID string) (*models.Portfolio, error) {
-	filter := constructor("_id", portfolioID)
+	db.logger.Info("mongo indexes are created", slog.String("collection", db.portfolios.Name()))
 
-	var portfolio models.Portfolio
-	if err := db.FindOne(ctx, filter, options.FindOne().SetProjection(bson.D{{"crafts", 0}})).Decode(&portfolio); err != nil {
-		return nil, fmt.Errorf("failed to get portfolio: %w", err)
-	}
-
-	return &portfolio, nil
+	return nil
 }
 
-func PatchPortfolio(ctx context.Context, db *mongo.Collection, portfolio models.Portfolio) error {
-	filter := constructor("_id", portfolio.ID)
-	update := bson.D{{"$set", bson.D{{"name", portfolio.Name}, {"category", portfolio.Category}, {"description", portfolio.Description}}}}
-
-	var oldPortfolio models.Portfolio
-	if err := db.FindOneAndUpdate(ctx, filter, update).Decode(&oldPortfolio); err != nil {
-		return fmt.Errorf("failed to update portfolio: %w", err)
-	}
-
-	return nil
+func (db *DB) Close(ctx context.Context) error {
+	return db.client.Disconnect(ctx)
 }
 
-func CreateCraft(ctx context.Context, db *mongo.Collection, portfolio models.Portfolio) error {
-	filter := constructor("_id", portfolio.ID)
-	update := constructor("$push", constructor("crafts", portfolio.Crafts[0]))
+// nextID returns the next value of the named sequence
+func (db *DB) nextID(ctx context.Context, sequence string) (int, error) {
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
 
-	var oldPortfolio models.Portfolio
-	if err := db.FindOneAndUpdate(ctx, filter, update).Decode(&oldPortfolio); err != nil {
-		return fmt.Errorf("failed to create a new craft: %w", err)
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	if err := db.counters.FindOneAndUpdate(ctx, constructor("_id", sequence), constructor("$inc", constructor("seq", 1)), opts).Decode(&counter); err != nil {
+		return 0, fmt.Errorf("failed to get next %s id: %w", sequence, err)
 	}
 
-	return nil
+	return counter.Seq, nil
 }
 
-func DeleteCraft(ctx context.Context, db *mongo.Collection, portfolio models.Portfolio) error {
-	filter := constructor("_id", portfolio.ID)
-	update := constructor("$pull", constructor("crafts", constructor("_id", portfolio.Crafts[0].ID)))
-
-	var oldPortfolio models.Portfolio
-	if err := db.FindOneAndUpdate(ctx, filter, update).Decode(&oldPortfolio); err != nil {
-		return fmt.Errorf("failed to delete craft: %w", err)
-	}
-	return nil
+func constructor(key string, value interface{}) bson.D {
+	return bson.D{{Key: key, Value: value}}
 }
 
-func PatchCraft(ctx context.Context, db *mongo.Collection, portfolio models.Portfolio) error {
-	filter := bson.D{{"crafts._id", portfolio.Crafts[0].ID}}
-	update := constructor("$set", constructor("crafts.name", portfolio.Crafts[0].Name))
-
-	var oldCraft models.Craft
-	if err := db.FindOneAndUpdate(ctx, filter, update).Decode(&oldCraft); err != nil {
-		return fmt.Errorf("failed to update craft: %w", err)
+// notFound replaces mongo.ErrNoDocuments with the error the api layer understands
+func notFound(err error) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return response_errors.ErrNotFound
 	}
-	//TODO
-	return nil
+	return err
 }
 
-func GetCraft(ctx context.Context, db *mongo.Collection, portfolio models.Portfolio) (*models.Craft, error) {
-	filter := constructor("_id", portfolio.ID)
-
-	var craft models.Craft
-	if err := db.FindOne(ctx, filter).Decode(&craft); err != nil {
-		return nil, fmt.Errorf("failed to get craft: %w", err)
+// pageStages returns aggregation stages for the page of documents sorted by _id
+func pageStages(page models.Page) mongo.Pipeline {
+	return mongo.Pipeline{
+		constructor("$match", afterID(page)),
+		constructor("$skip", page.Offset),
+		constructor("$limit", page.Limit),
 	}
-
-	return &craft, nil
 }
 
-func GetAllCrafts(ctx context.Context, db *mongo.Collection, portfolioID int) (*models.Portfolio, error) {
-	filter := constructor("_id", portfolioID)
-
-	var portfolio models.Portfolio
-	if err := db.FindOne(ctx, filter).Decode(&portfolio); err != nil {
-		return nil, fmt.Errorf("failed to get portfolio: %w", err)
-	}
-
-	for i := range portfolio.Crafts {
-		portfolio.Crafts[i].Contents = portfolio.Crafts[i].Contents[:1]
-	}
-
-	return &portfolio, nil
+// findPage returns find options for the page of documents sorted by _id
+func findPage(page models.Page) *options.FindOptions {
+	return options.Find().SetSort(constructor("_id", 1)).SetSkip(int64(page.Offset)).SetLimit(int64(page.Limit))
 }
 
-func CreateContent(ctx context.Context, db *mongo.Collection, craft models.Craft) error {
-	filter := constructor("craft_id", craft.ID)
-	update := bson.D{{"$push", bson.D{{"contents", craft.Contents[0]}}}}
-
-	var oldCraft models.Craft
-	if err := db.FindOneAndUpdate(ctx, filter, update).Decode(&oldCraft); err != nil {
-		return fmt.Errorf("failed to create a new content: %w", err)
-	}
-
-	return nil
+// afterID returns filter for keyset pagination, it matches all documents in page number mode
+func afterID(page models.Page) bson.D {
+	return constructor("_id", constructor("$gt", page.AfterID()))
 }
 
-func DeleteContent(ctx context.Context, db *mongo.Collection, craft models.Craft) error {
-	filter := constructor("craft_id", craft.ID)
-	update := bson.D{{"$pull", constructor("content_id", craft.Contents[0].ID)}}
+func countDocuments(ctx context.Context, collection *mongo.Collection, pipeline mongo.Pipeline) (int, error) {
+	pipeline = append(pipeline, constructor("$count", "amount"))
 
-	var oldCraft models.Craft
-	if err := db.FindOneAndUpdate(ctx, filter, update).Decode(&oldCraft); err != nil {
-		return fmt.Errorf("failed to delete content: %w", err)
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
 	}
+	defer cursor.Close(ctx)
 
-	return nil
-}
-
-func PatchContent(ctx context.Context, db *mongo.Collection, content models.Content) error {
-	filter := constructor("content_id", content.ID)
-	update := bson.D{{"$set", bson.D{{"content_description", content.Description}}}}
+	var result []struct {
+		Amount int `bson:"amount"`
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return 0, err
+	}
 
-	var oldContent models.Content
-	if err := db.FindOneAndUpdate(ctx, filter, update).Decode(&oldContent); err != nil {
-		return fmt.Errorf("failed to update content: %w", err)
+	if len(result) == 0 {
+		return 0, nil
 	}
 
-	return nil
+	return result[0].Amount, nil
 }
@@ -0,0 +1,31 @@
+package mongodb
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// ByProfileID returns filter by profile ID
+func ByProfileID(id int) bson.D {
+	return constructor("profile_id", id)
+}
+
+// ByCategoryID returns filter by category ID
+func ByCategoryID(id int) bson.D {
+	return constructor("category._id", id)
+}
+
+func portfolioFilter(filterType models.PortfoliosFilterType, id int) (bson.D, error) {
+	switch filterType {
+	case models.FilterEmpty:
+		return bson.D{}, nil
+	case models.FilterByProfileID:
+		return ByProfileID(id), nil
+	case models.FilterByCategoryID:
+		return ByCategoryID(id), nil
+	default:
+		return nil, response_errors.ErrIncorrectPortfoliosFilterType
+	}
+}
@@ -0,0 +1,135 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// nextPosition returns the position after the last one of the children found by the pipeline, it expects children to be the roots
+func (db *DB) nextPosition(ctx context.Context, pipeline mongo.Pipeline) (int, error) {
+	pipeline = append(pipeline, constructor("$group", bson.D{{Key: "_id", Value: nil}, {Key: "position", Value: constructor("$max", "$position")}}))
+
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next position: %w", err)
+	}
+
+	var result []struct {
+		Position int `bson:"position"`
+	}
+	if err = cursor.All(ctx, &result); err != nil {
+		return 0, fmt.Errorf("failed to get next position: decode error: %w", err)
+	}
+
+	if len(result) == 0 {
+		return 0, nil
+	}
+
+	return result[0].Position + 1, nil
+}
+
+// ReorderCrafts sets positions of not deleted crafts of the portfolio by the order in one update, deleted crafts keep their positions
+func (db *DB) ReorderCrafts(ctx context.Context, portfolioID int, order models.Order) error {
+	filter := bson.D{{Key: "_id", Value: portfolioID}, notDeleted}
+
+	ids, err := db.childrenIDs(ctx, filter, append(craftsByPortfolioID(portfolioID), constructor("$project", constructor("_id", 1))))
+	if err != nil {
+		return fmt.Errorf("failed to reorder crafts: %w", err)
+	}
+
+	if !order.Matches(ids) {
+		return fmt.Errorf("failed to reorder crafts: %w", response_errors.ErrIncorrectOrder)
+	}
+	if len(order.IDs) == 0 {
+		return nil
+	}
+
+	var update bson.D
+	var filters []interface{}
+	for position, id := range order.IDs {
+		update = append(update, bson.E{Key: fmt.Sprintf("crafts.$[c%d].position", position), Value: position})
+		filters = append(filters, constructor(fmt.Sprintf("c%d._id", position), id))
+	}
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: filters})
+	if _, err = db.portfolios.UpdateOne(ctx, filter, constructor("$set", update), opts); err != nil {
+		return fmt.Errorf("failed to reorder crafts: %w", err)
+	}
+
+	return nil
+}
+
+// ReorderContents sets positions of not deleted contents of the craft by the order in one update, deleted contents keep their positions
+func (db *DB) ReorderContents(ctx context.Context, craftID int, order models.Order) error {
+	filter := append(bson.D{notDeleted}, craftElement(craftID, nil)...)
+
+	ids, err := db.childrenIDs(ctx, filter, mongo.Pipeline{
+		constructor("$match", filter),
+		constructor("$unwind", "$crafts"),
+		constructor("$match", constructor("crafts._id", craftID)),
+		constructor("$unwind", "$crafts.contents"),
+		constructor("$match", constructor("crafts.contents.deleted_at", nil)),
+		constructor("$project", constructor("_id", "$crafts.contents._id")),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reorder contents: %w", err)
+	}
+
+	if !order.Matches(ids) {
+		return fmt.Errorf("failed to reorder contents: %w", response_errors.ErrIncorrectOrder)
+	}
+	if len(order.IDs) == 0 {
+		return nil
+	}
+
+	var update bson.D
+	filters := []interface{}{constructor("craft._id", craftID)}
+	for position, id := range order.IDs {
+		update = append(update, bson.E{Key: fmt.Sprintf("crafts.$[craft].contents.$[c%d].position", position), Value: position})
+		filters = append(filters, constructor(fmt.Sprintf("c%d._id", position), id))
+	}
+
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: filters})
+	if _, err = db.portfolios.UpdateOne(ctx, filter, constructor("$set", update), opts); err != nil {
+		return fmt.Errorf("failed to reorder contents: %w", err)
+	}
+
+	return nil
+}
+
+// childrenIDs returns ids found by the pipeline, ErrNotFound is returned if no portfolio matches the parent filter
+func (db *DB) childrenIDs(ctx context.Context, parent bson.D, pipeline mongo.Pipeline) ([]int, error) {
+	amount, err := db.portfolios.CountDocuments(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	if amount == 0 {
+		return nil, response_errors.ErrNotFound
+	}
+
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []struct {
+		ID int `bson:"_id"`
+	}
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+
+	var ids []int
+	for _, document := range documents {
+		ids = append(ids, document.ID)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,39 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// CheckPath checks that each object of the path belongs to the previous one, objects in the trash are checked too.
+// Crafts and contents are embedded into the portfolio, so the whole path is matched by one document
+func (db *DB) CheckPath(ctx context.Context, path models.Path) error {
+	if path.PortfolioID == 0 {
+		return nil
+	}
+
+	filter := bson.D{{Key: "_id", Value: path.PortfolioID}, {Key: "profile_id", Value: path.ProfileID}}
+	if path.CraftID != 0 {
+		craft := bson.D{{Key: "_id", Value: path.CraftID}}
+		if path.ContentID != 0 {
+			craft = append(craft, bson.E{Key: "contents._id", Value: path.ContentID})
+		}
+		filter = append(filter, bson.E{Key: "crafts", Value: constructor("$elemMatch", craft)})
+	}
+
+	amount, err := db.portfolios.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return fmt.Errorf("failed to check path: %w", err)
+	}
+	if amount == 0 {
+		return fmt.Errorf("failed to check path: %w", response_errors.ErrPathNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+var withoutCrafts = constructor("crafts", 0)
+
+func (db *DB) CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (int, error) {
+	category, err := db.getCategory(ctx, portfolio.Category.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create portfolio: %w", err)
+	}
+
+	id, err := db.nextID(ctx, portfoliosSeq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create portfolio: %w", err)
+	}
+
+	portfolio.ID = id
+	portfolio.Category = *category
+	portfolio.Crafts = nil
+
+	if _, err = db.portfolios.InsertOne(ctx, portfolio); err != nil {
+		return 0, fmt.Errorf("failed to create portfolio: creation error: %w", err)
+	}
+
+	return id, nil
+}
+
+func (db *DB) CreateCategory(ctx context.Context, name string) (int, error) {
+	id, err := db.nextID(ctx, categoriesSeq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	if _, err = db.categories.InsertOne(ctx, models.Category{ID: id, Name: name}); err != nil {
+		return 0, fmt.Errorf("failed to create category: %w", err)
+	}
+
+	return id, nil
+}
+
+func (db *DB) DeleteCategory(ctx context.Context, id int) error {
+	used, err := db.portfolios.CountDocuments(ctx, ByCategoryID(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if used != 0 {
+		return fmt.Errorf("failed to delete category: category is used by %d portfolios", used)
+	}
+
+	if _, err = db.categories.DeleteOne(ctx, constructor("_id", id)); err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+
+	return nil
+}
+
+// getCategory returns the category to be embedded into portfolios, pending categories can't be used
+func (db *DB) getCategory(ctx context.Context, id int) (*models.Category, error) {
+	var category models.Category
+	if err := db.categories.FindOne(ctx, constructor("_id", id)).Decode(&category); err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", notFound(err))
+	}
+	if category.Status == models.StatusPending {
+		return nil, fmt.Errorf("failed to get category: %w", response_errors.ErrNotApproved)
+	}
+
+	return &category, nil
+}
+
+// GetAllCategories returns approved categories or pending ones with their proposers
+func (db *DB) GetAllCategories(ctx context.Context, page models.Page, pending bool) ([]models.Category, error) {
+	cursor, err := db.categories.Find(ctx, append(afterID(page), byStatus(pending)), findPage(page))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all categories: %w", err)
+	}
+
+	var categories []models.Category
+	if err = cursor.All(ctx, &categories); err != nil {
+		return nil, fmt.Errorf("failed to get all categories: decode error: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (db *DB) CountCategoriesPages(ctx context.Context, pending bool) (int, error) {
+	amount, err := db.categories.CountDocuments(ctx, bson.D{byStatus(pending)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count categories: %w", err)
+	}
+
+	return int(amount), nil
+}
+
+func (db *DB) DeletePortfolio(ctx context.Context, portfolioID int) error {
+	update := constructor("$set", constructor("deleted_at", time.Now()))
+
+	if _, err := db.portfolios.UpdateOne(ctx, bson.D{{Key: "_id", Value: portfolioID}, notDeleted}, update); err != nil {
+		return fmt.Errorf("failed to delete portfolio: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) PatchPortfolio(ctx context.Context, authorID int, portfolio models.Portfolio) error {
+	category, err := db.getCategory(ctx, portfolio.Category.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update portfolio: %w", err)
+	}
+
+	if err = db.addRevision(ctx, models.ObjectPortfolio, portfolio.ID, authorID); err != nil {
+		return fmt.Errorf("failed to update portfolio: %w", err)
+	}
+
+	update := constructor("$set", bson.D{
+		{Key: "name", Value: portfolio.Name},
+		{Key: "description", Value: portfolio.Description},
+		{Key: "category", Value: category},
+	})
+
+	if _, err = db.portfolios.UpdateOne(ctx, bson.D{{Key: "_id", Value: portfolio.ID}, notDeleted}, update); err != nil {
+		return fmt.Errorf("failed to update portfolio: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetPortfolioByID(ctx context.Context, portfolioID int) (*models.Portfolio, error) {
+	var portfolio models.Portfolio
+	if err := db.portfolios.FindOne(ctx, bson.D{{Key: "_id", Value: portfolioID}, notDeleted}, options.FindOne().SetProjection(withoutCrafts)).Decode(&portfolio); err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", notFound(err))
+	}
+
+	return &portfolio, nil
+}
+
+func (db *DB) GetAllPortfolios(ctx context.Context, page models.Page, id int, filterType models.PortfoliosFilterType) ([]models.Portfolio, error) {
+	filter, err := portfolioFilter(filterType, id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter = append(filter, notDeleted)
+
+	cursor, err := db.portfolios.Find(ctx, append(filter, afterID(page)...), findPage(page).SetProjection(withoutCrafts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolios: %w", err)
+	}
+
+	var portfolios []models.Portfolio
+	if err = cursor.All(ctx, &portfolios); err != nil {
+		return nil, fmt.Errorf("failed to get portfolios: decode error: %w", err)
+	}
+
+	return portfolios, nil
+}
+
+func (db *DB) CountPortfoliosPages(ctx context.Context, id int, filterType models.PortfoliosFilterType) (int, error) {
+	filter, err := portfolioFilter(filterType, id)
+	if err != nil {
+		return 0, err
+	}
+
+	amount, err := db.portfolios.CountDocuments(ctx, append(filter, notDeleted))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count portfolios: %w", err)
+	}
+
+	return int(amount), nil
+}
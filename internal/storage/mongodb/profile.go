@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// DeleteProfilePortfolios deletes all portfolios of the profile including ones in the trash together with their revisions
+// and returns deleted portfolios, crafts and contents are removed with them and are not returned
+func (db *DB) DeleteProfilePortfolios(ctx context.Context, profileID int) ([]models.TrashItem, error) {
+	portfolios, err := db.findTrash(ctx, mongo.Pipeline{constructor("$match", constructor("profile_id", profileID)), trashPortfolios})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete portfolios of profile %d: %w", profileID, err)
+	}
+
+	var deleted []models.TrashItem
+	for _, document := range portfolios {
+		result, err := db.portfolios.DeleteOne(ctx, bson.D{{Key: "_id", Value: document.ID}, {Key: "profile_id", Value: profileID}})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete portfolios of profile %d: %w", profileID, err)
+		}
+		if result.DeletedCount == 0 {
+			continue
+		}
+		deleted = append(deleted, document.model(models.ObjectPortfolio))
+
+		if _, err = db.revisions.DeleteMany(ctx, constructor("portfolio_id", document.ID)); err != nil {
+			return deleted, fmt.Errorf("failed to delete portfolios of profile %d: revisions: %w", profileID, err)
+		}
+	}
+
+	return deleted, nil
+}
+
+// AnonymizeProfilePortfolios moves all portfolios of the profile to the anonymous profile, replaces the profile
+// in revisions by it and returns moved portfolios
+func (db *DB) AnonymizeProfilePortfolios(ctx context.Context, profileID, anonymousID int) ([]models.TrashItem, error) {
+	portfolios, err := db.findTrash(ctx, mongo.Pipeline{constructor("$match", constructor("profile_id", profileID)), trashPortfolios})
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize portfolios of profile %d: %w", profileID, err)
+	}
+
+	var anonymized []models.TrashItem
+	for _, document := range portfolios {
+		filter := bson.D{{Key: "_id", Value: document.ID}, {Key: "profile_id", Value: profileID}}
+		result, err := db.portfolios.UpdateOne(ctx, filter, constructor("$set", constructor("profile_id", anonymousID)))
+		if err != nil {
+			return anonymized, fmt.Errorf("failed to anonymize portfolios of profile %d: %w", profileID, err)
+		}
+		if result.ModifiedCount != 0 {
+			anonymized = append(anonymized, document.model(models.ObjectPortfolio))
+		}
+	}
+
+	_, err = db.revisions.UpdateMany(ctx, constructor("author_id", profileID), constructor("$set", constructor("author_id", anonymousID)))
+	if err != nil {
+		return anonymized, fmt.Errorf("failed to anonymize portfolios of profile %d: revisions: %w", profileID, err)
+	}
+
+	return anonymized, nil
+}
@@ -0,0 +1,216 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// revisionDocument keeps values of the object before the change, portfolio and craft ids are used to delete revisions with the object
+type revisionDocument struct {
+	Object      string    `bson:"object"`
+	ObjectID    int       `bson:"object_id"`
+	PortfolioID int       `bson:"portfolio_id"`
+	CraftID     int       `bson:"craft_id,omitempty"`
+	Number      int       `bson:"revision"`
+	AuthorID    int       `bson:"author_id"`
+	CreatedAt   time.Time `bson:"created_at"`
+	Name        string    `bson:"name,omitempty"`
+	Description string    `bson:"description"`
+	CategoryID  int       `bson:"category_id,omitempty"`
+}
+
+func (rd revisionDocument) model() models.Revision {
+	return models.Revision{
+		Object:      rd.Object,
+		ObjectID:    rd.ObjectID,
+		Number:      rd.Number,
+		AuthorID:    rd.AuthorID,
+		CreatedAt:   rd.CreatedAt,
+		Name:        rd.Name,
+		Description: rd.Description,
+		CategoryID:  rd.CategoryID,
+	}
+}
+
+func revisionFilter(object string, id int) bson.D {
+	return bson.D{{Key: "object", Value: object}, {Key: "object_id", Value: id}}
+}
+
+// currentValues returns values of the visible object as they are kept in revisions
+func (db *DB) currentValues(ctx context.Context, object string, id int) (*revisionDocument, error) {
+	var pipeline mongo.Pipeline
+
+	switch object {
+	case models.ObjectPortfolio:
+		pipeline = mongo.Pipeline{
+			constructor("$match", bson.D{{Key: "_id", Value: id}, notDeleted}),
+			constructor("$project", bson.D{
+				{Key: "object_id", Value: "$_id"},
+				{Key: "portfolio_id", Value: "$_id"},
+				{Key: "name", Value: 1},
+				{Key: "description", Value: 1},
+				{Key: "category_id", Value: "$category._id"},
+			}),
+		}
+	case models.ObjectCraft:
+		pipeline = mongo.Pipeline{
+			constructor("$match", bson.D{{Key: "crafts._id", Value: id}, notDeleted}),
+			constructor("$unwind", "$crafts"),
+			constructor("$match", bson.D{{Key: "crafts._id", Value: id}, {Key: "crafts.deleted_at", Value: nil}}),
+			constructor("$project", bson.D{
+				{Key: "object_id", Value: "$crafts._id"},
+				{Key: "portfolio_id", Value: "$_id"},
+				{Key: "name", Value: "$crafts.craft_name"},
+				{Key: "description", Value: "$crafts.craft_description"},
+			}),
+		}
+	case models.ObjectContent:
+		pipeline = mongo.Pipeline{
+			constructor("$match", bson.D{{Key: "crafts.contents._id", Value: id}, notDeleted}),
+			constructor("$unwind", "$crafts"),
+			constructor("$match", constructor("crafts.deleted_at", nil)),
+			constructor("$unwind", "$crafts.contents"),
+			constructor("$match", bson.D{{Key: "crafts.contents._id", Value: id}, {Key: "crafts.contents.deleted_at", Value: nil}}),
+			constructor("$project", bson.D{
+				{Key: "object_id", Value: "$crafts.contents._id"},
+				{Key: "portfolio_id", Value: "$_id"},
+				{Key: "craft_id", Value: "$crafts._id"},
+				{Key: "description", Value: "$crafts.contents.content_description"},
+			}),
+		}
+	default:
+		return nil, fmt.Errorf("unknown object of revisions: %s", object)
+	}
+
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []revisionDocument
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+
+	if len(documents) == 0 {
+		return nil, response_errors.ErrNotFound
+	}
+
+	documents[0].Object = object
+	return &documents[0], nil
+}
+
+// addRevision saves current values of the visible object as its next revision, returns ErrNotFound if there is no such object
+func (db *DB) addRevision(ctx context.Context, object string, id, authorID int) error {
+	current, err := db.currentValues(ctx, object, id)
+	if err != nil {
+		return err
+	}
+
+	current.Number, err = db.nextID(ctx, fmt.Sprintf("revisions.%s.%d", object, id))
+	if err != nil {
+		return err
+	}
+	current.AuthorID = authorID
+	current.CreatedAt = time.Now()
+
+	if _, err = db.revisions.InsertOne(ctx, current); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) Revert(ctx context.Context, authorID int, object string, id, revision int) error {
+	var values revisionDocument
+	if err := db.revisions.FindOne(ctx, append(revisionFilter(object, id), bson.E{Key: "revision", Value: revision})).Decode(&values); err != nil {
+		return fmt.Errorf("failed to revert %s: revision: %w", object, notFound(err))
+	}
+
+	var filter, update bson.D
+	var opts []*options.UpdateOptions
+
+	switch object {
+	case models.ObjectPortfolio:
+		category, err := db.getCategory(ctx, values.CategoryID)
+		if err != nil {
+			return fmt.Errorf("failed to revert %s: %w", object, err)
+		}
+
+		filter = bson.D{{Key: "_id", Value: id}, notDeleted}
+		update = constructor("$set", bson.D{
+			{Key: "name", Value: values.Name},
+			{Key: "description", Value: values.Description},
+			{Key: "category", Value: category},
+		})
+	case models.ObjectCraft:
+		filter = craftElement(id, nil)
+		update = constructor("$set", bson.D{
+			{Key: "crafts.$.craft_name", Value: values.Name},
+			{Key: "crafts.$.craft_description", Value: values.Description},
+		})
+	case models.ObjectContent:
+		filter = constructor("crafts.contents._id", id)
+		update = constructor("$set", constructor("crafts.$[craft].contents.$[content].content_description", values.Description))
+		opts = append(opts, contentArrayFilters(id, nil))
+	}
+
+	if err := db.addRevision(ctx, object, id, authorID); err != nil {
+		return fmt.Errorf("failed to revert %s: %w", object, err)
+	}
+
+	if _, err := db.portfolios.UpdateOne(ctx, filter, update, opts...); err != nil {
+		return fmt.Errorf("failed to revert %s: %w", object, err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetRevisions(ctx context.Context, object string, id int, page models.Page) ([]models.Revision, error) {
+	filter := append(revisionFilter(object, id), bson.E{Key: "revision", Value: constructor("$gt", page.AfterID())})
+	opts := options.Find().SetSort(constructor("revision", 1)).SetSkip(int64(page.Offset)).SetLimit(int64(page.Limit))
+
+	cursor, err := db.revisions.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+
+	var documents []revisionDocument
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("failed to get revisions: decode error: %w", err)
+	}
+
+	var revisions []models.Revision
+	for _, document := range documents {
+		revisions = append(revisions, document.model())
+	}
+
+	return revisions, nil
+}
+
+func (db *DB) CountRevisions(ctx context.Context, object string, id int) (int, error) {
+	amount, err := db.revisions.CountDocuments(ctx, revisionFilter(object, id))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	return int(amount), nil
+}
+
+func (db *DB) GetRevision(ctx context.Context, object string, id, revision int) (*models.Revision, error) {
+	var document revisionDocument
+	if err := db.revisions.FindOne(ctx, append(revisionFilter(object, id), bson.E{Key: "revision", Value: revision})).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", notFound(err))
+	}
+
+	result := document.model()
+	return &result, nil
+}
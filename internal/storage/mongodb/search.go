@@ -0,0 +1,18 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// Search is not supported: crafts are embedded into portfolios and mongodb allows only one text index per collection
+func (db *DB) Search(ctx context.Context, query models.SearchQuery, page models.Page) ([]models.SearchResult, error) {
+	return nil, fmt.Errorf("failed to search: %w", response_errors.ErrNotImplemented)
+}
+
+func (db *DB) CountSearchResults(ctx context.Context, query models.SearchQuery) (int, error) {
+	return 0, fmt.Errorf("failed to count search results: %w", response_errors.ErrNotImplemented)
+}
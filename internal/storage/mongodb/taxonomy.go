@@ -0,0 +1,93 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// pending matches proposals, approved categories and tags have no status, like the ones created before proposals
+var pending = bson.E{Key: "status", Value: models.StatusPending}
+
+// byStatus matches approved or pending categories and tags
+func byStatus(isPending bool) bson.E {
+	if isPending {
+		return pending
+	}
+	return bson.E{Key: "status", Value: constructor("$exists", false)}
+}
+
+func (db *DB) ProposeCategory(ctx context.Context, name string, profileID int) (int, error) {
+	id, err := db.nextID(ctx, categoriesSeq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to propose category: %w", err)
+	}
+
+	if _, err = db.categories.InsertOne(ctx, models.Category{ID: id, Name: name, Status: models.StatusPending, ProposedBy: profileID}); err != nil {
+		return 0, fmt.Errorf("failed to propose category: %w", err)
+	}
+
+	return id, nil
+}
+
+func (db *DB) ProposeTag(ctx context.Context, name string, profileID int) (int, error) {
+	id, err := db.nextID(ctx, tagsSeq)
+	if err != nil {
+		return 0, fmt.Errorf("failed to propose tag: %w", err)
+	}
+
+	if _, err = db.tags.InsertOne(ctx, models.Tag{ID: id, Name: name, Status: models.StatusPending, ProposedBy: profileID}); err != nil {
+		return 0, fmt.Errorf("failed to propose tag: %w", err)
+	}
+
+	return id, nil
+}
+
+// ReviewCategory approves the pending category or deletes it if it is rejected
+func (db *DB) ReviewCategory(ctx context.Context, id int, approve bool) error {
+	if err := review(ctx, db.categories, id, approve); err != nil {
+		return fmt.Errorf("failed to review category: %w", err)
+	}
+
+	return nil
+}
+
+// ReviewTag approves the pending tag or deletes it if it is rejected
+func (db *DB) ReviewTag(ctx context.Context, id int, approve bool) error {
+	if err := review(ctx, db.tags, id, approve); err != nil {
+		return fmt.Errorf("failed to review tag: %w", err)
+	}
+
+	return nil
+}
+
+// review returns response_errors.ErrNotFound if there is no such pending document
+func review(ctx context.Context, collection *mongo.Collection, id int, approve bool) error {
+	filter := bson.D{{Key: "_id", Value: id}, pending}
+
+	var changed int64
+	if approve {
+		result, err := collection.UpdateOne(ctx, filter, constructor("$unset", bson.D{{Key: "status", Value: ""}, {Key: "proposed_by", Value: ""}}))
+		if err != nil {
+			return err
+		}
+		changed = result.ModifiedCount
+	} else {
+		result, err := collection.DeleteOne(ctx, filter)
+		if err != nil {
+			return err
+		}
+		changed = result.DeletedCount
+	}
+
+	if changed == 0 {
+		return response_errors.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,246 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+type trashDocument struct {
+	ID          int       `bson:"_id"`
+	ProfileID   int       `bson:"profile_id"`
+	PortfolioID int       `bson:"portfolio_id"`
+	CraftID     int       `bson:"craft_id"`
+	Name        string    `bson:"name"`
+	DeletedAt   time.Time `bson:"deleted_at"`
+}
+
+func (td trashDocument) model(object string) models.TrashItem {
+	return models.TrashItem{Object: object, ID: td.ID, ProfileID: td.ProfileID, PortfolioID: td.PortfolioID, CraftID: td.CraftID, Name: td.Name, DeletedAt: td.DeletedAt}
+}
+
+var (
+	inTrash          = constructor("$ne", nil)
+	trashPortfolios  = constructor("$project", bson.D{{Key: "profile_id", Value: 1}, {Key: "portfolio_id", Value: "$_id"}, {Key: "name", Value: 1}, {Key: "deleted_at", Value: 1}})
+	trashCraftFields = bson.D{
+		{Key: "_id", Value: "$crafts._id"},
+		{Key: "profile_id", Value: 1},
+		{Key: "portfolio_id", Value: "$_id"},
+		{Key: "name", Value: "$crafts.craft_name"},
+		{Key: "deleted_at", Value: "$crafts.deleted_at"},
+	}
+	trashContentFields = bson.D{
+		{Key: "_id", Value: "$crafts.contents._id"},
+		{Key: "profile_id", Value: 1},
+		{Key: "portfolio_id", Value: "$_id"},
+		{Key: "craft_id", Value: "$crafts._id"},
+		{Key: "name", Value: "$crafts.contents.content_description"},
+		{Key: "deleted_at", Value: "$crafts.contents.deleted_at"},
+	}
+)
+
+// trashedCrafts finds crafts of matching portfolios, deletedAt is a condition for deleted_at of the crafts
+func trashedCrafts(portfolioMatch bson.D, deletedAt bson.D) mongo.Pipeline {
+	return mongo.Pipeline{
+		constructor("$match", portfolioMatch),
+		constructor("$unwind", "$crafts"),
+		constructor("$match", constructor("crafts.deleted_at", deletedAt)),
+		constructor("$project", trashCraftFields),
+	}
+}
+
+// trashedContents finds contents of matching crafts, deletedAt is a condition for deleted_at of the contents
+func trashedContents(portfolioMatch bson.D, craftMatch bson.D, deletedAt bson.D) mongo.Pipeline {
+	return mongo.Pipeline{
+		constructor("$match", portfolioMatch),
+		constructor("$unwind", "$crafts"),
+		constructor("$match", craftMatch),
+		constructor("$unwind", "$crafts.contents"),
+		constructor("$match", constructor("crafts.contents.deleted_at", deletedAt)),
+		constructor("$project", trashContentFields),
+	}
+}
+
+func (db *DB) GetTrash(ctx context.Context, profileID int) ([]models.TrashItem, error) {
+	visiblePortfolios := bson.D{{Key: "profile_id", Value: profileID}, notDeleted}
+
+	portfolios, err := db.findTrash(ctx, mongo.Pipeline{constructor("$match", bson.D{{Key: "profile_id", Value: profileID}, {Key: "deleted_at", Value: inTrash}}), trashPortfolios})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: portfolios: %w", err)
+	}
+
+	crafts, err := db.findTrash(ctx, trashedCrafts(visiblePortfolios, inTrash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: crafts: %w", err)
+	}
+
+	contents, err := db.findTrash(ctx, trashedContents(visiblePortfolios, constructor("crafts.deleted_at", nil), inTrash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: contents: %w", err)
+	}
+
+	var items []models.TrashItem
+	for _, document := range portfolios {
+		items = append(items, document.model(models.ObjectPortfolio))
+	}
+	for _, document := range crafts {
+		items = append(items, document.model(models.ObjectCraft))
+	}
+	for _, document := range contents {
+		items = append(items, document.model(models.ObjectContent))
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].DeletedAt.Equal(items[j].DeletedAt) {
+			return items[i].DeletedAt.After(items[j].DeletedAt)
+		}
+		if items[i].Object != items[j].Object {
+			return items[i].Object < items[j].Object
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	return items, nil
+}
+
+func (db *DB) findTrash(ctx context.Context, pipeline mongo.Pipeline) ([]trashDocument, error) {
+	cursor, err := db.portfolios.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []trashDocument
+	if err = cursor.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("decode error: %w", err)
+	}
+
+	return documents, nil
+}
+
+func (db *DB) RestorePortfolio(ctx context.Context, profileID, portfolioID int) error {
+	filter := bson.D{{Key: "_id", Value: portfolioID}, {Key: "profile_id", Value: profileID}, {Key: "deleted_at", Value: inTrash}}
+
+	result, err := db.portfolios.UpdateOne(ctx, filter, constructor("$unset", constructor("deleted_at", "")))
+	if err != nil {
+		return fmt.Errorf("failed to restore portfolio: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("failed to restore portfolio: %w", response_errors.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (db *DB) RestoreCraft(ctx context.Context, profileID, craftID int) error {
+	filter := append(bson.D{{Key: "profile_id", Value: profileID}, notDeleted}, craftElement(craftID, inTrash)...)
+
+	result, err := db.portfolios.UpdateOne(ctx, filter, constructor("$unset", constructor("crafts.$.deleted_at", "")))
+	if err != nil {
+		return fmt.Errorf("failed to restore craft: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("failed to restore craft: %w", response_errors.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (db *DB) RestoreContent(ctx context.Context, profileID, contentID int) error {
+	filter := bson.D{
+		{Key: "profile_id", Value: profileID},
+		notDeleted,
+		{Key: "crafts", Value: constructor("$elemMatch", bson.D{
+			notDeleted,
+			{Key: "contents", Value: constructor("$elemMatch", bson.D{{Key: "_id", Value: contentID}, {Key: "deleted_at", Value: inTrash}})},
+		})},
+	}
+	update := constructor("$unset", constructor("crafts.$[craft].contents.$[content].deleted_at", ""))
+
+	result, err := db.portfolios.UpdateOne(ctx, filter, update, contentArrayFilters(contentID, inTrash))
+	if err != nil {
+		return fmt.Errorf("failed to restore content: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("failed to restore content: %w", response_errors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// PurgeTrash deletes everything deleted before the given time and returns purged items,
+// crafts and contents of purged portfolios and crafts are removed with them and are not returned
+func (db *DB) PurgeTrash(ctx context.Context, before time.Time) ([]models.TrashItem, error) {
+	expired := constructor("$lt", before)
+	var purged []models.TrashItem
+
+	portfolios, err := db.findTrash(ctx, mongo.Pipeline{constructor("$match", constructor("deleted_at", expired)), trashPortfolios})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trash: portfolios: %w", err)
+	}
+	for _, document := range portfolios {
+		// the portfolio may be restored after it was found
+		result, err := db.portfolios.DeleteOne(ctx, bson.D{{Key: "_id", Value: document.ID}, {Key: "deleted_at", Value: expired}})
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge trash: portfolios: %w", err)
+		}
+		if result.DeletedCount != 0 {
+			purged = append(purged, document.model(models.ObjectPortfolio))
+
+			if _, err = db.revisions.DeleteMany(ctx, constructor("portfolio_id", document.ID)); err != nil {
+				return purged, fmt.Errorf("failed to purge trash: revisions: %w", err)
+			}
+		}
+	}
+
+	crafts, err := db.findTrash(ctx, trashedCrafts(bson.D{}, expired))
+	if err != nil {
+		return purged, fmt.Errorf("failed to purge trash: crafts: %w", err)
+	}
+	for _, document := range crafts {
+		update := constructor("$pull", constructor("crafts", bson.D{{Key: "_id", Value: document.ID}, {Key: "deleted_at", Value: expired}}))
+
+		result, err := db.portfolios.UpdateOne(ctx, constructor("_id", document.PortfolioID), update)
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge trash: crafts: %w", err)
+		}
+		if result.ModifiedCount != 0 {
+			purged = append(purged, document.model(models.ObjectCraft))
+
+			filter := constructor("$or", bson.A{revisionFilter(models.ObjectCraft, document.ID), constructor("craft_id", document.ID)})
+			if _, err = db.revisions.DeleteMany(ctx, filter); err != nil {
+				return purged, fmt.Errorf("failed to purge trash: revisions: %w", err)
+			}
+		}
+	}
+
+	contents, err := db.findTrash(ctx, trashedContents(bson.D{}, bson.D{}, expired))
+	if err != nil {
+		return purged, fmt.Errorf("failed to purge trash: contents: %w", err)
+	}
+	for _, document := range contents {
+		update := constructor("$pull", constructor("crafts.$[craft].contents", bson.D{{Key: "_id", Value: document.ID}, {Key: "deleted_at", Value: expired}}))
+		opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{constructor("craft._id", document.CraftID)}})
+
+		result, err := db.portfolios.UpdateOne(ctx, constructor("_id", document.PortfolioID), update, opts)
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge trash: contents: %w", err)
+		}
+		if result.ModifiedCount != 0 {
+			purged = append(purged, document.model(models.ObjectContent))
+
+			if _, err = db.revisions.DeleteMany(ctx, revisionFilter(models.ObjectContent, document.ID)); err != nil {
+				return purged, fmt.Errorf("failed to purge trash: revisions: %w", err)
+			}
+		}
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/jackc/pgx/pgtype"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
+)
+
+// uploadData saves data of the content to the blob store, the blob must be deleted if the content is not saved
+func (db *DB) uploadData(ctx context.Context, data []byte) (blob.Info, error) {
+	return blob.Upload(ctx, db.blobs, bytes.NewReader(data), int64(len(data)))
+}
+
+// contentData returns data from the blob store by the key, contents created before the blob store keep data in the table
+func (db *DB) contentData(ctx context.Context, key pgtype.Text, data pgtype.Bytea) ([]byte, error) {
+	if key.Status != pgtype.Present {
+		return data.Bytes, nil
+	}
+
+	reader, err := db.blobs.Get(ctx, key.String)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	result, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", key.String, err)
+	}
+
+	return result, nil
+}
+
+// deleteBlobs deletes all the blobs even if some of them fail
+func (db *DB) deleteBlobs(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := db.blobs.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// discardBlobs deletes uploaded blobs which were not saved to the table, blobs which failed to be deleted
+// are left in the store and logged, so they can be deleted by hand
+func (db *DB) discardBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := db.blobs.Delete(ctx, key); err != nil {
+			db.logger.WarnContext(ctx, "failed to delete unsaved blob", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
+
+// MoveContentDataToBlobs moves data of contents from the table to the blob store by batches and returns amount of moved contents,
+// it can be stopped and run again at any moment
+func (db *DB) MoveContentDataToBlobs(ctx context.Context, batchSize int) (int, error) {
+	moved := 0
+
+	for {
+		rows, err := db.db.Query(ctx, `SELECT id, data FROM contents WHERE data IS NOT NULL ORDER BY id LIMIT $1`, batchSize)
+		if err != nil {
+			return moved, fmt.Errorf("failed to move contents data: %w", err)
+		}
+
+		var ids []int64
+		var batch [][]byte
+		for rows.Next() {
+			var id pgtype.Int8
+			var data pgtype.Bytea
+
+			if err = rows.Scan(&id, &data); err != nil {
+				rows.Close()
+				return moved, fmt.Errorf("failed to move contents data: scan error: %w", err)
+			}
+
+			ids, batch = append(ids, id.Int), append(batch, data.Bytes)
+		}
+		rows.Close()
+
+		if err = rows.Err(); err != nil {
+			return moved, fmt.Errorf("failed to move contents data: rows error: %w", err)
+		}
+
+		if len(ids) == 0 {
+			return moved, nil
+		}
+
+		for i, id := range ids {
+			info, err := db.uploadData(ctx, batch[i])
+			if err != nil {
+				return moved, fmt.Errorf("failed to move data of content %d: %w", id, err)
+			}
+
+			// the content may be changed or purged after it was read
+			tag, err := db.db.Exec(ctx, `UPDATE contents SET data = NULL, data_key = $2, data_size = $3, data_checksum = $4 WHERE id = $1 AND data IS NOT NULL`,
+				id, info.Key, info.Size, info.Checksum)
+			if err != nil || tag.RowsAffected() == 0 {
+				db.discardBlobs(ctx, info.Key)
+			}
+			if err != nil {
+				return moved, fmt.Errorf("failed to move data of content %d: %w", id, err)
+			}
+
+			if tag.RowsAffected() != 0 {
+				moved++
+			}
+		}
+	}
+}
@@ -1,35 +1,162 @@
 package postgresql
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
 
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
 )
 
+// contentMetadata scans columns mime_type, data_size, data_checksum, width and height of contents
+type contentMetadata struct {
+	mimeType, checksum  pgtype.Text
+	size, width, height pgtype.Int8
+}
+
+func (cm *contentMetadata) targets() []any {
+	return []any{&cm.mimeType, &cm.size, &cm.checksum, &cm.width, &cm.height}
+}
+
+func (cm *contentMetadata) apply(content *models.Content) {
+	content.MIMEType, content.Size, content.Checksum = cm.mimeType.String, cm.size.Int, cm.checksum.String
+	content.Width, content.Height = int(cm.width.Int), int(cm.height.Int)
+}
+
+// nullableInt keeps unknown image dimensions as NULL
+func nullableInt(value int) pgtype.Int8 {
+	if value == 0 {
+		return pgtype.Int8{Status: pgtype.Null}
+	}
+
+	return pgtype.Int8{Int: int64(value), Status: pgtype.Present}
+}
+
 func (db *DB) CreateContent(ctx context.Context, craftID int, content models.Content) (int, error) {
+	return db.createContent(ctx, craftID, content, bytes.NewReader(content.Data), int64(len(content.Data)))
+}
+
+// CreateContentFromReader streams data to the blob store, so it is never kept in memory as a whole
+func (db *DB) CreateContentFromReader(ctx context.Context, craftID int, content models.Content, data io.Reader) (int, error) {
+	return db.createContent(ctx, craftID, content, data, -1)
+}
+
+func (db *DB) createContent(ctx context.Context, craftID int, content models.Content, data io.Reader, size int64) (int, error) {
+	info, err := blob.Upload(ctx, db.blobs, data, size)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create content: %w", err)
+	}
+
 	var contentID pgtype.Int8
-	if err := db.db.QueryRow(ctx, `INSERT INTO contents (craft_id, description, data) VALUES ($1, $2, $3) RETURNING id`, craftID, content.Description, content.Data).Scan(&contentID); err != nil {
+	err = db.inTransaction(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `
+		INSERT INTO contents (craft_id, description, data_key, data_size, data_checksum, mime_type, width, height, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, (SELECT COALESCE(MAX(position) + 1, 0) FROM contents WHERE craft_id = $1)) RETURNING id`,
+			craftID, content.Description, info.Key, info.Size, info.Checksum, content.MIMEType, nullableInt(content.Width), nullableInt(content.Height)).Scan(&contentID); err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, models.ObjectContent, int(contentID.Int), sender.CreateObj)
+	})
+	if err != nil {
+		db.discardBlobs(ctx, info.Key)
 		return 0, fmt.Errorf("failed to create content: %w", err)
 	}
 
 	return int(contentID.Int), nil
 }
 
+func (db *DB) GetContentData(ctx context.Context, id int) (*models.ContentData, error) {
+	var key, checksum, mimeType pgtype.Text
+	var size pgtype.Int8
+	var data pgtype.Bytea
+
+	if err := db.db.QueryRow(ctx, `
+	SELECT contents.data_key, contents.data_size, contents.data_checksum, contents.mime_type, contents.data
+	FROM contents
+	JOIN crafts ON contents.craft_id = crafts.id
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	WHERE contents.id = $1 AND contents.deleted_at IS NULL AND `+visibleCrafts, id).Scan(&key, &size, &checksum, &mimeType, &data); err != nil {
+		return nil, fmt.Errorf("failed to get content data: %w", err)
+	}
+
+	// contents created before the blob store keep data in the table
+	if key.Status != pgtype.Present {
+		return &models.ContentData{Data: blob.Bytes(data.Bytes), MIMEType: mimeType.String, Size: int64(len(data.Bytes)), Checksum: blob.Checksum(data.Bytes)}, nil
+	}
+
+	reader, err := db.blobs.Get(ctx, key.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content data: %w", err)
+	}
+
+	return &models.ContentData{Data: reader, MIMEType: mimeType.String, Size: size.Int, Checksum: checksum.String}, nil
+}
+
+// GetCraftContentsSize returns size of not deleted contents of the craft except the given one
+func (db *DB) GetCraftContentsSize(ctx context.Context, craftID, exceptContentID int) (int64, error) {
+	var size pgtype.Int8
+	if err := db.db.QueryRow(ctx, `SELECT COALESCE(SUM(COALESCE(data_size, octet_length(data))), 0) FROM contents WHERE craft_id = $1 AND id <> $2 AND deleted_at IS NULL`,
+		craftID, exceptContentID).Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to get size of craft contents: %w", err)
+	}
+
+	return size.Int, nil
+}
+
 func (db *DB) DeleteContent(ctx context.Context, id int) error {
-	if _, err := db.db.Exec(ctx, `DELETE FROM contents WHERE id = $1`, id); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		return moveToTrash(ctx, tx, models.ObjectContent, id, `UPDATE contents SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete content: %w", err)
 	}
 
 	return nil
 }
 
-func (db *DB) PatchContent(ctx context.Context, content models.Content) error {
-	if _, err := db.db.Exec(ctx, `UPDATE contents SET description = $1, data = $2 WHERE id = $3`, content.Description, content.Data, content.ID); err != nil {
+// PatchContent saves new data to the blob store and deletes the previous one after the content is updated
+func (db *DB) PatchContent(ctx context.Context, authorID int, content models.Content) error {
+	info, err := db.uploadData(ctx, content.Data)
+	if err != nil {
 		return fmt.Errorf("failed to update content: %w", err)
 	}
 
+	var previousKey pgtype.Text
+	var thumbnailsKeys []string
+	err = db.revise(ctx, models.ObjectContent, authorID, content.ID, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `SELECT data_key FROM contents WHERE id = $1`, content.ID).Scan(&previousKey); err != nil {
+			return err
+		}
+
+		// thumbnails of the previous data are made again for the new one
+		var err error
+		if thumbnailsKeys, err = deleteThumbnails(ctx, tx, content.ID); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, `
+		UPDATE contents SET description = $1, data = NULL, data_key = $2, data_size = $3, data_checksum = $4, mime_type = $5, width = $6, height = $7
+		WHERE id = $8`,
+			content.Description, info.Key, info.Size, info.Checksum, content.MIMEType, nullableInt(content.Width), nullableInt(content.Height), content.ID)
+		return err
+	})
+	if err != nil {
+		db.discardBlobs(ctx, info.Key)
+		return fmt.Errorf("failed to update content: %w", err)
+	}
+
+	if previousKey.Status == pgtype.Present {
+		thumbnailsKeys = append(thumbnailsKeys, previousKey.String)
+	}
+	if err = db.deleteBlobs(ctx, thumbnailsKeys); err != nil {
+		return fmt.Errorf("failed to update content: previous data: %w", err)
+	}
+
 	return nil
 }
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/pgtype"
 	"github.com/jackc/pgx/v5"
 
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
 )
 
 func (db *DB) CreateCraft(ctx context.Context, portfolioID int, craft models.Craft) (int, error) {
@@ -20,16 +23,25 @@ func (db *DB) CreateCraft(ctx context.Context, portfolioID int, craft models.Cra
 	defer tx.Rollback(ctx)
 
 	var craftID pgtype.Int8
-	if err = tx.QueryRow(ctx, `INSERT INTO crafts (portfolio_id, name, description) VALUES ($1, $2, $3) RETURNING id`, portfolioID, craft.Name, craft.Description).Scan(&craftID); err != nil {
+	if err = tx.QueryRow(ctx, `
+	INSERT INTO crafts (portfolio_id, name, description, position)
+	VALUES ($1, $2, $3, (SELECT COALESCE(MAX(position) + 1, 0) FROM crafts WHERE portfolio_id = $1)) RETURNING id`, portfolioID, craft.Name, craft.Description).Scan(&craftID); err != nil {
 		return 0, fmt.Errorf("failed to create craft: %w", err)
 	}
 
 	for _, tag := range craft.Tags {
+		if err = checkApproved(ctx, tx, models.ObjectTag, tag.ID); err != nil {
+			return 0, fmt.Errorf("failed to create craft: %w", err)
+		}
 		if _, err = tx.Exec(ctx, `INSERT INTO crafts_tags (craft_id, tag_id) VALUES ($1, $2)`, int(craftID.Int), tag.ID); err != nil {
 			return 0, fmt.Errorf("failed to create craft: tags error: %w", err)
 		}
 	}
 
+	if err = addEvent(ctx, tx, models.ObjectCraft, int(craftID.Int), sender.CreateObj); err != nil {
+		return 0, fmt.Errorf("failed to create craft: %w", err)
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return 0, fmt.Errorf("failed to create craft: transaction error: %w", err)
 	}
@@ -39,7 +51,13 @@ func (db *DB) CreateCraft(ctx context.Context, portfolioID int, craft models.Cra
 
 func (db *DB) CreateTag(ctx context.Context, name string) (int, error) {
 	var id pgtype.Int8
-	if err := db.db.QueryRow(ctx, `INSERT INTO tags (name) VALUES ($1) RETURNING id`, name).Scan(&id); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `INSERT INTO tags (name) VALUES ($1) RETURNING id`, name).Scan(&id); err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, models.ObjectTag, int(id.Int), sender.CreateObj)
+	})
+	if err != nil {
 		return 0, fmt.Errorf("failed to create tag: %w", err)
 	}
 
@@ -47,40 +65,56 @@ func (db *DB) CreateTag(ctx context.Context, name string) (int, error) {
 }
 
 func (db *DB) DeleteTag(ctx context.Context, id int) error {
-	if _, err := db.db.Exec(ctx, `DELETE FROM tags WHERE id = $1`, id); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		if err := addEvent(ctx, tx, models.ObjectTag, id, sender.DeleteObj); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM tags WHERE id = $1`, id)
+		return err
+	})
+	// there is nothing to delete
+	if errors.Is(err, response_errors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
 		return fmt.Errorf("failed to delete tag: %w", err)
 	}
 
 	return nil
 }
 
-func (db *DB) GetAllTags(ctx context.Context, limit, offset int) ([]models.Tag, error) {
-	rows, err := db.db.Query(ctx, `SELECT id, name FROM tags LIMIT $1 OFFSET $2`, limit, offset)
+// GetAllTags returns approved tags or pending ones with their proposers
+func (db *DB) GetAllTags(ctx context.Context, page models.Page, pending bool) ([]models.Tag, error) {
+	rows, err := db.db.Query(ctx, `SELECT id, name, proposed_by FROM tags WHERE id > $3 AND pending = $4 ORDER BY id LIMIT $1 OFFSET $2`, page.Limit, page.Offset, page.AfterID(), pending)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all tags: %w", err)
 	}
+	defer rows.Close()
 
 	var tags []models.Tag
 
 	for rows.Next() {
-		var id pgtype.Int8
+		var id, proposedBy pgtype.Int8
 		var name pgtype.Text
 
-		if err = rows.Scan(&id, &name); err != nil {
+		if err = rows.Scan(&id, &name, &proposedBy); err != nil {
 			return nil, fmt.Errorf("failed to get all tags: scan error: %w", err)
 		}
 
 		tag := models.Tag{ID: int(id.Int), Name: name.String}
+		if pending {
+			tag.Status, tag.ProposedBy = models.StatusPending, int(proposedBy.Int)
+		}
 		tags = append(tags, tag)
 	}
 
 	return tags, nil
 }
 
-func (db *DB) CountTagsPages(ctx context.Context) (int, error) {
+func (db *DB) CountTagsPages(ctx context.Context, pending bool) (int, error) {
 	var amount pgtype.Int8
 
-	if err := db.db.QueryRow(ctx, `SELECT COUNT(*) FROM tags`).Scan(&amount); err != nil {
+	if err := db.db.QueryRow(ctx, `SELECT COUNT(*) FROM tags WHERE pending = $1`, pending).Scan(&amount); err != nil {
 		return 0, fmt.Errorf("failed to count tags: %w", err)
 	}
 
@@ -88,7 +122,19 @@ func (db *DB) CountTagsPages(ctx context.Context) (int, error) {
 }
 
 func (db *DB) AddTagToCraft(ctx context.Context, craftID, tagID int) error {
-	if _, err := db.db.Exec(ctx, `INSERT INTO crafts_tags (craft_id, tag_id) VALUES ($1, $2)`, craftID, tagID); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		if err := checkApproved(ctx, tx, models.ObjectTag, tagID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO crafts_tags (craft_id, tag_id) VALUES ($1, $2)`, craftID, tagID); err != nil {
+			return err
+		}
+		if err := addCraftTagEvent(ctx, tx, craftID, tagID, sender.AttachObj); err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, models.ObjectCraft, craftID, sender.UpdateObj)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to add tag: %w", err)
 	}
 
@@ -96,7 +142,17 @@ func (db *DB) AddTagToCraft(ctx context.Context, craftID, tagID int) error {
 }
 
 func (db *DB) DeleteTagFromCraft(ctx context.Context, craftID, tagID int) error {
-	if _, err := db.db.Exec(ctx, `DELETE FROM crafts_tags WHERE craft_id=$1 AND tag_id=$2`, craftID, tagID); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM crafts_tags WHERE craft_id=$1 AND tag_id=$2`, craftID, tagID)
+		if err != nil || tag.RowsAffected() == 0 {
+			return err
+		}
+		if err = addCraftTagEvent(ctx, tx, craftID, tagID, sender.DetachObj); err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, models.ObjectCraft, craftID, sender.UpdateObj)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete tag: %w", err)
 	}
 
@@ -104,15 +160,22 @@ func (db *DB) DeleteTagFromCraft(ctx context.Context, craftID, tagID int) error
 }
 
 func (db *DB) DeleteCraft(ctx context.Context, id int) error {
-	if _, err := db.db.Exec(ctx, `DELETE FROM crafts WHERE id=$1`, id); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		return moveToTrash(ctx, tx, models.ObjectCraft, id, `UPDATE crafts SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete craft: %w", err)
 	}
 
 	return nil
 }
 
-func (db *DB) PatchCraft(ctx context.Context, craft models.Craft) error {
-	if _, err := db.db.Exec(ctx, `UPDATE crafts SET name = $1, description = $2 WHERE id = $3`, craft.Name, craft.Description, craft.ID); err != nil {
+func (db *DB) PatchCraft(ctx context.Context, authorID int, craft models.Craft) error {
+	err := db.revise(ctx, models.ObjectCraft, authorID, craft.ID, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `UPDATE crafts SET name = $1, description = $2 WHERE id = $3`, craft.Name, craft.Description, craft.ID)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update craft: %w", err)
 	}
 
@@ -123,12 +186,14 @@ func (db *DB) GetCraftByID(ctx context.Context, craftID int) (*models.Craft, err
 	craft := models.Craft{ID: craftID}
 
 	var craftName, craftDescription pgtype.Text
-	if err := db.db.QueryRow(ctx, `SELECT name, description FROM crafts WHERE id = $1`, craftID).Scan(&craftName, &craftDescription); err != nil {
+	var position, portfolioID, profileID pgtype.Int8
+	if err := db.db.QueryRow(ctx, `SELECT crafts.name, crafts.description, crafts.position, portfolios.id, portfolios.profile_id FROM crafts JOIN portfolios ON crafts.portfolio_id = portfolios.id WHERE crafts.id = $1 AND `+visibleCrafts, craftID).Scan(&craftName, &craftDescription, &position, &portfolioID, &profileID); err != nil {
 		return nil, fmt.Errorf("failed to get craft: %w", err)
 	}
-	craft.Name, craft.Description = craftName.String, craftDescription.String
+	craft.Name, craft.Description, craft.Position = craftName.String, craftDescription.String, int(position.Int)
+	craft.PortfolioID, craft.ProfileID = int(portfolioID.Int), int(profileID.Int)
 
-	rows, err := db.db.Query(ctx, `SELECT crafts_tags.tag_id, tags.name FROM crafts_tags JOIN tags ON crafts_tags.tag_id = tags.id WHERE crafts_tags.craft_id = $1`, craftID)
+	rows, err := db.db.Query(ctx, `SELECT crafts_tags.tag_id, tags.name FROM crafts_tags JOIN tags ON crafts_tags.tag_id = tags.id WHERE crafts_tags.craft_id = $1 ORDER BY tags.id`, craftID)
 	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 		return nil, fmt.Errorf("failed to get craft: tags error: %w", err)
 	}
@@ -146,7 +211,12 @@ func (db *DB) GetCraftByID(ctx context.Context, craftID int) (*models.Craft, err
 		craft.Tags = append(craft.Tags, tag)
 	}
 
-	rows, err = db.db.Query(ctx, `SELECT id, description, data FROM contents WHERE craft_id = $1`, craftID)
+	rows, err = db.db.Query(ctx, `
+	SELECT contents.id, contents.description, contents.position, contents.data_key, contents.data,
+	       contents.mime_type, contents.data_size, contents.data_checksum, contents.width, contents.height, `+thumbnailsColumns+`
+	FROM contents`+fmt.Sprintf(thumbnailsSQL, "contents")+`
+	WHERE contents.craft_id = $1 AND contents.deleted_at IS NULL
+	ORDER BY contents.position, contents.id`, craftID)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
 			return nil, fmt.Errorf("failed to get craft: content error: %w", err)
@@ -154,52 +224,44 @@ func (db *DB) GetCraftByID(ctx context.Context, craftID int) (*models.Craft, err
 		return &craft, nil
 	}
 
+	var dataKeys []pgtype.Text
+	var legacyData []pgtype.Bytea
 	for rows.Next() {
-		var contentID pgtype.Int8
-		var contentDescription pgtype.Text
+		var contentID, contentPosition pgtype.Int8
+		var contentDescription, dataKey pgtype.Text
 		var contentData pgtype.Bytea
+		var metadata contentMetadata
+		var thumbnails contentThumbnails
 
-		if err = rows.Scan(&contentID, &contentDescription, &contentData); err != nil {
+		targets := append([]any{&contentID, &contentDescription, &contentPosition, &dataKey, &contentData}, metadata.targets()...)
+		if err = rows.Scan(append(targets, thumbnails.targets()...)...); err != nil {
 			return nil, fmt.Errorf("failed to get craft: content scan error: %w", err)
 		}
 
-		content := models.Content{ID: int(contentID.Int), Description: contentDescription.String, Data: contentData.Bytes}
+		content := models.Content{ID: int(contentID.Int), Description: contentDescription.String, Position: int(contentPosition.Int)}
+		metadata.apply(&content)
+		thumbnails.apply(&content)
 		craft.Contents = append(craft.Contents, content)
+		dataKeys, legacyData = append(dataKeys, dataKey), append(legacyData, contentData)
+	}
+
+	// the blob store is read after the rows are closed, so the connection is not held while the data is loaded
+	rows.Close()
+	for i := range craft.Contents {
+		if craft.Contents[i].Data, err = db.contentData(ctx, dataKeys[i], legacyData[i]); err != nil {
+			return nil, fmt.Errorf("failed to get craft: content data error: %w", err)
+		}
 	}
 
 	return &craft, nil
 }
 
-func (db *DB) GetAllCraftsByPortfolioID(ctx context.Context, portfolioID, limit, offset int) ([]models.Craft, error) {
-	var crafts []models.Craft
-
-	rows, err := db.db.Query(ctx, `SELECT id, name, description FROM crafts WHERE portfolio_id = $1 LIMIT $2 OFFSET $3`, portfolioID, limit, offset)
+func (db *DB) GetAllCraftsByPortfolioID(ctx context.Context, portfolioID int, page models.Page) ([]models.Craft, error) {
+	crafts, err := db.getCraftsPreviews(ctx, `crafts.portfolio_id = $1`, portfolioID, page, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get crafts by portfolio id: %w", err)
 	}
 
-	for rows.Next() {
-		var craftID pgtype.Int8
-		var craftName, craftDescription pgtype.Text
-
-		if err = rows.Scan(&craftID, &craftName, &craftDescription); err != nil {
-			return nil, fmt.Errorf("failed to get crafts: scan error %w", err)
-		}
-
-		craft := models.Craft{ID: int(craftID.Int), Name: craftName.String, Description: craftDescription.String}
-		crafts = append(crafts, craft)
-	}
-
-	for i, craft := range crafts {
-		tags, content, err := db.getDetailsOfCraft(ctx, craft.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get crafts: details error: %w", err)
-		}
-
-		crafts[i].Tags = tags
-		crafts[i].Contents = []models.Content{content}
-	}
-
 	return crafts, nil
 }
 
@@ -208,9 +270,13 @@ func (db *DB) CountCraftsPages(ctx context.Context, id int, isPortfolioID bool)
 	var err error
 
 	if isPortfolioID {
-		err = db.db.QueryRow(ctx, `SELECT COUNT(*) FROM crafts WHERE portfolio_id = $1`, id).Scan(&amount)
+		err = db.db.QueryRow(ctx, `SELECT COUNT(*) FROM crafts JOIN portfolios ON crafts.portfolio_id = portfolios.id WHERE crafts.portfolio_id = $1 AND `+visibleCrafts, id).Scan(&amount)
 	} else {
-		err = db.db.QueryRow(ctx, `SELECT COUNT(*) FROM crafts_tags WHERE tag_id = $1`, id).Scan(&amount)
+		err = db.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM crafts_tags 
+		JOIN crafts ON crafts_tags.craft_id = crafts.id 
+		JOIN portfolios ON crafts.portfolio_id = portfolios.id 
+		WHERE crafts_tags.tag_id = $1 AND `+visibleCrafts, id).Scan(&amount)
 	}
 
 	if err != nil {
@@ -220,74 +286,98 @@ func (db *DB) CountCraftsPages(ctx context.Context, id int, isPortfolioID bool)
 	return int(amount.Int), nil
 }
 
-func (db *DB) getDetailsOfCraft(ctx context.Context, craftID int) ([]models.Tag, models.Content, error) {
-	rows, err := db.db.Query(ctx, `SELECT crafts_tags.tag_id, tags.name FROM crafts_tags JOIN tags ON crafts_tags.tag_id = tags.id WHERE crafts_tags.craft_id = $1`, craftID)
-	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-		return nil, models.Content{}, fmt.Errorf("failed to get details of craft: tags error: %w", err)
-	}
-
-	var tags []models.Tag
-	for rows.Next() {
-		var tagID pgtype.Int8
-		var tagName pgtype.Text
-
-		if err = rows.Scan(&tagID, &tagName); err != nil {
-			return nil, models.Content{}, fmt.Errorf("failed to get details of craft: scan tags error: %w", err)
-		}
-
-		tag := models.Tag{ID: int(tagID.Int), Name: tagName.String}
-		tags = append(tags, tag)
-	}
-
-	var contentID pgtype.Int8
-	var contentDescription pgtype.Text
-	var contentData pgtype.Bytea
-
-	if err = db.db.QueryRow(ctx, `SELECT id, description, data FROM contents WHERE craft_id = $1 LIMIT 1`, craftID).Scan(&contentID, &contentDescription, &contentData); err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			return nil, models.Content{}, fmt.Errorf("failed to get details of craft: %w", err)
-		}
-		return tags, models.Content{}, nil
+func (db *DB) GetAllCraftsByTagID(ctx context.Context, tagID int, page models.Page) ([]models.Craft, error) {
+	crafts, err := db.getCraftsPreviews(ctx, `crafts.id IN (SELECT craft_id FROM crafts_tags WHERE tag_id = $1)`, tagID, page, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get crafts by tag id: %w", err)
 	}
 
-	content := models.Content{ID: int(contentID.Int), Description: contentDescription.String, Data: contentData.Bytes}
-
-	return tags, content, nil
+	return crafts, nil
 }
 
-func (db *DB) GetAllCraftsByTagID(ctx context.Context, tagID, limit, offset int) ([]models.Craft, error) {
-	rows, err := db.db.Query(ctx, `SELECT craft_id FROM crafts_tags WHERE tag_id = $1 LIMIT $2 OFFSET $3`, tagID, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get crafts by tag id: %w", err)
+// visibleCrafts hides crafts deleted by themselves or together with their portfolio, expects crafts joined with portfolios
+const visibleCrafts = `crafts.deleted_at IS NULL AND portfolios.deleted_at IS NULL`
+
+// craftsPreviewsSQL loads page of crafts with their tags and first content in one query,
+// so the amount of round trips doesn't depend on the page size, data of the preview is not loaded, only its thumbnails are referred
+var craftsPreviewsSQL = `
+	SELECT crafts.id,
+       crafts.name,
+       crafts.description,
+       crafts.position,
+       crafts.portfolio_id,
+       portfolios.profile_id,
+       COALESCE(craft_tags.ids, '{}'),
+       COALESCE(craft_tags.names, '{}'),
+       preview.id,
+       preview.description,
+       preview.position,
+       preview.mime_type,
+       preview.data_size,
+       preview.data_checksum,
+       preview.width,
+       preview.height,
+       ` + thumbnailsColumns + `
+	FROM crafts
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	LEFT JOIN LATERAL (
+		SELECT array_agg(tags.id ORDER BY tags.id) AS ids, array_agg(tags.name ORDER BY tags.id) AS names
+		FROM crafts_tags
+		JOIN tags ON crafts_tags.tag_id = tags.id
+		WHERE crafts_tags.craft_id = crafts.id
+	) craft_tags ON true
+	LEFT JOIN LATERAL (
+		SELECT contents.id, contents.description, contents.position, contents.mime_type, contents.data_size, contents.data_checksum, contents.width, contents.height
+		FROM contents
+		WHERE contents.craft_id = crafts.id AND contents.deleted_at IS NULL
+		ORDER BY contents.position, contents.id
+		LIMIT 1
+	) preview ON true` + fmt.Sprintf(thumbnailsSQL, "preview")
+
+// getCraftsPreviews expects filter condition with $1 placeholder for id, byPosition sorts crafts of one portfolio by their position instead of id
+func (db *DB) getCraftsPreviews(ctx context.Context, filter string, id int, page models.Page, byPosition bool) ([]models.Craft, error) {
+	after, order, args := `crafts.id > $4`, `crafts.id`, []any{id, page.Limit, page.Offset, page.AfterID()}
+	if byPosition {
+		after, order, args = `(crafts.position, crafts.id) > ($5, $4)`, `crafts.position, crafts.id`, append(args, page.AfterPosition())
 	}
 
-	var craftsIDs []int
-	for rows.Next() {
-		var craftID pgtype.Int8
-
-		if err = rows.Scan(&craftID); err != nil {
-			return nil, fmt.Errorf("failed to get crafts by portfolio id: scan craftID error %w", err)
-		}
+	sql := strings.Join([]string{craftsPreviewsSQL, where(visibleCrafts, filter, after), `ORDER BY`, order, `LIMIT $2 OFFSET $3`}, " ")
 
-		craftsIDs = append(craftsIDs, int(craftID.Int))
+	rows, err := db.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
 	var crafts []models.Craft
-
-	for _, craftID := range craftsIDs {
-		var craftName, craftDescription pgtype.Text
-		if err = db.db.QueryRow(ctx, `SELECT name, description FROM crafts WHERE id = $1`, craftID).Scan(&craftName, &craftDescription); err != nil {
-			return nil, fmt.Errorf("failed to get crafts by portfolio id: scan craft error %w", err)
+	for rows.Next() {
+		var craftID, craftPosition, portfolioID, profileID, contentID, contentPosition pgtype.Int8
+		var craftName, craftDescription, contentDescription pgtype.Text
+		var metadata contentMetadata
+		var thumbnails contentThumbnails
+		var tagsIDs []int64
+		var tagsNames []string
+
+		targets := []any{&craftID, &craftName, &craftDescription, &craftPosition, &portfolioID, &profileID, &tagsIDs, &tagsNames, &contentID, &contentDescription, &contentPosition}
+		targets = append(targets, metadata.targets()...)
+		if err = rows.Scan(append(targets, thumbnails.targets()...)...); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
 		}
 
-		tags, content, err := db.getDetailsOfCraft(ctx, craftID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get crafts: details error: %w", err)
+		craft := models.Craft{ID: int(craftID.Int), Name: craftName.String, Description: craftDescription.String, Position: int(craftPosition.Int), PortfolioID: int(portfolioID.Int), ProfileID: int(profileID.Int)}
+		for i := range tagsIDs {
+			craft.Tags = append(craft.Tags, models.Tag{ID: int(tagsIDs[i]), Name: tagsNames[i]})
 		}
+		craft.Contents = []models.Content{{ID: int(contentID.Int), Description: contentDescription.String, Position: int(contentPosition.Int)}}
+		metadata.apply(&craft.Contents[0])
+		thumbnails.apply(&craft.Contents[0])
 
-		craft := models.Craft{ID: craftID, Name: craftName.String, Description: craftDescription.String, Tags: tags, Contents: []models.Content{content}}
 		crafts = append(crafts, craft)
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
 	return crafts, nil
 }
@@ -0,0 +1,189 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob/filesystem"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// testDSNEnv is the connection string of the database for tests, tests of the package are skipped without it.
+// Migrations are applied to the database and rows created by tests are kept
+const testDSNEnv = "PG_TEST_DSN"
+
+// queryCounter counts queries sent by the pool
+type queryCounter struct {
+	queries atomic.Int64
+}
+
+func (qc *queryCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	qc.queries.Add(1)
+	return ctx
+}
+
+func (qc *queryCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+func newTestDB(t testing.TB) (*DB, *queryCounter) {
+	t.Helper()
+
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", testDSNEnv, err)
+	}
+	counter := &queryCounter{}
+	poolConfig.ConnConfig.Tracer = counter
+
+	ctx := context.Background()
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	blobs, err := filesystem.NewStore(config.Filesystem{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create blob store: %v", err)
+	}
+
+	db := &DB{db: pool, blobs: blobs, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	if _, err = db.MigrateUp(ctx); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	return db, counter
+}
+
+// seedCrafts creates the portfolio with crafts, every craft has the tag and the content, so previews of crafts have all their parts
+func seedCrafts(t testing.TB, db *DB, amount int) (portfolioID, tagID int) {
+	t.Helper()
+	ctx := context.Background()
+	suffix := fmt.Sprintf("%s %d", t.Name(), os.Getpid())
+
+	categoryID, err := db.CreateCategory(ctx, "category "+suffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tagID, err = db.CreateTag(ctx, "tag "+suffix); err != nil {
+		t.Fatal(err)
+	}
+
+	if portfolioID, err = db.CreatePortfolio(ctx, models.Portfolio{ProfileID: 1, Name: "portfolio", Category: models.Category{ID: categoryID}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < amount; i++ {
+		craftID, err := db.CreateCraft(ctx, portfolioID, models.Craft{Name: fmt.Sprintf("craft %d", i), Tags: []models.Tag{{ID: tagID}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content := models.Content{Description: "content", Data: []byte("data"), MIMEType: "text/plain; charset=utf-8"}
+		if _, err = db.CreateContent(ctx, craftID, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return portfolioID, tagID
+}
+
+func TestCraftsPreviewsQueries(t *testing.T) {
+	db, counter := newTestDB(t)
+	portfolioID, tagID := seedCrafts(t, db, 20)
+
+	tests := []struct {
+		name string
+		get  func(ctx context.Context, page models.Page) ([]models.Craft, error)
+	}{
+		{
+			name: "by portfolio id",
+			get: func(ctx context.Context, page models.Page) ([]models.Craft, error) {
+				return db.GetAllCraftsByPortfolioID(ctx, portfolioID, page)
+			},
+		},
+		{
+			name: "by tag id",
+			get: func(ctx context.Context, page models.Page) ([]models.Craft, error) {
+				return db.GetAllCraftsByTagID(ctx, tagID, page)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, limit := range []int{2, 20} {
+			t.Run(fmt.Sprintf("%s, limit %d", tt.name, limit), func(t *testing.T) {
+				counter.queries.Store(0)
+
+				crafts, err := tt.get(context.Background(), models.Page{Limit: limit})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(crafts) != limit {
+					t.Fatalf("got %d crafts, want %d", len(crafts), limit)
+				}
+				for _, craft := range crafts {
+					if len(craft.Tags) != 1 || len(craft.Contents) != 1 || craft.Contents[0].ID == 0 {
+						t.Fatalf("craft %d has incomplete preview: %+v", craft.ID, craft)
+					}
+				}
+
+				if queries := counter.queries.Load(); queries != 1 {
+					t.Errorf("got %d queries, want 1", queries)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkGetAllCraftsByPortfolioID(b *testing.B) {
+	db, counter := newTestDB(b)
+	portfolioID, _ := seedCrafts(b, db, 100)
+
+	benchmarkCraftsPreviews(b, counter, func(ctx context.Context, page models.Page) ([]models.Craft, error) {
+		return db.GetAllCraftsByPortfolioID(ctx, portfolioID, page)
+	})
+}
+
+func BenchmarkGetAllCraftsByTagID(b *testing.B) {
+	db, counter := newTestDB(b)
+	_, tagID := seedCrafts(b, db, 100)
+
+	benchmarkCraftsPreviews(b, counter, func(ctx context.Context, page models.Page) ([]models.Craft, error) {
+		return db.GetAllCraftsByTagID(ctx, tagID, page)
+	})
+}
+
+// benchmarkCraftsPreviews gets pages of crafts of several sizes and reports queries sent for every page
+func benchmarkCraftsPreviews(b *testing.B, counter *queryCounter, get func(ctx context.Context, page models.Page) ([]models.Craft, error)) {
+	for _, limit := range []int{10, 50, 100} {
+		b.Run(fmt.Sprintf("limit %d", limit), func(b *testing.B) {
+			ctx := context.Background()
+			counter.queries.Store(0)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := get(ctx, models.Page{Limit: limit}); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(counter.queries.Load())/float64(b.N), "queries/op")
+		})
+	}
+}
@@ -3,17 +3,22 @@ package postgresql
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
 )
 
 type DB struct {
 	db *pgxpool.Pool
+	// blobs keeps data of contents, the table keeps only its key, size and checksum
+	blobs  blob.Store
+	logger *slog.Logger
 }
 
-func NewDB(ctx context.Context, cfg config.Postgres) (*DB, error) {
+func NewDB(ctx context.Context, cfg config.Postgres, blobs blob.Store, logger *slog.Logger) (*DB, error) {
 	connstr := fmt.Sprintf("postgres://%s:%s@%s:%d/%s", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
 
 	db, err := pgxpool.New(ctx, connstr)
@@ -26,7 +31,9 @@ func NewDB(ctx context.Context, cfg config.Postgres) (*DB, error) {
 	}
 
 	return &DB{
-		db: db,
+		db:     db,
+		blobs:  blobs,
+		logger: logger,
 	}, nil
 }
 
@@ -34,7 +41,4 @@ func (db *DB) Close() {
 	db.db.Close()
 }
 
-func GetProfileIDByPortfolio() {} // TODO: для перехода на профиль автора портфолио
-func GetProfileIDByCraft()     {} // TODO: для перехода на профиль автора крафта
-func GetPortfolioIDByCraft()   {} // TODO: для перехода на портфолио по найденному крафту
 // TODO: модифицировать методы обновления объектов для обновления не всех параметров за раз
@@ -2,45 +2,56 @@ package postgresql
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
 )
 
-type PortfoliosFilterType string
-
 const (
 	Empty        = ""
-	ByProfileID  = "WHERE portfolios.profile_id = %d"
-	ByCategoryID = "WHERE portfolios.category_id = %d"
+	ByProfileID  = "portfolios.profile_id = %d"
+	ByCategoryID = "portfolios.category_id = %d"
 )
 
-const (
-	FilterEmpty        PortfoliosFilterType = ""
-	FilterByProfileID  PortfoliosFilterType = "ByProfileID"
-	FilterByCategoryID PortfoliosFilterType = "ByCategoryID"
-)
-
-var requiredFilters = map[PortfoliosFilterType]string{
-	FilterEmpty:        Empty,
-	FilterByProfileID:  ByProfileID,
-	FilterByCategoryID: ByCategoryID,
+var requiredFilters = map[models.PortfoliosFilterType]string{
+	models.FilterEmpty:        Empty,
+	models.FilterByProfileID:  ByProfileID,
+	models.FilterByCategoryID: ByCategoryID,
 }
 
 type PortfoliosFilter struct {
-	Type PortfoliosFilterType
+	Type models.PortfoliosFilterType
 	ID   int
 }
 
-func portfolioFilter(filterType PortfoliosFilterType, id int) (string, error) {
+// portfolioFilter returns condition for WHERE clause, empty for FilterEmpty
+func portfolioFilter(filterType models.PortfoliosFilterType, id int) (string, error) {
 	filter, ok := requiredFilters[filterType]
 	if !ok {
 		return "", response_errors.ErrIncorrectPortfoliosFilterType
 	}
 
 	switch filterType {
-	case FilterEmpty:
+	case models.FilterEmpty:
 		return filter, nil
 	default:
 		return fmt.Sprintf(filter, id), nil
 	}
 }
+
+// where joins non-empty conditions into WHERE clause
+func where(conditions ...string) string {
+	var nonEmpty []string
+	for _, condition := range conditions {
+		if condition != "" {
+			nonEmpty = append(nonEmpty, condition)
+		}
+	}
+
+	if len(nonEmpty) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(nonEmpty, " AND ")
+}
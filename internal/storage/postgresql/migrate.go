@@ -0,0 +1,264 @@
+package postgresql
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationsLockID is the key of the advisory lock, so replicas started together apply migrations one by one
+const migrationsLockID int64 = 7_301_202_401
+
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus describes one embedded migration and whether it is applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// loadMigrations reads files named {version}_{name}.up.sql and {version}_{name}.down.sql
+func loadMigrations() ([]migration, error) {
+	files, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*migration)
+	for _, file := range files {
+		fileName := file.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("failed to read migrations: unexpected file %s", fileName)
+		}
+
+		versionStr, name, ok := strings.Cut(strings.TrimSuffix(fileName, "."+direction+".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("failed to read migrations: incorrect file name %s", fileName)
+		}
+
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migrations: incorrect version of %s: %w", fileName, err)
+		}
+
+		sql, err := migrationsFS.ReadFile(path.Join("migrations", fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migrations: %w", err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: name}
+			byVersion[version] = m
+		}
+
+		if direction == "up" {
+			m.up = string(sql)
+		} else {
+			m.down = string(sql)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("failed to read migrations: version %d has no up migration", m.version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+
+	return migrations, nil
+}
+
+// MigrateUp applies all pending migrations
+func (db *DB) MigrateUp(ctx context.Context) (int, error) {
+	var applied int
+
+	err := db.withMigrationsLock(ctx, func(conn *pgxpool.Conn) error {
+		migrations, err := loadMigrations()
+		if err != nil {
+			return err
+		}
+
+		done, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if _, ok := done[m.version]; ok {
+				continue
+			}
+
+			if err = applyMigration(ctx, conn, m.up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.version, m.name); err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", m.version, m.name, err)
+			}
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// MigrateDown rolls back the given amount of the latest applied migrations
+func (db *DB) MigrateDown(ctx context.Context, steps int) (int, error) {
+	var reverted int
+
+	err := db.withMigrationsLock(ctx, func(conn *pgxpool.Conn) error {
+		migrations, err := loadMigrations()
+		if err != nil {
+			return err
+		}
+
+		done, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && reverted < steps; i-- {
+			m := migrations[i]
+			if _, ok := done[m.version]; !ok {
+				continue
+			}
+
+			if m.down == "" {
+				return fmt.Errorf("failed to revert migration %d_%s: no down migration", m.version, m.name)
+			}
+
+			if err = applyMigration(ctx, conn, m.down, `DELETE FROM schema_migrations WHERE version = $1`, m.version); err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s: %w", m.version, m.name, err)
+			}
+			reverted++
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// MigrationsStatus returns all embedded migrations ordered by version
+func (db *DB) MigrationsStatus(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := db.withMigrationsLock(ctx, func(conn *pgxpool.Conn) error {
+		migrations, err := loadMigrations()
+		if err != nil {
+			return err
+		}
+
+		done, err := appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			appliedAt, ok := done[m.version]
+			statuses = append(statuses, MigrationStatus{Version: m.version, Name: m.name, Applied: ok, AppliedAt: appliedAt})
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// withMigrationsLock holds session advisory lock on a single connection while f works
+func (db *DB) withMigrationsLock(ctx context.Context, f func(conn *pgxpool.Conn) error) error {
+	conn, err := db.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationsLockID); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationsLockID)
+
+	if _, err = conn.Exec(ctx, `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		"version" BIGINT PRIMARY KEY,
+		"name" TEXT NOT NULL,
+		"applied_at" TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return f(conn)
+}
+
+func appliedMigrations(ctx context.Context, conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to get applied migrations: scan error: %w", err)
+		}
+		applied[int(version)] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// applyMigration runs migration sql and bookkeeping query in one transaction
+func applyMigration(ctx context.Context, conn *pgxpool.Conn, sql string, bookkeeping string, args ...any) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, bookkeeping, args...); err != nil {
+		return fmt.Errorf("schema_migrations error: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	return nil
+}
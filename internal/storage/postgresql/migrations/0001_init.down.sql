@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS contents;
+DROP TABLE IF EXISTS crafts_tags;
+DROP TABLE IF EXISTS tags;
+DROP TABLE IF EXISTS crafts;
+DROP TABLE IF EXISTS portfolios;
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS tag_id_crafts_tags_idx;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS tag_id_crafts_tags_idx ON crafts_tags(tag_id);
@@ -0,0 +1,12 @@
+DROP TRIGGER IF EXISTS contents_search_vector_update ON contents;
+DROP TRIGGER IF EXISTS crafts_tags_search_vector_update ON crafts_tags;
+DROP TRIGGER IF EXISTS crafts_search_vector_update ON crafts;
+DROP FUNCTION IF EXISTS crafts_children_search_vector_trigger();
+DROP FUNCTION IF EXISTS crafts_search_vector_trigger();
+DROP FUNCTION IF EXISTS craft_search_vector(BIGINT, TEXT, TEXT);
+
+DROP INDEX IF EXISTS search_vector_crafts_idx;
+ALTER TABLE crafts DROP COLUMN IF EXISTS search_vector;
+
+DROP INDEX IF EXISTS search_vector_portfolios_idx;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,59 @@
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS "search_vector" TSVECTOR
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS search_vector_portfolios_idx ON portfolios USING GIN (search_vector);
+
+-- crafts are found by their tags and contents descriptions too, so the vector is kept up to date by triggers
+ALTER TABLE crafts ADD COLUMN IF NOT EXISTS "search_vector" TSVECTOR NOT NULL DEFAULT ''::TSVECTOR;
+
+CREATE INDEX IF NOT EXISTS search_vector_crafts_idx ON crafts USING GIN (search_vector);
+
+CREATE OR REPLACE FUNCTION craft_search_vector(target_id BIGINT, target_name TEXT, target_description TEXT) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(target_name, '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(tags.name, ' ')
+               FROM crafts_tags
+               JOIN tags ON crafts_tags.tag_id = tags.id
+               WHERE crafts_tags.craft_id = target_id), '')), 'B') ||
+           setweight(to_tsvector('simple', coalesce(target_description, '')), 'C') ||
+           setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(contents.description, ' ')
+               FROM contents
+               WHERE contents.craft_id = target_id), '')), 'D')
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION crafts_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := craft_search_vector(NEW.id, NEW.name, NEW.description);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION crafts_children_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE crafts SET search_vector = craft_search_vector(id, name, description) WHERE id = NEW.craft_id;
+    END IF;
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        UPDATE crafts SET search_vector = craft_search_vector(id, name, description) WHERE id = OLD.craft_id;
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS crafts_search_vector_update ON crafts;
+CREATE TRIGGER crafts_search_vector_update BEFORE INSERT OR UPDATE OF name, description ON crafts
+    FOR EACH ROW EXECUTE FUNCTION crafts_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS crafts_tags_search_vector_update ON crafts_tags;
+CREATE TRIGGER crafts_tags_search_vector_update AFTER INSERT OR DELETE ON crafts_tags
+    FOR EACH ROW EXECUTE FUNCTION crafts_children_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS contents_search_vector_update ON contents;
+CREATE TRIGGER contents_search_vector_update AFTER INSERT OR DELETE OR UPDATE OF description, craft_id ON contents
+    FOR EACH ROW EXECUTE FUNCTION crafts_children_search_vector_trigger();
+
+UPDATE crafts SET search_vector = craft_search_vector(id, name, description);
@@ -0,0 +1,30 @@
+-- rows in the trash would become visible again, so they are deleted
+DELETE FROM portfolios WHERE deleted_at IS NOT NULL;
+DELETE FROM crafts WHERE deleted_at IS NOT NULL;
+DELETE FROM contents WHERE deleted_at IS NOT NULL;
+
+DROP TRIGGER IF EXISTS contents_search_vector_update ON contents;
+CREATE TRIGGER contents_search_vector_update AFTER INSERT OR DELETE OR UPDATE OF description, craft_id ON contents
+    FOR EACH ROW EXECUTE FUNCTION crafts_children_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION craft_search_vector(target_id BIGINT, target_name TEXT, target_description TEXT) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(target_name, '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(tags.name, ' ')
+               FROM crafts_tags
+               JOIN tags ON crafts_tags.tag_id = tags.id
+               WHERE crafts_tags.craft_id = target_id), '')), 'B') ||
+           setweight(to_tsvector('simple', coalesce(target_description, '')), 'C') ||
+           setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(contents.description, ' ')
+               FROM contents
+               WHERE contents.craft_id = target_id), '')), 'D')
+$$ LANGUAGE SQL STABLE;
+
+DROP INDEX IF EXISTS deleted_at_contents_idx;
+DROP INDEX IF EXISTS deleted_at_crafts_idx;
+DROP INDEX IF EXISTS deleted_at_portfolios_idx;
+
+ALTER TABLE contents DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE crafts DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,27 @@
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE crafts ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE contents ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+-- trash is small compared to the tables, so partial indexes are enough for the trash listing and the purge job
+CREATE INDEX IF NOT EXISTS deleted_at_portfolios_idx ON portfolios(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS deleted_at_crafts_idx ON crafts(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS deleted_at_contents_idx ON contents(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- descriptions of deleted contents are not searchable
+CREATE OR REPLACE FUNCTION craft_search_vector(target_id BIGINT, target_name TEXT, target_description TEXT) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(target_name, '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(tags.name, ' ')
+               FROM crafts_tags
+               JOIN tags ON crafts_tags.tag_id = tags.id
+               WHERE crafts_tags.craft_id = target_id), '')), 'B') ||
+           setweight(to_tsvector('simple', coalesce(target_description, '')), 'C') ||
+           setweight(to_tsvector('simple', coalesce((
+               SELECT string_agg(contents.description, ' ')
+               FROM contents
+               WHERE contents.craft_id = target_id AND contents.deleted_at IS NULL), '')), 'D')
+$$ LANGUAGE SQL STABLE;
+
+DROP TRIGGER IF EXISTS contents_search_vector_update ON contents;
+CREATE TRIGGER contents_search_vector_update AFTER INSERT OR DELETE OR UPDATE OF description, craft_id, deleted_at ON contents
+    FOR EACH ROW EXECUTE FUNCTION crafts_children_search_vector_trigger();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS contents_revisions;
+DROP TABLE IF EXISTS crafts_revisions;
+DROP TABLE IF EXISTS portfolios_revisions;
@@ -0,0 +1,36 @@
+-- every row keeps values of the object before the change made by author_id at created_at
+CREATE TABLE IF NOT EXISTS portfolios_revisions (
+                                        "portfolio_id" BIGINT NOT NULL,
+                                        "revision" INT NOT NULL,
+                                        "author_id" BIGINT NOT NULL,
+                                        "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                        "name" TEXT,
+                                        "description" TEXT,
+                                        "category_id" BIGINT,
+                                        PRIMARY KEY (portfolio_id, revision),
+                                        FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS crafts_revisions (
+                                        "craft_id" BIGINT NOT NULL,
+                                        "revision" INT NOT NULL,
+                                        "author_id" BIGINT NOT NULL,
+                                        "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                        "name" TEXT,
+                                        "description" TEXT,
+                                        "category_id" BIGINT,
+                                        PRIMARY KEY (craft_id, revision),
+                                        FOREIGN KEY (craft_id) REFERENCES crafts(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS contents_revisions (
+                                        "content_id" BIGINT NOT NULL,
+                                        "revision" INT NOT NULL,
+                                        "author_id" BIGINT NOT NULL,
+                                        "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                        "name" TEXT,
+                                        "description" TEXT,
+                                        "category_id" BIGINT,
+                                        PRIMARY KEY (content_id, revision),
+                                        FOREIGN KEY (content_id) REFERENCES contents(id) ON DELETE CASCADE
+);
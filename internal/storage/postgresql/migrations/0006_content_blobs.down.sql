@@ -0,0 +1,7 @@
+-- fails while there are contents which data is kept in the blob store only
+ALTER TABLE contents ALTER COLUMN data SET NOT NULL;
+
+ALTER TABLE contents
+    DROP COLUMN IF EXISTS data_key,
+    DROP COLUMN IF EXISTS data_size,
+    DROP COLUMN IF EXISTS data_checksum;
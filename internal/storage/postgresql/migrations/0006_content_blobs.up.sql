@@ -0,0 +1,7 @@
+-- data of new contents is kept in the blob store, existing data is moved there by the migrate blobs command
+ALTER TABLE contents ALTER COLUMN data DROP NOT NULL;
+
+ALTER TABLE contents
+    ADD COLUMN IF NOT EXISTS data_key TEXT,
+    ADD COLUMN IF NOT EXISTS data_size BIGINT,
+    ADD COLUMN IF NOT EXISTS data_checksum TEXT;
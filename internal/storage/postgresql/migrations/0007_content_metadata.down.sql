@@ -0,0 +1,4 @@
+ALTER TABLE contents
+    DROP COLUMN IF EXISTS mime_type,
+    DROP COLUMN IF EXISTS width,
+    DROP COLUMN IF EXISTS height;
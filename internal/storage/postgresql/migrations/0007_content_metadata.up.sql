@@ -0,0 +1,7 @@
+ALTER TABLE contents
+    ADD COLUMN IF NOT EXISTS mime_type TEXT,
+    ADD COLUMN IF NOT EXISTS width INT,
+    ADD COLUMN IF NOT EXISTS height INT;
+
+-- contents which data is still kept in the table get their size and checksum, the type of old contents stays unknown
+UPDATE contents SET data_size = octet_length(data), data_checksum = encode(sha256(data), 'hex') WHERE data IS NOT NULL;
@@ -0,0 +1,13 @@
+-- thumbnails of image contents, their data is kept in the blob store like data of contents
+CREATE TABLE IF NOT EXISTS content_thumbnails (
+                                        "content_id" BIGINT NOT NULL,
+                                        "size" INT NOT NULL,
+                                        "width" INT NOT NULL,
+                                        "height" INT NOT NULL,
+                                        "mime_type" TEXT NOT NULL,
+                                        "data_key" TEXT NOT NULL,
+                                        "data_size" BIGINT NOT NULL,
+                                        "data_checksum" TEXT NOT NULL,
+                                        PRIMARY KEY (content_id, size),
+                                        FOREIGN KEY (content_id) REFERENCES contents(id) ON DELETE CASCADE
+);
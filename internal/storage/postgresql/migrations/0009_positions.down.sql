@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS portfolio_id_position_crafts_idx;
+DROP INDEX IF EXISTS craft_id_position_contents_idx;
+
+ALTER TABLE crafts DROP COLUMN IF EXISTS position;
+ALTER TABLE contents DROP COLUMN IF EXISTS position;
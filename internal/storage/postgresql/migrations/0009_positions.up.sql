@@ -0,0 +1,14 @@
+-- crafts are ordered within their portfolio and contents within their craft, existing rows keep the order of their ids
+ALTER TABLE crafts ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+ALTER TABLE contents ADD COLUMN IF NOT EXISTS position INT NOT NULL DEFAULT 0;
+
+UPDATE crafts SET position = ordered.position
+FROM (SELECT id, row_number() OVER (PARTITION BY portfolio_id ORDER BY id) - 1 AS position FROM crafts) ordered
+WHERE crafts.id = ordered.id;
+
+UPDATE contents SET position = ordered.position
+FROM (SELECT id, row_number() OVER (PARTITION BY craft_id ORDER BY id) - 1 AS position FROM contents) ordered
+WHERE contents.id = ordered.id;
+
+CREATE INDEX IF NOT EXISTS portfolio_id_position_crafts_idx ON crafts(portfolio_id, position, id);
+CREATE INDEX IF NOT EXISTS craft_id_position_contents_idx ON contents(craft_id, position, id);
@@ -0,0 +1,15 @@
+-- events are written in the same transaction as the change and are sent to the broker by the relay in order of id
+CREATE TABLE IF NOT EXISTS outbox (
+                                        "id" BIGSERIAL PRIMARY KEY,
+                                        "profile_id" BIGINT NOT NULL,
+                                        "object" TEXT NOT NULL,
+                                        "object_id" BIGINT NOT NULL,
+                                        "change" TEXT NOT NULL,
+                                        "created_at" TIMESTAMPTZ NOT NULL DEFAULT now(),
+                                        "attempts" INT NOT NULL DEFAULT 0,
+                                        "last_error" TEXT,
+                                        "delivered_at" TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS undelivered_outbox_idx ON outbox(id) WHERE delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS delivered_at_outbox_idx ON outbox(delivered_at) WHERE delivered_at IS NOT NULL;
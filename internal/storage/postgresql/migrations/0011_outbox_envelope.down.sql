@@ -0,0 +1,4 @@
+ALTER TABLE outbox DROP COLUMN IF EXISTS event_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS portfolio_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS craft_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS snapshot;
@@ -0,0 +1,5 @@
+-- event_id is kept on redeliveries so consumers can skip duplicates, parents and the snapshot are sent with the event
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS event_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS portfolio_id BIGINT;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS craft_id BIGINT;
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS snapshot JSONB;
@@ -0,0 +1,2 @@
+DELETE FROM outbox WHERE profile_id IS NULL;
+ALTER TABLE outbox ALTER COLUMN profile_id SET NOT NULL;
@@ -0,0 +1,2 @@
+-- events of categories and tags are not owned by profiles
+ALTER TABLE outbox ALTER COLUMN profile_id DROP NOT NULL;
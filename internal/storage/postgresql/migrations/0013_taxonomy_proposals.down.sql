@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS pending_tags_idx;
+DROP INDEX IF EXISTS pending_categories_idx;
+
+DELETE FROM tags WHERE pending;
+DELETE FROM categories WHERE pending;
+
+ALTER TABLE tags DROP COLUMN IF EXISTS "proposed_by";
+ALTER TABLE tags DROP COLUMN IF EXISTS "pending";
+ALTER TABLE categories DROP COLUMN IF EXISTS "proposed_by";
+ALTER TABLE categories DROP COLUMN IF EXISTS "pending";
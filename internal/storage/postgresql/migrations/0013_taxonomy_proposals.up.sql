@@ -0,0 +1,8 @@
+-- categories and tags proposed by users are pending until they are approved by moderators
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS "pending" BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS "proposed_by" BIGINT;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS "pending" BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS "proposed_by" BIGINT;
+
+CREATE INDEX IF NOT EXISTS pending_categories_idx ON categories(id) WHERE pending;
+CREATE INDEX IF NOT EXISTS pending_tags_idx ON tags(id) WHERE pending;
@@ -0,0 +1,2 @@
+-- the id of the request which made the change, it is sent with the event
+ALTER TABLE outbox ADD COLUMN IF NOT EXISTS request_id TEXT;
@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/pgtype"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// ReorderCrafts sets positions of not deleted crafts of the portfolio by the order, deleted crafts keep their positions
+func (db *DB) ReorderCrafts(ctx context.Context, portfolioID int, order models.Order) error {
+	err := db.reorder(ctx, models.ObjectPortfolio, order,
+		`SELECT id FROM portfolios WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+		`SELECT id FROM crafts WHERE portfolio_id = $1 AND deleted_at IS NULL`,
+		`UPDATE crafts SET position = ordered.position - 1
+		FROM unnest($2::BIGINT[]) WITH ORDINALITY AS ordered(id, position)
+		WHERE crafts.id = ordered.id AND crafts.portfolio_id = $1`, portfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to reorder crafts: %w", err)
+	}
+
+	return nil
+}
+
+// ReorderContents sets positions of not deleted contents of the craft by the order, deleted contents keep their positions
+func (db *DB) ReorderContents(ctx context.Context, craftID int, order models.Order) error {
+	err := db.reorder(ctx, models.ObjectCraft, order,
+		`SELECT crafts.id FROM crafts JOIN portfolios ON crafts.portfolio_id = portfolios.id WHERE crafts.id = $1 AND `+visibleCrafts+` FOR UPDATE OF crafts`,
+		`SELECT id FROM contents WHERE craft_id = $1 AND deleted_at IS NULL`,
+		`UPDATE contents SET position = ordered.position - 1
+		FROM unnest($2::BIGINT[]) WITH ORDINALITY AS ordered(id, position)
+		WHERE contents.id = ordered.id AND contents.craft_id = $1`, craftID)
+	if err != nil {
+		return fmt.Errorf("failed to reorder contents: %w", err)
+	}
+
+	return nil
+}
+
+// reorder locks the parent, so children are reordered one request at a time, checks that the order matches ids of the children,
+// updates their positions and saves the event of the parent in one transaction.
+// All queries expect $1 to be the parent id, update expects $2 to be the ordered ids
+func (db *DB) reorder(ctx context.Context, parent string, order models.Order, lockParent, selectChildren, update string, parentID int) error {
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var id pgtype.Int8
+	if err = tx.QueryRow(ctx, lockParent, parentID).Scan(&id); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, selectChildren, parentID)
+	if err != nil {
+		return err
+	}
+
+	var ids []int
+	for rows.Next() {
+		if err = rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan error: %w", err)
+		}
+		ids = append(ids, int(id.Int))
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	if !order.Matches(ids) {
+		return response_errors.ErrIncorrectOrder
+	}
+
+	if _, err = tx.Exec(ctx, update, parentID, order.IDs); err != nil {
+		return err
+	}
+
+	if err = addEvent(ctx, tx, parent, parentID, sender.UpdateObj); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,260 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/logging"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// outboxLockID is the key of the advisory lock, so events are relayed by one replica at a time and keep their order
+const outboxLockID int64 = 7_301_202_402
+
+type eventTarget struct {
+	// parents selects the profile, the portfolio and the craft containing the object by $1,
+	// events are keyed by the owner of the object
+	parents string
+	// snapshot selects the object by $1 as JSON with the fields of the model, without crafts of portfolios,
+	// contents of crafts and data of contents
+	snapshot string
+}
+
+var eventTargets = map[string]eventTarget{
+	models.ObjectPortfolio: {
+		parents: `SELECT profile_id, NULL::BIGINT, NULL::BIGINT FROM portfolios WHERE id = $1`,
+		snapshot: `SELECT json_build_object('portfolio_id', portfolios.id, 'profile_id', portfolios.profile_id, 'name', portfolios.name,
+			'category', json_build_object('category_id', categories.id, 'category_name', categories.name), 'description', portfolios.description)
+		FROM portfolios JOIN categories ON portfolios.category_id = categories.id
+		WHERE portfolios.id = $1`,
+	},
+	models.ObjectCraft: {
+		parents: `SELECT portfolios.profile_id, crafts.portfolio_id, NULL::BIGINT FROM crafts
+		JOIN portfolios ON crafts.portfolio_id = portfolios.id
+		WHERE crafts.id = $1`,
+		snapshot: `SELECT json_build_object('craft_id', crafts.id, 'craft_name', crafts.name, 'craft_description', crafts.description,
+			'position', crafts.position, 'portfolio_id', crafts.portfolio_id,
+			'tags', COALESCE((SELECT json_agg(json_build_object('tag_id', tags.id, 'tag_name', tags.name) ORDER BY tags.id)
+				FROM crafts_tags JOIN tags ON crafts_tags.tag_id = tags.id WHERE crafts_tags.craft_id = crafts.id), '[]'))
+		FROM crafts
+		WHERE crafts.id = $1`,
+	},
+	models.ObjectCategory: {
+		parents:  `SELECT NULL::BIGINT, NULL::BIGINT, NULL::BIGINT FROM categories WHERE id = $1`,
+		snapshot: `SELECT json_build_object('category_id', id, 'category_name', name) FROM categories WHERE id = $1`,
+	},
+	models.ObjectTag: {
+		parents:  `SELECT NULL::BIGINT, NULL::BIGINT, NULL::BIGINT FROM tags WHERE id = $1`,
+		snapshot: `SELECT json_build_object('tag_id', id, 'tag_name', name) FROM tags WHERE id = $1`,
+	},
+	models.ObjectContent: {
+		parents: `SELECT portfolios.profile_id, crafts.portfolio_id, contents.craft_id FROM contents
+		JOIN crafts ON contents.craft_id = crafts.id
+		JOIN portfolios ON crafts.portfolio_id = portfolios.id
+		WHERE contents.id = $1`,
+		snapshot: `SELECT json_strip_nulls(json_build_object('content_id', contents.id, 'content_description', contents.description, 'position', contents.position,
+			'mime_type', contents.mime_type, 'size', COALESCE(contents.data_size, octet_length(contents.data)), 'sha256', contents.data_checksum,
+			'width', contents.width, 'height', contents.height))
+		FROM contents
+		WHERE contents.id = $1`,
+	},
+}
+
+// inTransaction runs the change in a transaction, so events saved by it are committed only together with the change
+func (db *DB) inTransaction(ctx context.Context, change func(tx pgx.Tx) error) error {
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	if err = change(tx); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	return nil
+}
+
+// addEvent saves the event of the existing object to the outbox in the transaction of the change,
+// created and changed events get the snapshot of the object after the change, so deleted events are saved before the delete.
+// Returns ErrNotFound if there is no such object
+func addEvent(ctx context.Context, tx pgx.Tx, object string, id int, change sender.Change) error {
+	target, ok := eventTargets[object]
+	if !ok {
+		return fmt.Errorf("failed to save event: unknown object %s", object)
+	}
+
+	snapshot := `NULL::JSONB`
+	if change == sender.CreateObj || change == sender.UpdateObj {
+		snapshot = `(` + target.snapshot + `)::JSONB`
+	}
+
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO outbox (profile_id, portfolio_id, craft_id, object, object_id, change, snapshot, request_id)
+	SELECT parents.*, $2, $1, $3, `+snapshot+`, $4 FROM (`+target.parents+`) parents`, id, object, string(change), requestID(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to save event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to save event: %s %d: %w", object, id, response_errors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// requestID is the id of the request of the context, changes made outside of requests have no id
+func requestID(ctx context.Context) pgtype.Text {
+	if id := logging.RequestID(ctx); id != "" {
+		return pgtype.Text{String: id, Status: pgtype.Present}
+	}
+
+	return pgtype.Text{Status: pgtype.Null}
+}
+
+// addCraftTagEvent saves the event of the tag of the craft, attached events get the snapshot of the tag
+func addCraftTagEvent(ctx context.Context, tx pgx.Tx, craftID, tagID int, change sender.Change) error {
+	snapshot := `NULL::JSONB`
+	if change == sender.AttachObj {
+		snapshot = `(SELECT json_build_object('tag_id', tags.id, 'tag_name', tags.name) FROM tags WHERE tags.id = $2)::JSONB`
+	}
+
+	tag, err := tx.Exec(ctx, `
+	INSERT INTO outbox (profile_id, portfolio_id, craft_id, object, object_id, change, snapshot, request_id)
+	SELECT portfolios.profile_id, crafts.portfolio_id, crafts.id, $3, $2, $4, `+snapshot+`, $5
+	FROM crafts
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	WHERE crafts.id = $1`, craftID, tagID, models.ObjectCraftTag, string(change), requestID(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to save event: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("failed to save event: craft %d: %w", craftID, response_errors.ErrNotFound)
+	}
+
+	return nil
+}
+
+// addTrashEvents saves events of trash items, which may be already deleted, so their parents are taken from the items
+func addTrashEvents(ctx context.Context, tx pgx.Tx, items []models.TrashItem, change sender.Change) error {
+	for _, item := range items {
+		// trash items of portfolios keep their own id as the portfolio id
+		portfolioID := item.PortfolioID
+		if item.Object == models.ObjectPortfolio {
+			portfolioID = 0
+		}
+
+		if _, err := tx.Exec(ctx, `INSERT INTO outbox (profile_id, portfolio_id, craft_id, object, object_id, change, request_id) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			item.ProfileID, nullableInt(portfolioID), nullableInt(item.CraftID), item.Object, item.ID, string(change), requestID(ctx)); err != nil {
+			return fmt.Errorf("failed to save event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// RelayEvents passes undelivered events to send in order of creation and marks sent ones as delivered,
+// it stops at the first event which is not sent, the event is retried by the next call.
+// Returns the number of delivered events, nothing is relayed while another replica relays events
+func (db *DB) RelayEvents(ctx context.Context, limit int, send func(ctx context.Context, event models.OutboxEvent) error) (int, error) {
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to relay events: transaction error: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var locked bool
+	if err = tx.QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxLockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to relay events: lock error: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	events, err := undeliveredEvents(ctx, tx, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to relay events: %w", err)
+	}
+
+	var delivered []int64
+	var sendErr error
+	for _, event := range events {
+		if sendErr = send(ctx, event); sendErr != nil {
+			if _, err = tx.Exec(ctx, `UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`, event.ID, sendErr.Error()); err != nil {
+				return 0, fmt.Errorf("failed to relay events: %w", err)
+			}
+			sendErr = fmt.Errorf("failed to relay event %d: %w", event.ID, sendErr)
+			break
+		}
+		delivered = append(delivered, event.ID)
+	}
+
+	if len(delivered) != 0 {
+		if _, err = tx.Exec(ctx, `UPDATE outbox SET delivered_at = now() WHERE id = ANY($1)`, delivered); err != nil {
+			return 0, fmt.Errorf("failed to relay events: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to relay events: transaction error: %w", err)
+	}
+
+	return len(delivered), sendErr
+}
+
+func undeliveredEvents(ctx context.Context, tx pgx.Tx, limit int) ([]models.OutboxEvent, error) {
+	rows, err := tx.Query(ctx, `
+	SELECT id, event_id::TEXT, request_id, profile_id, portfolio_id, craft_id, object, object_id, change, snapshot::TEXT, created_at, attempts
+	FROM outbox
+	WHERE delivered_at IS NULL
+	ORDER BY id LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var id, profileID, portfolioID, craftID, objectID, attempts pgtype.Int8
+		var eventID, requestID, object, change, snapshot pgtype.Text
+		var createdAt time.Time
+
+		if err = rows.Scan(&id, &eventID, &requestID, &profileID, &portfolioID, &craftID, &object, &objectID, &change, &snapshot, &createdAt, &attempts); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+
+		event := models.OutboxEvent{ID: id.Int, EventID: eventID.String, RequestID: requestID.String, ProfileID: int(profileID.Int), PortfolioID: int(portfolioID.Int), CraftID: int(craftID.Int),
+			Object: object.String, ObjectID: int(objectID.Int), Change: change.String, CreatedAt: createdAt, Attempts: int(attempts.Int)}
+		if snapshot.Status == pgtype.Present {
+			event.Snapshot = []byte(snapshot.String)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return events, nil
+}
+
+// DeleteDeliveredEvents deletes events delivered before the given time and returns their number
+func (db *DB) DeleteDeliveredEvents(ctx context.Context, before time.Time) (int, error) {
+	tag, err := db.db.Exec(ctx, `DELETE FROM outbox WHERE delivered_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered events: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
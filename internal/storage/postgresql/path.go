@@ -0,0 +1,91 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// CheckPath checks that each object of the path belongs to the previous one, objects in the trash are checked too,
+// so handlers can tell that they are deleted
+func (db *DB) CheckPath(ctx context.Context, path models.Path) error {
+	checks := []struct {
+		id, parentID int
+		getParentID  func(ctx context.Context, id int) (int, error)
+	}{
+		{path.ContentID, path.CraftID, db.GetCraftIDByContent},
+		{path.CraftID, path.PortfolioID, db.GetPortfolioIDByCraft},
+		{path.PortfolioID, path.ProfileID, db.GetProfileIDByPortfolio},
+	}
+
+	for _, check := range checks {
+		if check.id == 0 {
+			continue
+		}
+
+		parentID, err := check.getParentID(ctx, check.id)
+		if errors.Is(err, response_errors.ErrNotFound) || err == nil && parentID != check.parentID {
+			return fmt.Errorf("failed to check path: %w", response_errors.ErrPathNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check path: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (db *DB) GetProfileIDByPortfolio(ctx context.Context, portfolioID int) (int, error) {
+	profileID, err := db.parentID(ctx, `SELECT profile_id FROM portfolios WHERE id = $1`, portfolioID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get profile id by portfolio: %w", err)
+	}
+
+	return profileID, nil
+}
+
+func (db *DB) GetProfileIDByCraft(ctx context.Context, craftID int) (int, error) {
+	profileID, err := db.parentID(ctx, `SELECT portfolios.profile_id FROM crafts JOIN portfolios ON crafts.portfolio_id = portfolios.id WHERE crafts.id = $1`, craftID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get profile id by craft: %w", err)
+	}
+
+	return profileID, nil
+}
+
+func (db *DB) GetPortfolioIDByCraft(ctx context.Context, craftID int) (int, error) {
+	portfolioID, err := db.parentID(ctx, `SELECT portfolio_id FROM crafts WHERE id = $1`, craftID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get portfolio id by craft: %w", err)
+	}
+
+	return portfolioID, nil
+}
+
+func (db *DB) GetCraftIDByContent(ctx context.Context, contentID int) (int, error) {
+	craftID, err := db.parentID(ctx, `SELECT craft_id FROM contents WHERE id = $1`, contentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get craft id by content: %w", err)
+	}
+
+	return craftID, nil
+}
+
+// parentID expects the query of one id with $1 placeholder for the id of the child
+func (db *DB) parentID(ctx context.Context, sql string, id int) (int, error) {
+	var parentID pgtype.Int8
+	if err := db.db.QueryRow(ctx, sql, id).Scan(&parentID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, response_errors.ErrNotFound
+		}
+		return 0, err
+	}
+
+	return int(parentID.Int), nil
+}
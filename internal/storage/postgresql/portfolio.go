@@ -2,12 +2,16 @@ package postgresql
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
 
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
 	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
 )
 
 func (db *DB) CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (int, error) {
@@ -18,11 +22,19 @@ func (db *DB) CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (
 
 	defer tx.Rollback(ctx)
 
+	if err = checkApproved(ctx, tx, models.ObjectCategory, portfolio.Category.ID); err != nil {
+		return 0, fmt.Errorf("failed to create portfolio: %w", err)
+	}
+
 	var id pgtype.Int8
 	if err = tx.QueryRow(ctx, `INSERT INTO portfolios (profile_id, name, category_id, description) VALUES ($1, $2, $3, $4) RETURNING id`, portfolio.ProfileID, portfolio.Name, portfolio.Category.ID, portfolio.Description).Scan(&id); err != nil {
 		return 0, fmt.Errorf("failed to create portfolio: creation error: %w", err)
 	}
 
+	if err = addEvent(ctx, tx, models.ObjectPortfolio, int(id.Int), sender.CreateObj); err != nil {
+		return 0, fmt.Errorf("failed to create portfolio: %w", err)
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return 0, fmt.Errorf("failed to create portfolio: transaction error: %w", err)
 	}
@@ -32,7 +44,13 @@ func (db *DB) CreatePortfolio(ctx context.Context, portfolio models.Portfolio) (
 
 func (db *DB) CreateCategory(ctx context.Context, name string) (int, error) {
 	var id pgtype.Int8
-	if err := db.db.QueryRow(ctx, `INSERT INTO categories (name) VALUES ($1) RETURNING id`, name).Scan(&id); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		if err := tx.QueryRow(ctx, `INSERT INTO categories (name) VALUES ($1) RETURNING id`, name).Scan(&id); err != nil {
+			return err
+		}
+		return addEvent(ctx, tx, models.ObjectCategory, int(id.Int), sender.CreateObj)
+	})
+	if err != nil {
 		return 0, fmt.Errorf("failed to create category: %w", err)
 	}
 
@@ -40,38 +58,54 @@ func (db *DB) CreateCategory(ctx context.Context, name string) (int, error) {
 }
 
 func (db *DB) DeleteCategory(ctx context.Context, id int) error {
-	if _, err := db.db.Exec(ctx, `DELETE FROM categories WHERE id=$1`, id); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		if err := addEvent(ctx, tx, models.ObjectCategory, id, sender.DeleteObj); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM categories WHERE id=$1`, id)
+		return err
+	})
+	// there is nothing to delete
+	if errors.Is(err, response_errors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
 		return fmt.Errorf("failed to delete category: %w", err)
 	}
 
 	return nil
 }
 
-func (db *DB) GetAllCategories(ctx context.Context, limit, offset int) ([]models.Category, error) {
-	rows, err := db.db.Query(ctx, `SELECT id, name FROM categories LIMIT $1 OFFSET $2`, limit, offset)
+// GetAllCategories returns approved categories or pending ones with their proposers
+func (db *DB) GetAllCategories(ctx context.Context, page models.Page, pending bool) ([]models.Category, error) {
+	rows, err := db.db.Query(ctx, `SELECT id, name, proposed_by FROM categories WHERE id > $3 AND pending = $4 ORDER BY id LIMIT $1 OFFSET $2`, page.Limit, page.Offset, page.AfterID(), pending)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all categories: %w", err)
 	}
+	defer rows.Close()
 
 	var categories []models.Category
 	var category models.Category
 
 	for rows.Next() {
-		var id pgtype.Int8
+		var id, proposedBy pgtype.Int8
 		var name pgtype.Text
-		if err = rows.Scan(&id, &name); err != nil {
+		if err = rows.Scan(&id, &name, &proposedBy); err != nil {
 			return nil, fmt.Errorf("failed to get all categories: scan error: %w", err)
 		}
 		category.ID, category.Name = int(id.Int), name.String
+		if pending {
+			category.Status, category.ProposedBy = models.StatusPending, int(proposedBy.Int)
+		}
 		categories = append(categories, category)
 	}
 
 	return categories, nil
 }
 
-func (db *DB) CountCategoriesPages(ctx context.Context) (int, error) {
+func (db *DB) CountCategoriesPages(ctx context.Context, pending bool) (int, error) {
 	var amount pgtype.Int8
-	if err := db.db.QueryRow(ctx, `SELECT COUNT(*) FROM categories`).Scan(&amount); err != nil {
+	if err := db.db.QueryRow(ctx, `SELECT COUNT(*) FROM categories WHERE pending = $1`, pending).Scan(&amount); err != nil {
 		return 0, fmt.Errorf("failed to count categories: %w", err)
 	}
 
@@ -79,15 +113,25 @@ func (db *DB) CountCategoriesPages(ctx context.Context) (int, error) {
 }
 
 func (db *DB) DeletePortfolio(ctx context.Context, portfolioID int) error {
-	if _, err := db.db.Exec(ctx, `DELETE FROM portfolios WHERE id=$1`, portfolioID); err != nil {
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		return moveToTrash(ctx, tx, models.ObjectPortfolio, portfolioID, `UPDATE portfolios SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL`)
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete portfolio: %w", err)
 	}
 
 	return nil
 }
 
-func (db *DB) PatchPortfolio(ctx context.Context, portfolio models.Portfolio) error {
-	if _, err := db.db.Exec(ctx, `UPDATE portfolios SET name = $1, description = $2, category_id = $3 WHERE id = $4`, portfolio.Name, portfolio.Description, portfolio.Category.ID, portfolio.ID); err != nil {
+func (db *DB) PatchPortfolio(ctx context.Context, authorID int, portfolio models.Portfolio) error {
+	err := db.revise(ctx, models.ObjectPortfolio, authorID, portfolio.ID, func(tx pgx.Tx) error {
+		if err := checkApproved(ctx, tx, models.ObjectCategory, portfolio.Category.ID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `UPDATE portfolios SET name = $1, description = $2, category_id = $3 WHERE id = $4`, portfolio.Name, portfolio.Description, portfolio.Category.ID, portfolio.ID)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to update portfolio: %w", err)
 	}
 
@@ -106,7 +150,7 @@ func (db *DB) GetPortfolioByID(ctx context.Context, portfolioID int) (*models.Po
        portfolios.description 
 	FROM portfolios 
 	JOIN categories ON portfolios.category_id = categories.id 
-	WHERE portfolios.id = $1`,
+	WHERE portfolios.id = $1 AND portfolios.deleted_at IS NULL`,
 		portfolioID).Scan(&profileID, &portfolioName, &categoryID, &categoryName, &portfolioDescription); err != nil {
 		return nil, fmt.Errorf("failed to get portfolio: %w", err)
 	}
@@ -114,7 +158,7 @@ func (db *DB) GetPortfolioByID(ctx context.Context, portfolioID int) (*models.Po
 	return &models.Portfolio{ID: portfolioID, ProfileID: int(profileID.Int), Name: portfolioName.String, Description: portfolioDescription.String, Category: models.Category{ID: int(categoryID.Int), Name: categoryName.String}}, nil
 }
 
-func (db *DB) GetAllPortfolios(ctx context.Context, limit, offset int, id int, filterType PortfoliosFilterType) ([]models.Portfolio, error) {
+func (db *DB) GetAllPortfolios(ctx context.Context, page models.Page, id int, filterType models.PortfoliosFilterType) ([]models.Portfolio, error) {
 	filter, err := portfolioFilter(filterType, id)
 	if err != nil {
 		return nil, err
@@ -129,13 +173,14 @@ func (db *DB) GetAllPortfolios(ctx context.Context, limit, offset int, id int, f
        categories.name 
 	FROM portfolios 
     JOIN categories ON portfolios.category_id = categories.id`,
-		filter,
-		`LIMIT $1 OFFSET $2`}, " ")
+		where(`portfolios.deleted_at IS NULL`, `portfolios.id > $3`, filter),
+		`ORDER BY portfolios.id LIMIT $1 OFFSET $2`}, " ")
 
-	rows, err := db.db.Query(ctx, sql, limit, offset)
+	rows, err := db.db.Query(ctx, sql, page.Limit, page.Offset, page.AfterID())
 	if err != nil {
 		return nil, fmt.Errorf("failed to get portfolios: %w", err)
 	}
+	defer rows.Close()
 
 	var portfolios []models.Portfolio
 	for rows.Next() {
@@ -153,13 +198,13 @@ func (db *DB) GetAllPortfolios(ctx context.Context, limit, offset int, id int, f
 	return portfolios, nil
 }
 
-func (db *DB) CountPortfoliosPages(ctx context.Context, id int, filterType PortfoliosFilterType) (int, error) {
+func (db *DB) CountPortfoliosPages(ctx context.Context, id int, filterType models.PortfoliosFilterType) (int, error) {
 	filter, err := portfolioFilter(filterType, id)
 	if err != nil {
 		return 0, err
 	}
 
-	sql := strings.Join([]string{"SELECT COUNT(*) FROM categories", filter}, " ")
+	sql := strings.Join([]string{"SELECT COUNT(*) FROM portfolios", where(`portfolios.deleted_at IS NULL`, filter)}, " ")
 
 	var amount pgtype.Int8
 	if err := db.db.QueryRow(ctx, sql).Scan(&amount); err != nil {
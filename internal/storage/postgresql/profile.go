@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// DeleteProfilePortfolios hard-deletes all portfolios of the profile including ones in the trash, saves their events
+// and returns deleted portfolios, crafts, contents and revisions are removed by ON DELETE CASCADE
+func (db *DB) DeleteProfilePortfolios(ctx context.Context, profileID int) ([]models.TrashItem, error) {
+	var deleted []models.TrashItem
+	var dataKeys []string
+
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		var err error
+		if dataKeys, err = lockContentBlobs(ctx, tx, `portfolios.profile_id = $1`, profileID); err != nil {
+			return fmt.Errorf("blobs: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, `
+		DELETE FROM portfolios
+		WHERE profile_id = $1
+		RETURNING 'portfolio', id, profile_id, id, 0, name, COALESCE(deleted_at, now())`, profileID)
+		if err != nil {
+			return err
+		}
+
+		if deleted, err = scanTrash(rows); err != nil {
+			return err
+		}
+
+		return addTrashEvents(ctx, tx, deleted, sender.DeleteObj)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete portfolios of profile %d: %w", profileID, err)
+	}
+
+	// the portfolios are already deleted and a retry wouldn't find their blobs, so blobs which failed to be deleted
+	// are left in the store and logged, so they can be deleted by hand
+	for _, key := range dataKeys {
+		if err = db.blobs.Delete(ctx, key); err != nil {
+			db.logger.WarnContext(ctx, "failed to delete blob of deleted profile", slog.Int("profile_id", profileID), slog.String("key", key), slog.Any("error", err))
+		}
+	}
+
+	return deleted, nil
+}
+
+// AnonymizeProfilePortfolios moves all portfolios of the profile to the anonymous profile and replaces the profile
+// in revisions by it, returns moved portfolios and saves their deleted events keyed by the previous profile
+func (db *DB) AnonymizeProfilePortfolios(ctx context.Context, profileID, anonymousID int) ([]models.TrashItem, error) {
+	var anonymized []models.TrashItem
+
+	err := db.inTransaction(ctx, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+		UPDATE portfolios SET profile_id = $2
+		WHERE profile_id = $1
+		RETURNING 'portfolio', id, $1::BIGINT, id, 0, name, COALESCE(deleted_at, now())`, profileID, anonymousID)
+		if err != nil {
+			return err
+		}
+
+		if anonymized, err = scanTrash(rows); err != nil {
+			return err
+		}
+
+		for _, table := range []string{"portfolios_revisions", "crafts_revisions", "contents_revisions"} {
+			if _, err = tx.Exec(ctx, `UPDATE `+table+` SET author_id = $2 WHERE author_id = $1`, profileID, anonymousID); err != nil {
+				return fmt.Errorf("revisions: %w", err)
+			}
+		}
+
+		return addTrashEvents(ctx, tx, anonymized, sender.DeleteObj)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize portfolios of profile %d: %w", profileID, err)
+	}
+
+	return anonymized, nil
+}
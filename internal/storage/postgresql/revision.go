@@ -0,0 +1,220 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// revisionValues are the values kept in revisions, category id is used by portfolios only
+type revisionValues struct {
+	name        pgtype.Text
+	description pgtype.Text
+	categoryID  pgtype.Int8
+}
+
+type revisionTarget struct {
+	// table of revisions and its column with the object id
+	table  string
+	column string
+	// current selects name, description and category id of the visible object by $1 and locks it
+	current string
+}
+
+var revisionTargets = map[string]revisionTarget{
+	models.ObjectPortfolio: {
+		table:   "portfolios_revisions",
+		column:  "portfolio_id",
+		current: `SELECT name, description, category_id FROM portfolios WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`,
+	},
+	models.ObjectCraft: {
+		table:  "crafts_revisions",
+		column: "craft_id",
+		current: `SELECT crafts.name, crafts.description, NULL::BIGINT FROM crafts JOIN portfolios ON crafts.portfolio_id = portfolios.id
+		WHERE crafts.id = $1 AND ` + visibleCrafts + ` FOR UPDATE OF crafts`,
+	},
+	models.ObjectContent: {
+		table:  "contents_revisions",
+		column: "content_id",
+		current: `SELECT NULL::TEXT, contents.description, NULL::BIGINT FROM contents
+		JOIN crafts ON contents.craft_id = crafts.id
+		JOIN portfolios ON crafts.portfolio_id = portfolios.id
+		WHERE contents.id = $1 AND contents.deleted_at IS NULL AND ` + visibleCrafts + ` FOR UPDATE OF contents`,
+	},
+}
+
+func getRevisionTarget(object string) (revisionTarget, error) {
+	target, ok := revisionTargets[object]
+	if !ok {
+		return revisionTarget{}, fmt.Errorf("unknown object of revisions: %s", object)
+	}
+
+	return target, nil
+}
+
+// revise saves current values of the object as a new revision, applies the change and saves its event in the same transaction,
+// returns ErrNotFound if the object doesn't exist or is deleted
+func (db *DB) revise(ctx context.Context, object string, authorID, id int, change func(tx pgx.Tx) error) error {
+	target, err := getRevisionTarget(object)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var current revisionValues
+	if err = tx.QueryRow(ctx, target.current, id).Scan(&current.name, &current.description, &current.categoryID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return response_errors.ErrNotFound
+		}
+		return fmt.Errorf("failed to get current values: %w", err)
+	}
+
+	sql := fmt.Sprintf(`INSERT INTO %[1]s (%[2]s, revision, author_id, name, description, category_id)
+	VALUES ($1, (SELECT COALESCE(MAX(revision), 0) + 1 FROM %[1]s WHERE %[2]s = $1), $2, $3, $4, $5)`, target.table, target.column)
+	if _, err = tx.Exec(ctx, sql, id, authorID, current.name, current.description, current.categoryID); err != nil {
+		return fmt.Errorf("failed to save revision: %w", err)
+	}
+
+	if err = change(tx); err != nil {
+		return err
+	}
+
+	if err = addEvent(ctx, tx, object, id, sender.UpdateObj); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("transaction error: %w", err)
+	}
+
+	return nil
+}
+
+// Revert saves current values of the object as a new revision and restores values of the given revision
+func (db *DB) Revert(ctx context.Context, authorID int, object string, id, revision int) error {
+	err := db.revise(ctx, object, authorID, id, func(tx pgx.Tx) error {
+		target, _ := getRevisionTarget(object)
+
+		var values revisionValues
+		sql := fmt.Sprintf(`SELECT name, description, category_id FROM %s WHERE %s = $1 AND revision = $2`, target.table, target.column)
+		if err := tx.QueryRow(ctx, sql, id, revision).Scan(&values.name, &values.description, &values.categoryID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return response_errors.ErrNotFound
+			}
+			return fmt.Errorf("failed to get revision: %w", err)
+		}
+
+		var err error
+		switch object {
+		case models.ObjectPortfolio:
+			_, err = tx.Exec(ctx, `UPDATE portfolios SET name = $1, description = $2, category_id = $3 WHERE id = $4`, values.name, values.description, values.categoryID, id)
+		case models.ObjectCraft:
+			_, err = tx.Exec(ctx, `UPDATE crafts SET name = $1, description = $2 WHERE id = $3`, values.name, values.description, id)
+		case models.ObjectContent:
+			_, err = tx.Exec(ctx, `UPDATE contents SET description = $1 WHERE id = $2`, values.description, id)
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revert %s: %w", object, err)
+	}
+
+	return nil
+}
+
+func (db *DB) GetRevisions(ctx context.Context, object string, id int, page models.Page) ([]models.Revision, error) {
+	target, err := getRevisionTarget(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+
+	sql := fmt.Sprintf(`SELECT revision, author_id, created_at, name, description, category_id FROM %s
+	WHERE %s = $1 AND revision > $4 ORDER BY revision LIMIT $2 OFFSET $3`, target.table, target.column)
+
+	rows, err := db.db.Query(ctx, sql, id, page.Limit, page.Offset, page.AfterID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []models.Revision
+	for rows.Next() {
+		revision, err := scanRevision(rows, object, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get revisions: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get revisions: rows error: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (db *DB) CountRevisions(ctx context.Context, object string, id int) (int, error) {
+	target, err := getRevisionTarget(object)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	var amount pgtype.Int8
+	if err = db.db.QueryRow(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s = $1`, target.table, target.column), id).Scan(&amount); err != nil {
+		return 0, fmt.Errorf("failed to count revisions: %w", err)
+	}
+
+	return int(amount.Int), nil
+}
+
+func (db *DB) GetRevision(ctx context.Context, object string, id, revision int) (*models.Revision, error) {
+	target, err := getRevisionTarget(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	sql := fmt.Sprintf(`SELECT revision, author_id, created_at, name, description, category_id FROM %s
+	WHERE %s = $1 AND revision = $2`, target.table, target.column)
+
+	result, err := scanRevision(db.db.QueryRow(ctx, sql, id, revision), object, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	return &result, nil
+}
+
+func scanRevision(row pgx.Row, object string, id int) (models.Revision, error) {
+	var revision, authorID, categoryID pgtype.Int8
+	var name, description pgtype.Text
+	var createdAt time.Time
+
+	if err := row.Scan(&revision, &authorID, &createdAt, &name, &description, &categoryID); err != nil {
+		return models.Revision{}, err
+	}
+
+	return models.Revision{
+		Object:      object,
+		ObjectID:    id,
+		Number:      int(revision.Int),
+		AuthorID:    int(authorID.Int),
+		CreatedAt:   createdAt,
+		Name:        name.String,
+		Description: description.String,
+		CategoryID:  int(categoryID.Int),
+	}, nil
+}
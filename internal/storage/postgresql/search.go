@@ -0,0 +1,119 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/pgtype"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// searchResultsSQL finds portfolios and crafts by their search_vector columns,
+// $1 is the query in websearch syntax, $2 is category id and $3 is tag id (0 means any)
+const searchResultsSQL = `
+	WITH query AS (SELECT websearch_to_tsquery('simple', $1) AS q),
+	results AS (
+		SELECT 'portfolio'::TEXT AS object,
+		       portfolios.id,
+		       portfolios.id AS portfolio_id,
+		       portfolios.profile_id,
+		       portfolios.name,
+		       ts_rank(portfolios.search_vector, query.q) AS rank
+		FROM portfolios, query
+		WHERE portfolios.search_vector @@ query.q
+		  AND portfolios.deleted_at IS NULL
+		  AND ($2 = 0 OR portfolios.category_id = $2)
+		  AND ($3 = 0 OR EXISTS (
+		      SELECT 1 FROM crafts
+		      JOIN crafts_tags ON crafts_tags.craft_id = crafts.id
+		      WHERE crafts.portfolio_id = portfolios.id AND crafts.deleted_at IS NULL AND crafts_tags.tag_id = $3))
+		UNION ALL
+		SELECT 'craft'::TEXT,
+		       crafts.id,
+		       crafts.portfolio_id,
+		       portfolios.profile_id,
+		       crafts.name,
+		       ts_rank(crafts.search_vector, query.q)
+		FROM crafts
+		JOIN portfolios ON crafts.portfolio_id = portfolios.id, query
+		WHERE crafts.search_vector @@ query.q
+		  AND ` + visibleCrafts + `
+		  AND ($2 = 0 OR portfolios.category_id = $2)
+		  AND ($3 = 0 OR EXISTS (
+		      SELECT 1 FROM crafts_tags
+		      WHERE crafts_tags.craft_id = crafts.id AND crafts_tags.tag_id = $3))
+	)`
+
+// searchPageSQL sorts results by rank and builds highlighted snippets for the page only,
+// $4 is true for the first page in cursor mode and in page number mode, $5-$7 are rank, object and id of the cursor
+const searchPageSQL = searchResultsSQL + `
+	SELECT page.object,
+	       page.id,
+	       page.portfolio_id,
+	       page.profile_id,
+	       page.name,
+	       page.rank,
+	       ts_headline('simple', CASE page.object
+	           WHEN 'portfolio' THEN (
+	               SELECT concat_ws(' ', portfolios.name, portfolios.description)
+	               FROM portfolios
+	               WHERE portfolios.id = page.id)
+	           ELSE (
+	               SELECT concat_ws(' ', crafts.name, crafts.description,
+	                   (SELECT string_agg(tags.name, ' ') FROM crafts_tags JOIN tags ON crafts_tags.tag_id = tags.id WHERE crafts_tags.craft_id = crafts.id),
+	                   (SELECT string_agg(contents.description, ' ') FROM contents WHERE contents.craft_id = crafts.id AND contents.deleted_at IS NULL))
+	               FROM crafts
+	               WHERE crafts.id = page.id)
+	           END, query.q, 'MaxFragments=2, MaxWords=20, MinWords=5')
+	FROM (
+		SELECT * FROM results
+		WHERE $4 OR results.rank < $5 OR (results.rank = $5 AND (results.object > $6 OR (results.object = $6 AND results.id > $7)))
+		ORDER BY results.rank DESC, results.object, results.id
+		LIMIT $8 OFFSET $9
+	) page, query
+	ORDER BY page.rank DESC, page.object, page.id`
+
+func (db *DB) Search(ctx context.Context, query models.SearchQuery, page models.Page) ([]models.SearchResult, error) {
+	var after models.Cursor
+	fromStart := page.After == nil || page.After.Object == ""
+	if !fromStart {
+		after = *page.After
+	}
+
+	rows, err := db.db.Query(ctx, searchPageSQL, query.Text, query.CategoryID, query.TagID,
+		fromStart, after.Rank, after.Object, after.ID, page.Limit, page.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var id, portfolioID, profileID pgtype.Int8
+		var object, name, snippet pgtype.Text
+		var rank float32
+
+		if err = rows.Scan(&object, &id, &portfolioID, &profileID, &name, &rank, &snippet); err != nil {
+			return nil, fmt.Errorf("failed to search: scan error: %w", err)
+		}
+
+		result := models.SearchResult{Object: object.String, ID: int(id.Int), PortfolioID: int(portfolioID.Int), ProfileID: int(profileID.Int), Name: name.String, Snippet: snippet.String, Rank: rank}
+		results = append(results, result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: rows error: %w", err)
+	}
+
+	return results, nil
+}
+
+func (db *DB) CountSearchResults(ctx context.Context, query models.SearchQuery) (int, error) {
+	var amount pgtype.Int8
+	if err := db.db.QueryRow(ctx, searchResultsSQL+` SELECT COUNT(*) FROM results`, query.Text, query.CategoryID, query.TagID).Scan(&amount); err != nil {
+		return 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	return int(amount.Int), nil
+}
@@ -0,0 +1,108 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// taxonomyTables are the tables of categories and tags by their objects
+var taxonomyTables = map[string]string{
+	models.ObjectCategory: "categories",
+	models.ObjectTag:      "tags",
+}
+
+func (db *DB) ProposeCategory(ctx context.Context, name string, profileID int) (int, error) {
+	id, err := db.propose(ctx, models.ObjectCategory, name, profileID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to propose category: %w", err)
+	}
+
+	return id, nil
+}
+
+func (db *DB) ProposeTag(ctx context.Context, name string, profileID int) (int, error) {
+	id, err := db.propose(ctx, models.ObjectTag, name, profileID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to propose tag: %w", err)
+	}
+
+	return id, nil
+}
+
+// ReviewCategory approves the pending category or deletes it if it is rejected
+func (db *DB) ReviewCategory(ctx context.Context, id int, approve bool) error {
+	if err := db.review(ctx, models.ObjectCategory, id, approve); err != nil {
+		return fmt.Errorf("failed to review category: %w", err)
+	}
+
+	return nil
+}
+
+// ReviewTag approves the pending tag or deletes it if it is rejected
+func (db *DB) ReviewTag(ctx context.Context, id int, approve bool) error {
+	if err := db.review(ctx, models.ObjectTag, id, approve); err != nil {
+		return fmt.Errorf("failed to review tag: %w", err)
+	}
+
+	return nil
+}
+
+// propose saves the pending category or tag, it has no event until it is approved
+func (db *DB) propose(ctx context.Context, object, name string, profileID int) (int, error) {
+	var id pgtype.Int8
+	sql := fmt.Sprintf(`INSERT INTO %s (name, pending, proposed_by) VALUES ($1, true, $2) RETURNING id`, taxonomyTables[object])
+	if err := db.db.QueryRow(ctx, sql, name, profileID).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return int(id.Int), nil
+}
+
+// review returns response_errors.ErrNotFound if there is no such pending category or tag
+func (db *DB) review(ctx context.Context, object string, id int, approve bool) error {
+	return db.inTransaction(ctx, func(tx pgx.Tx) error {
+		sql := `DELETE FROM %s WHERE id = $1 AND pending`
+		if approve {
+			sql = `UPDATE %s SET pending = false WHERE id = $1 AND pending`
+		}
+
+		result, err := tx.Exec(ctx, fmt.Sprintf(sql, taxonomyTables[object]), id)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return response_errors.ErrNotFound
+		}
+
+		if !approve {
+			return nil
+		}
+		return addEvent(ctx, tx, object, id, sender.CreateObj)
+	})
+}
+
+// checkApproved returns response_errors.ErrNotApproved if the category or the tag is pending,
+// missing ones are left to foreign keys
+func checkApproved(ctx context.Context, tx pgx.Tx, object string, id int) error {
+	var pending bool
+	err := tx.QueryRow(ctx, fmt.Sprintf(`SELECT pending FROM %s WHERE id = $1`, taxonomyTables[object]), id).Scan(&pending)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if pending {
+		return fmt.Errorf("%s %d: %w", object, id, response_errors.ErrNotApproved)
+	}
+
+	return nil
+}
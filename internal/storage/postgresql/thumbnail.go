@@ -0,0 +1,149 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/blob"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// thumbnailsSQL aggregates thumbnails of the content, expects %s to be the alias of contents
+const thumbnailsSQL = `
+	LEFT JOIN LATERAL (
+		SELECT array_agg(size ORDER BY size) AS sizes, array_agg(width ORDER BY size) AS widths,
+		       array_agg(height ORDER BY size) AS heights, array_agg(mime_type ORDER BY size) AS mime_types
+		FROM content_thumbnails
+		WHERE content_thumbnails.content_id = %s.id
+	) thumbnails ON true`
+
+// thumbnailsColumns are columns of thumbnailsSQL in order of contentThumbnails targets
+const thumbnailsColumns = `COALESCE(thumbnails.sizes, '{}'), COALESCE(thumbnails.widths, '{}'), COALESCE(thumbnails.heights, '{}'), COALESCE(thumbnails.mime_types, '{}')`
+
+// contentThumbnails scans thumbnailsColumns
+type contentThumbnails struct {
+	sizes, widths, heights []int64
+	mimeTypes              []string
+}
+
+func (ct *contentThumbnails) targets() []any {
+	return []any{&ct.sizes, &ct.widths, &ct.heights, &ct.mimeTypes}
+}
+
+func (ct *contentThumbnails) apply(content *models.Content) {
+	content.Thumbnails = nil
+	for i := range ct.sizes {
+		content.Thumbnails = append(content.Thumbnails, models.Thumbnail{Size: int(ct.sizes[i]), Width: int(ct.widths[i]), Height: int(ct.heights[i]), MIMEType: ct.mimeTypes[i]})
+	}
+}
+
+// SaveThumbnails replaces thumbnails of the content, thumbnails are not saved if the data of the content
+// doesn't match the checksum anymore because it was changed while thumbnails were made
+func (db *DB) SaveThumbnails(ctx context.Context, contentID int, checksum string, thumbnails []models.Thumbnail) error {
+	blobs := make([]blob.Info, 0, len(thumbnails))
+	keys := make([]string, 0, len(thumbnails))
+	for _, thumbnail := range thumbnails {
+		info, err := db.uploadData(ctx, thumbnail.Data)
+		if err != nil {
+			db.discardBlobs(ctx, keys...)
+			return fmt.Errorf("failed to save thumbnails: %w", err)
+		}
+		blobs, keys = append(blobs, info), append(keys, info.Key)
+	}
+
+	previousKeys, err := db.replaceThumbnails(ctx, contentID, checksum, thumbnails, blobs)
+	if err != nil {
+		db.discardBlobs(ctx, keys...)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to save thumbnails: %w", err)
+	}
+
+	if err = db.deleteBlobs(ctx, previousKeys); err != nil {
+		return fmt.Errorf("failed to save thumbnails: previous thumbnails: %w", err)
+	}
+
+	return nil
+}
+
+// replaceThumbnails returns pgx.ErrNoRows if there is no content with the checksum
+func (db *DB) replaceThumbnails(ctx context.Context, contentID int, checksum string, thumbnails []models.Thumbnail, blobs []blob.Info) ([]string, error) {
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("transaction error: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	var id pgtype.Int8
+	if err = tx.QueryRow(ctx, `SELECT id FROM contents WHERE id = $1 AND data_checksum = $2 FOR UPDATE`, contentID, checksum).Scan(&id); err != nil {
+		return nil, err
+	}
+
+	previousKeys, err := deleteThumbnails(ctx, tx, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, thumbnail := range thumbnails {
+		if _, err = tx.Exec(ctx, `
+		INSERT INTO content_thumbnails (content_id, size, width, height, mime_type, data_key, data_size, data_checksum)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			contentID, thumbnail.Size, thumbnail.Width, thumbnail.Height, thumbnail.MIMEType, blobs[i].Key, blobs[i].Size, blobs[i].Checksum); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("transaction error: %w", err)
+	}
+
+	return previousKeys, nil
+}
+
+// deleteThumbnails deletes thumbnails of the content and returns their keys, the blobs must be deleted after the transaction is committed
+func deleteThumbnails(ctx context.Context, tx pgx.Tx, contentID int) ([]string, error) {
+	rows, err := tx.Query(ctx, `DELETE FROM content_thumbnails WHERE content_id = $1 RETURNING data_key`, contentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key pgtype.Text
+		if err = rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		keys = append(keys, key.String)
+	}
+
+	return keys, rows.Err()
+}
+
+func (db *DB) GetThumbnailData(ctx context.Context, contentID, size int) (*models.ContentData, error) {
+	var key, checksum, mimeType pgtype.Text
+	var dataSize pgtype.Int8
+
+	if err := db.db.QueryRow(ctx, `
+	SELECT content_thumbnails.data_key, content_thumbnails.data_size, content_thumbnails.data_checksum, content_thumbnails.mime_type
+	FROM content_thumbnails
+	JOIN contents ON content_thumbnails.content_id = contents.id
+	JOIN crafts ON contents.craft_id = crafts.id
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	WHERE content_thumbnails.content_id = $1 AND content_thumbnails.size = $2 AND contents.deleted_at IS NULL AND `+visibleCrafts, contentID, size).Scan(&key, &dataSize, &checksum, &mimeType); err != nil {
+		return nil, fmt.Errorf("failed to get thumbnail data: %w", err)
+	}
+
+	reader, err := db.blobs.Get(ctx, key.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get thumbnail data: %w", err)
+	}
+
+	return &models.ContentData{Data: reader, MIMEType: mimeType.String, Size: dataSize.Int, Checksum: checksum.String}, nil
+}
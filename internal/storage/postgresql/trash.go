@@ -0,0 +1,231 @@
+package postgresql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/pgtype"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/api/response_errors"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+// trash columns are object, id, profile_id, portfolio_id, craft_id, name and deleted_at,
+// crafts and contents deleted together with their parent are not listed separately
+const trashSQL = `
+	SELECT 'portfolio', portfolios.id, portfolios.profile_id, portfolios.id, 0, portfolios.name, portfolios.deleted_at
+	FROM portfolios
+	WHERE portfolios.profile_id = $1 AND portfolios.deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'craft', crafts.id, portfolios.profile_id, crafts.portfolio_id, 0, crafts.name, crafts.deleted_at
+	FROM crafts
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	WHERE portfolios.profile_id = $1 AND crafts.deleted_at IS NOT NULL AND portfolios.deleted_at IS NULL
+	UNION ALL
+	SELECT 'content', contents.id, portfolios.profile_id, crafts.portfolio_id, contents.craft_id, contents.description, contents.deleted_at
+	FROM contents
+	JOIN crafts ON contents.craft_id = crafts.id
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	WHERE portfolios.profile_id = $1 AND contents.deleted_at IS NOT NULL AND ` + visibleCrafts + `
+	ORDER BY 7 DESC, 1, 2`
+
+func (db *DB) GetTrash(ctx context.Context, profileID int) ([]models.TrashItem, error) {
+	rows, err := db.db.Query(ctx, trashSQL, profileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	items, err := scanTrash(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash: %w", err)
+	}
+
+	return items, nil
+}
+
+// moveToTrash runs the update which moves the object with id $1 to the trash and saves the event if the object is moved
+func moveToTrash(ctx context.Context, tx pgx.Tx, object string, id int, update string) error {
+	tag, err := tx.Exec(ctx, update, id)
+	if err != nil || tag.RowsAffected() == 0 {
+		return err
+	}
+
+	return addEvent(ctx, tx, object, id, sender.TrashObj)
+}
+
+// restore runs the update which restores the object with id $1 owned by profile $2 and saves the event,
+// returns ErrNotFound if there is no such object in the trash
+func (db *DB) restore(ctx context.Context, object string, profileID, id int, update string) error {
+	return db.inTransaction(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, update, id, profileID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return response_errors.ErrNotFound
+		}
+
+		return addEvent(ctx, tx, object, id, sender.RestoreObj)
+	})
+}
+
+func (db *DB) RestorePortfolio(ctx context.Context, profileID, portfolioID int) error {
+	err := db.restore(ctx, models.ObjectPortfolio, profileID, portfolioID, `UPDATE portfolios SET deleted_at = NULL WHERE id = $1 AND profile_id = $2 AND deleted_at IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to restore portfolio: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) RestoreCraft(ctx context.Context, profileID, craftID int) error {
+	err := db.restore(ctx, models.ObjectCraft, profileID, craftID, `
+	UPDATE crafts SET deleted_at = NULL
+	FROM portfolios
+	WHERE crafts.portfolio_id = portfolios.id
+	  AND crafts.id = $1
+	  AND portfolios.profile_id = $2
+	  AND crafts.deleted_at IS NOT NULL
+	  AND portfolios.deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("failed to restore craft: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) RestoreContent(ctx context.Context, profileID, contentID int) error {
+	err := db.restore(ctx, models.ObjectContent, profileID, contentID, `
+	UPDATE contents SET deleted_at = NULL
+	FROM crafts
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	WHERE contents.craft_id = crafts.id
+	  AND contents.id = $1
+	  AND portfolios.profile_id = $2
+	  AND contents.deleted_at IS NOT NULL
+	  AND `+visibleCrafts)
+	if err != nil {
+		return fmt.Errorf("failed to restore content: %w", err)
+	}
+
+	return nil
+}
+
+// PurgeTrash hard-deletes everything deleted before the given time, saves their events and returns purged items,
+// children of purged portfolios and crafts are removed by ON DELETE CASCADE and are not returned
+func (db *DB) PurgeTrash(ctx context.Context, before time.Time) ([]models.TrashItem, error) {
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trash: transaction error: %w", err)
+	}
+
+	defer tx.Rollback(ctx)
+
+	// data and thumbnails of contents removed by cascade are deleted from the blob store too
+	dataKeys, err := lockContentBlobs(ctx, tx, `contents.deleted_at < $1 OR crafts.deleted_at < $1 OR portfolios.deleted_at < $1`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge trash: blobs: %w", err)
+	}
+
+	queries := []string{`
+	DELETE FROM portfolios
+	WHERE deleted_at < $1
+	RETURNING 'portfolio', id, profile_id, id, 0, name, deleted_at`, `
+	DELETE FROM crafts USING portfolios
+	WHERE crafts.portfolio_id = portfolios.id AND crafts.deleted_at < $1
+	RETURNING 'craft', crafts.id, portfolios.profile_id, crafts.portfolio_id, 0, crafts.name, crafts.deleted_at`, `
+	DELETE FROM contents USING crafts, portfolios
+	WHERE contents.craft_id = crafts.id AND crafts.portfolio_id = portfolios.id AND contents.deleted_at < $1
+	RETURNING 'content', contents.id, portfolios.profile_id, crafts.portfolio_id, contents.craft_id, contents.description, contents.deleted_at`,
+	}
+
+	var purged []models.TrashItem
+	for _, query := range queries {
+		rows, err := tx.Query(ctx, query, before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge trash: %w", err)
+		}
+
+		items, err := scanTrash(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge trash: %w", err)
+		}
+		purged = append(purged, items...)
+	}
+
+	if err = addTrashEvents(ctx, tx, purged, sender.PurgeObj); err != nil {
+		return nil, fmt.Errorf("failed to purge trash: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to purge trash: transaction error: %w", err)
+	}
+
+	if err = db.deleteBlobs(ctx, dataKeys); err != nil {
+		return purged, fmt.Errorf("failed to purge trash: blobs: %w", err)
+	}
+
+	return purged, nil
+}
+
+func scanTrash(rows pgx.Rows) ([]models.TrashItem, error) {
+	defer rows.Close()
+
+	var items []models.TrashItem
+	for rows.Next() {
+		var object, name pgtype.Text
+		var id, profileID, portfolioID, craftID pgtype.Int8
+		var deletedAt time.Time
+
+		if err := rows.Scan(&object, &id, &profileID, &portfolioID, &craftID, &name, &deletedAt); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+
+		item := models.TrashItem{Object: object.String, ID: int(id.Int), ProfileID: int(profileID.Int), PortfolioID: int(portfolioID.Int), CraftID: int(craftID.Int), Name: name.String, DeletedAt: deletedAt}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return items, nil
+}
+
+// lockContentBlobs locks contents matching the condition on contents, crafts and portfolios
+// and returns keys of their data and thumbnails, which are deleted from the blob store after the contents are deleted
+func lockContentBlobs(ctx context.Context, tx pgx.Tx, condition string, args ...any) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+	SELECT contents.data_key, ARRAY(SELECT data_key FROM content_thumbnails WHERE content_thumbnails.content_id = contents.id)
+	FROM contents
+	JOIN crafts ON contents.craft_id = crafts.id
+	JOIN portfolios ON crafts.portfolio_id = portfolios.id
+	WHERE `+condition+`
+	FOR UPDATE OF contents`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dataKeys []string
+	for rows.Next() {
+		var key pgtype.Text
+		var thumbnailsKeys []string
+		if err = rows.Scan(&key, &thumbnailsKeys); err != nil {
+			return nil, fmt.Errorf("scan error: %w", err)
+		}
+		if key.Status == pgtype.Present {
+			dataKeys = append(dataKeys, key.String)
+		}
+		dataKeys = append(dataKeys, thumbnailsKeys...)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return dataKeys, nil
+}
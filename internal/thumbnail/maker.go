@@ -0,0 +1,214 @@
+package thumbnail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+)
+
+// sources are types of contents which get thumbnails, there is no webp encoder in go, so thumbnails of webp images are jpeg or png
+var sources = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Supported reports whether contents of the type get thumbnails
+func Supported(mimeType string) bool {
+	return sources[mimeType]
+}
+
+type Connector interface {
+	GetContentData(ctx context.Context, contentID int) (*models.ContentData, error)
+	SaveThumbnails(ctx context.Context, contentID int, checksum string, thumbnails []models.Thumbnail) error
+}
+
+// job is the content queued for thumbnails, the context gives the request id of the upload
+type job struct {
+	ctx       context.Context
+	contentID int
+}
+
+// Maker makes thumbnails of image contents after they are saved, queued contents are handled by a fixed number of workers
+type Maker struct {
+	connector       Connector
+	sizes           []int
+	jpegQuality     int
+	maxSourcePixels int
+	workers         int
+	logger          *slog.Logger
+
+	// mu protects jobs from being closed while contents are queued
+	mu            sync.RWMutex
+	jobs          chan job
+	closed        bool
+	finishClosing sync.WaitGroup
+}
+
+func NewMaker(cfg config.Thumbnail, connector Connector, logger *slog.Logger) *Maker {
+	sizes := append([]int(nil), cfg.Sizes...)
+	sort.Ints(sizes)
+
+	return &Maker{
+		connector:       connector,
+		sizes:           sizes,
+		jpegQuality:     cfg.JPEGQuality,
+		maxSourcePixels: cfg.MaxSourcePixels,
+		workers:         max(1, cfg.Workers),
+		logger:          logger,
+		jobs:            make(chan job, max(0, cfg.QueueSize)),
+		finishClosing:   sync.WaitGroup{},
+	}
+}
+
+// Run starts workers which make thumbnails of queued contents
+func (m *Maker) Run() {
+	for i := 0; i < m.workers; i++ {
+		m.finishClosing.Add(1)
+
+		go func() {
+			defer m.finishClosing.Done()
+
+			for j := range m.jobs {
+				if err := m.Make(j.ctx, j.contentID); err != nil {
+					m.logger.ErrorContext(j.ctx, "failed to make thumbnails", slog.Int("content_id", j.contentID), slog.Any("error", err))
+				}
+			}
+		}()
+	}
+}
+
+// Enqueue queues the content for thumbnails without waiting, false is returned if the queue is full or the maker is shut down
+func (m *Maker) Enqueue(ctx context.Context, contentID int) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return false
+	}
+
+	select {
+	case m.jobs <- job{ctx: ctx, contentID: contentID}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Shutdown stops queueing and waits until workers make thumbnails of already queued contents
+func (m *Maker) Shutdown() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.jobs)
+	}
+	m.mu.Unlock()
+
+	m.finishClosing.Wait()
+}
+
+// Make replaces thumbnails of the content by thumbnails of its current data, contents of other types are skipped
+func (m *Maker) Make(ctx context.Context, contentID int) error {
+	data, err := m.connector.GetContentData(ctx, contentID)
+	if err != nil {
+		return fmt.Errorf("failed to make thumbnails of content %d: %w", contentID, err)
+	}
+	defer data.Data.Close()
+
+	if !Supported(data.MIMEType) {
+		return nil
+	}
+
+	thumbnails, err := m.Generate(data.Data)
+	if err != nil {
+		return fmt.Errorf("failed to make thumbnails of content %d: %w", contentID, err)
+	}
+
+	if err = m.connector.SaveThumbnails(ctx, contentID, data.Checksum, thumbnails); err != nil {
+		return fmt.Errorf("failed to make thumbnails of content %d: %w", contentID, err)
+	}
+
+	return nil
+}
+
+// Generate decodes the image and makes a thumbnail for every size smaller than the image,
+// images with transparency get png thumbnails, others get jpeg ones
+func (m *Maker) Generate(data io.ReadSeeker) ([]models.Thumbnail, error) {
+	cfg, _, err := image.DecodeConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image config: %w", err)
+	}
+	if cfg.Width*cfg.Height > m.maxSourcePixels {
+		return nil, fmt.Errorf("image %dx%d is bigger than %d pixels", cfg.Width, cfg.Height, m.maxSourcePixels)
+	}
+
+	if _, err = data.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek image: %w", err)
+	}
+
+	source, _, err := image.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	var thumbnails []models.Thumbnail
+	for _, size := range m.sizes {
+		if size >= max(cfg.Width, cfg.Height) {
+			break
+		}
+
+		thumbnail, err := m.scale(source, size)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make thumbnail %d: %w", size, err)
+		}
+		thumbnails = append(thumbnails, thumbnail)
+	}
+
+	return thumbnails, nil
+}
+
+// scale fits the image into a square with the side of size keeping its proportions
+func (m *Maker) scale(source image.Image, size int) (models.Thumbnail, error) {
+	bounds := source.Bounds()
+	width, height := size, size
+	if bounds.Dx() > bounds.Dy() {
+		height = max(1, bounds.Dy()*size/bounds.Dx())
+	} else {
+		width = max(1, bounds.Dx()*size/bounds.Dy())
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), source, bounds, draw.Src, nil)
+
+	thumbnail := models.Thumbnail{Size: size, Width: width, Height: height}
+	var buf bytes.Buffer
+	var err error
+	if scaled.Opaque() {
+		thumbnail.MIMEType = "image/jpeg"
+		err = jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: m.jpegQuality})
+	} else {
+		thumbnail.MIMEType = "image/png"
+		err = png.Encode(&buf, scaled)
+	}
+	if err != nil {
+		return models.Thumbnail{}, err
+	}
+	thumbnail.Data = buf.Bytes()
+
+	return thumbnail, nil
+}
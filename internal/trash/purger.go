@@ -0,0 +1,101 @@
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/config"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/models"
+	"github.com/KseniiaSalmina/tikkichest-portfolio-service/internal/sender"
+)
+
+type Connector interface {
+	PurgeTrash(ctx context.Context, before time.Time) ([]models.TrashItem, error)
+}
+
+type Sender interface {
+	SendEvent(ctx context.Context, event sender.Event)
+}
+
+// Purger periodically deletes objects which have been in the trash longer than retention
+type Purger struct {
+	connector     Connector
+	sender        Sender
+	retention     time.Duration
+	interval      time.Duration
+	logger        *slog.Logger
+	finishClosing sync.WaitGroup
+}
+
+func NewPurger(cfg config.Trash, connector Connector, notifier Sender, logger *slog.Logger) *Purger {
+	return &Purger{
+		connector:     connector,
+		sender:        notifier,
+		retention:     cfg.Retention,
+		interval:      cfg.PurgeInterval,
+		logger:        logger,
+		finishClosing: sync.WaitGroup{},
+	}
+}
+
+func (p *Purger) Run(ctx context.Context) {
+	if p.retention <= 0 || p.interval <= 0 {
+		p.logger.Info("trash purging is disabled")
+		return
+	}
+
+	p.finishClosing.Add(1)
+
+	go func() {
+		defer p.finishClosing.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			p.purge(ctx)
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (p *Purger) purge(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+
+	// items purged before an error are deleted anyway, so their events are sent too
+	purged, err := p.connector.PurgeTrash(ctx, time.Now().Add(-p.retention))
+	if err != nil {
+		p.logger.Error("failed to purge trash", slog.Any("error", err))
+	}
+
+	for _, item := range purged {
+		p.sender.SendEvent(ctx, purgeEvent(item))
+	}
+
+	if len(purged) != 0 {
+		p.logger.Info("purged objects from trash", slog.Int("count", len(purged)))
+	}
+}
+
+func purgeEvent(item models.TrashItem) sender.Event {
+	switch item.Object {
+	case models.ObjectPortfolio:
+		return sender.PortfolioEvent(item.ProfileID, item.ID, sender.PurgeObj)
+	case models.ObjectCraft:
+		return sender.CraftEvent(item.ProfileID, item.PortfolioID, item.ID, sender.PurgeObj)
+	default:
+		return sender.ContentEvent(item.ProfileID, item.PortfolioID, item.CraftID, item.ID, sender.PurgeObj)
+	}
+}
+
+func (p *Purger) Shutdown() {
+	p.finishClosing.Wait()
+}